@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/analytics"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/decoder"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func init() {
+	c := &Command{
+		Name:  "analytics",
+		Usage: "[-rpc URL] [-from 区块] [-to 区块] [-workers N] [-max-blocks N] [-top N] [-abi 名称=文件] [-json] [-csv 文件]",
+		Short: "并发扫描区块范围，统计 gas 使用率、base fee、销毁费用、小费分布、交易类型和热门合约",
+	}
+	c.Run = func(args []string) error { return runAnalytics(c, args) }
+	register(c)
+}
+
+func runAnalytics(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	rpcURL := fs.String("rpc", defaultRPC(), "节点 RPC 地址（也可通过环境变量 IWS_RPC_URL 设置）")
+	from := fs.Uint64("from", 0, "起始区块（默认为结束区块往前 100 个）")
+	to := fs.Uint64("to", 0, "结束区块（默认为最新区块）")
+	workers := fs.Int("workers", 8, "并发查询的协程数")
+	maxBlocks := fs.Uint64("max-blocks", analytics.DefaultMaxBlocks, "单次扫描的最大区块数")
+	top := fs.Int("top", 10, "输出的热门合约/方法数量")
+	asJSON := fs.Bool("json", false, "以 JSON 输出完整报告")
+	csvPath := fs.String("csv", "", "把每个区块的统计导出为 CSV 文件（- 表示标准输出）")
+	var abiFiles listFlag
+	fs.Var(&abiFiles, "abi", "额外加载的 ABI 文件，用于解析方法签名，可重复")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	reg, err := buildRegistry(abiFiles, nil)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client, err := ethclient.DialContext(ctx, *rpcURL)
+	if err != nil {
+		return fmt.Errorf("连接节点失败: %w", err)
+	}
+	defer client.Close()
+
+	if !isSet(fs, "to") {
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("获取最新区块失败: %w", err)
+		}
+		*to = head
+	}
+	if !isSet(fs, "from") && *to > 100 {
+		*from = *to - 99
+	}
+
+	report, err := analytics.Scan(ctx, client, *from, *to, analytics.Options{
+		Workers:   *workers,
+		Top:       *top,
+		Decoder:   decoder.New(reg),
+		MaxBlocks: *maxBlocks,
+	})
+	if err != nil {
+		return err
+	}
+
+	if *csvPath != "" {
+		w := stdout
+		if *csvPath != "-" {
+			f, err := os.Create(*csvPath)
+			if err != nil {
+				return fmt.Errorf("创建 CSV 文件失败: %w", err)
+			}
+			defer f.Close()
+			w = f
+		}
+		if err := report.WriteCSV(w); err != nil {
+			return err
+		}
+		if *csvPath == "-" {
+			return nil
+		}
+	}
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	printAnalytics(report)
+	return nil
+}
+
+func printAnalytics(r *analytics.Report) {
+	fmt.Fprintf(stdout, "📊 区块 %d ~ %d（%d 个区块，%d 笔交易）\n", r.From, r.To, len(r.Blocks), r.TxCount)
+	fmt.Fprintf(stdout, "Gas 使用: %d / %d（%.2f%%）\n", r.GasUsed, r.GasLimit, r.Utilization*100)
+	if r.BaseFee != nil {
+		fmt.Fprintf(stdout, "Base fee (gwei): 最低 %s 中位 %s 最高 %s，区间变化 %+.2f%%\n",
+			gwei(r.BaseFee.Min), gwei(r.BaseFee.P50), gwei(r.BaseFee.Max), r.BaseFeeChange*100)
+	}
+	fmt.Fprintf(stdout, "销毁费用: %s ETH\n", ether(r.Burned))
+	if r.Tips != nil {
+		fmt.Fprintf(stdout, "优先费 (gwei): P10 %s P50 %s P90 %s 最高 %s\n",
+			gwei(r.Tips.P10), gwei(r.Tips.P50), gwei(r.Tips.P90), gwei(r.Tips.Max))
+	}
+	fmt.Fprintf(stdout, "失败交易: %d（%.2f%%）\n", r.Failed, r.FailureRate*100)
+	fmt.Fprintf(stdout, "交易类型: %v\n", r.TxTypes)
+
+	fmt.Fprintln(stdout, "\n热门合约:")
+	for i, c := range r.TopContracts {
+		name := c.Address.Hex()
+		if c.Label != "" {
+			name += " (" + c.Label + ")"
+		}
+		fmt.Fprintf(stdout, "  %2d. %s 调用 %d 次，gas %d\n", i+1, name, c.Calls, c.GasUsed)
+	}
+	fmt.Fprintln(stdout, "\n热门方法:")
+	for i, s := range r.TopSelectors {
+		fmt.Fprintf(stdout, "  %2d. %s %s 调用 %d 次\n", i+1, s.Selector, s.Signature, s.Calls)
+	}
+}
+
+func gwei(wei *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e9)).Text('f', 3)
+}
+
+func ether(wei *big.Int) string {
+	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18)).Text('f', 6)
+}
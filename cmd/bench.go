@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/rpcbench"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+func init() {
+	c := &Command{
+		Name:  "bench",
+		Usage: "[-rpc URL]... [-methods 方法[:权重],...] [-concurrency 1,4,16] [-requests N] [-address 地址] [-call-to 地址] [-call-data 十六进制] [-log-range N] [-batch-size N] [-timeout 时长] [-json]",
+		Short: "压测一个或多个 RPC 节点：p50/p95/p99 延迟、错误率、限流响应和节点间区块高度差",
+	}
+	c.Run = func(args []string) error { return runBench(c, args) }
+	register(c)
+}
+
+func runBench(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	var urls listFlag
+	fs.Var(&urls, "rpc", "节点 RPC 地址，可重复以对比多个节点（默认 IWS_RPC_URL 或本地节点）")
+	methods := fs.String("methods", "blockNumber,getBalance,call", "逗号分隔的方法组合，可选: "+strings.Join(rpcbench.Methods, ", "))
+	concurrency := fs.String("concurrency", "1,4,16", "逗号分隔的并发级别")
+	requests := fs.Int("requests", 100, "每个并发级别发送的请求数")
+	address := fs.String("address", "0x8c8aB9B6178877246B224F8D745A1410C4928373", "getBalance/batch 查询的地址")
+	callTo := fs.String("call-to", "", "eth_call 和 getLogs 的目标合约（默认同 -address）")
+	callData := fs.String("call-data", "", "eth_call 的 calldata（十六进制）")
+	logRange := fs.Uint64("log-range", 100, "getLogs 查询最近多少个区块")
+	batchSize := fs.Int("batch-size", 10, "batch 方法每个批量请求的条数")
+	timeout := fs.Duration("timeout", 10*time.Second, "单个请求超时")
+	asJSON := fs.Bool("json", false, "以 JSON 输出报告，便于保存并与历史结果对比")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(urls) == 0 {
+		urls = listFlag{defaultRPC()}
+	}
+
+	opts := rpcbench.Options{
+		Mix:       strings.Split(*methods, ","),
+		Requests:  *requests,
+		Timeout:   *timeout,
+		LogRange:  *logRange,
+		BatchSize: *batchSize,
+	}
+	if _, err := rpcbench.ParseMix(opts.Mix); err != nil {
+		return err
+	}
+	for _, part := range strings.Split(*concurrency, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil || n <= 0 {
+			return fmt.Errorf("无效的并发数: %s", part)
+		}
+		opts.Concurrency = append(opts.Concurrency, n)
+	}
+	if !common.IsHexAddress(*address) {
+		return fmt.Errorf("无效的地址: %s", *address)
+	}
+	opts.Address = common.HexToAddress(*address)
+	if *callTo != "" {
+		if !common.IsHexAddress(*callTo) {
+			return fmt.Errorf("无效的地址: %s", *callTo)
+		}
+		opts.CallTo = common.HexToAddress(*callTo)
+	}
+	if *callData != "" {
+		data, err := hexutil.Decode(ensureHexPrefix(*callData))
+		if err != nil {
+			return fmt.Errorf("无效的 calldata: %w", err)
+		}
+		opts.CallData = data
+	}
+
+	// Ctrl+C 中断压测
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := rpcbench.Run(ctx, urls, opts)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(report)
+	}
+	printBenchReport(report)
+	return nil
+}
+
+func printBenchReport(r *rpcbench.Report) {
+	fmt.Fprintf(stdout, "⏱️  方法组合: %s，每级 %d 个请求\n", strings.Join(r.Mix, ","), r.Requests)
+	for _, ep := range r.Endpoints {
+		fmt.Fprintf(stdout, "\n🌐 %s\n", ep.URL)
+		if ep.Error != "" {
+			fmt.Fprintf(stdout, "   ❌ %s\n", ep.Error)
+			continue
+		}
+		fmt.Fprintf(stdout, "   📦 最新区块: %d（落后 %d）\n", ep.Head, ep.HeadLag)
+		for _, level := range ep.Levels {
+			fmt.Fprintf(stdout, "   🔀 并发 %d：耗时 %s，吞吐 %.1f req/s\n", level.Concurrency, level.Duration, level.Throughput)
+			fmt.Fprintf(stdout, "      %-12s %6s %6s %6s %10s %10s %10s %10s\n", "方法", "请求", "错误", "限流", "p50", "p95", "p99", "max")
+			for _, s := range append(level.Methods, level.Total) {
+				fmt.Fprintf(stdout, "      %-12s %6d %6d %6d %10s %10s %10s %10s\n",
+					s.Method, s.Count, s.Errors, s.RateLimited, s.P50, s.P95, s.P99, s.Max)
+			}
+			if level.Total.LastError != "" {
+				fmt.Fprintf(stdout, "      ⚠️  最近错误: %s\n", level.Total.LastError)
+			}
+		}
+	}
+}
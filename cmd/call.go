@@ -0,0 +1,268 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contract"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/deployments"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/txbuild"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/txutil"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func init() {
+	call := &Command{
+		Name:  "call",
+		Usage: "[-rpc URL] (-address 地址 -abi 文件 | -name 名称 [-network 网络] [-manifest 文件]) [-from 地址] [-block 区块] [-json] 方法 [参数...]",
+		Short: "按 ABI 调用合约只读方法（无需 abigen），参数按类型自动转换，数组和 tuple 用 JSON",
+	}
+	call.Run = func(args []string) error { return runCall(call, args) }
+	register(call)
+
+	send := &Command{
+		Name:  "send-contract",
+		Usage: "[-rpc URL] (-address 地址 -abi 文件 | -name 名称 [-network 网络] [-manifest 文件]) [-key 私钥] [-value wei] [-gas-limit N] [-access-list] [-wait=false] [-wait-timeout 时长] 方法 [参数...]",
+		Short: "按 ABI 发送合约交易（无需 abigen），参数按类型自动转换，数组和 tuple 用 JSON",
+	}
+	send.Run = func(args []string) error { return runSendContract(send, args) }
+	register(send)
+}
+
+// uint256Type 用于解析 -value
+var uint256Type, _ = abi.NewType("uint256", "", nil)
+
+// contractFlags 是 call 和 send-contract 共用的定位合约的参数
+type contractFlags struct {
+	rpcURL, address, abiPath, name, network, manifest *string
+	timeout                                           *time.Duration
+}
+
+func addContractFlags(fs *flag.FlagSet) *contractFlags {
+	return &contractFlags{
+		rpcURL:   fs.String("rpc", defaultRPC(), "节点 RPC 地址（也可通过环境变量 IWS_RPC_URL 设置）"),
+		address:  fs.String("address", "", "合约地址"),
+		abiPath:  fs.String("abi", "", "ABI 文件（JSON 数组或带 abi 字段的构建产物）"),
+		name:     fs.String("name", "", "部署清单中的合约名（代替 -address，未指定 -abi 时使用清单中的 ABI）"),
+		network:  fs.String("network", "sepolia", "部署清单中的网络名"),
+		manifest: fs.String("manifest", "", "部署清单文件，默认使用内置清单"),
+		timeout:  fs.Duration("timeout", 30*time.Second, "超时时间"),
+	}
+}
+
+// open 按参数定位合约并连接节点
+func (f *contractFlags) open(ctx context.Context) (*contract.Contract, *ethclient.Client, error) {
+	if (*f.address == "") == (*f.name == "") {
+		return nil, nil, fmt.Errorf("需要 -address 或 -name 之一")
+	}
+	var addr common.Address
+	var abiJSON []byte
+	if *f.name != "" {
+		manifest := deployments.Default()
+		if *f.manifest != "" {
+			var err error
+			if manifest, err = deployments.Load(*f.manifest); err != nil {
+				return nil, nil, err
+			}
+		}
+		d, err := manifest.Lookup(*f.network, *f.name)
+		if err != nil {
+			return nil, nil, err
+		}
+		addr, abiJSON = d.Address, d.ABI
+	} else {
+		if !common.IsHexAddress(*f.address) {
+			return nil, nil, fmt.Errorf("无效的地址: %s", *f.address)
+		}
+		addr = common.HexToAddress(*f.address)
+	}
+	if *f.abiPath != "" {
+		data, err := os.ReadFile(*f.abiPath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("读取 ABI 文件失败: %w", err)
+		}
+		abiJSON = data
+	}
+	if len(abiJSON) == 0 {
+		return nil, nil, fmt.Errorf("需要 -abi（清单中 %s 没有 ABI）", *f.name)
+	}
+	parsed, err := contract.ParseABI(abiJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	client, err := ethclient.DialContext(ctx, *f.rpcURL)
+	if err != nil {
+		return nil, nil, fmt.Errorf("连接节点失败: %w", err)
+	}
+	return contract.New(addr, parsed, client), client, nil
+}
+
+// stringArgs 把命令行参数转换为 []interface{}，由 contract.Coerce 按 ABI 类型解析
+func stringArgs(args []string) []interface{} {
+	out := make([]interface{}, len(args))
+	for i, a := range args {
+		out[i] = a
+	}
+	return out
+}
+
+func runCall(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	f := addContractFlags(fs)
+	from := fs.String("from", "", "调用者地址（msg.sender）")
+	block := fs.Int64("block", -1, "在指定区块上调用，默认最新区块")
+	asJSON := fs.Bool("json", false, "以 JSON 输出返回值")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("需要方法名")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *f.timeout)
+	defer cancel()
+	ct, client, err := f.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	opts := &bind.CallOpts{Context: ctx}
+	if *from != "" {
+		if !common.IsHexAddress(*from) {
+			return fmt.Errorf("无效的地址: %s", *from)
+		}
+		opts.From = common.HexToAddress(*from)
+	}
+	if *block >= 0 {
+		opts.BlockNumber = big.NewInt(*block)
+	}
+	method, err := ct.Method(fs.Arg(0), fs.NArg()-1)
+	if err != nil {
+		return err
+	}
+	values, err := ct.Call(opts, method.Sig, stringArgs(fs.Args()[1:])...)
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		result := make(map[string]interface{}, len(values))
+		for i, v := range values {
+			name := method.Outputs[i].Name
+			if name == "" {
+				name = fmt.Sprint(i)
+			}
+			result[name] = contract.Plain(v)
+		}
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	for i, v := range values {
+		out := method.Outputs[i]
+		if out.Name != "" {
+			fmt.Fprintf(stdout, "%s (%s): %s\n", out.Name, out.Type, contract.Format(v))
+		} else {
+			fmt.Fprintf(stdout, "%s: %s\n", out.Type, contract.Format(v))
+		}
+	}
+	return nil
+}
+
+func runSendContract(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	f := addContractFlags(fs)
+	keyHex := fs.String("key", os.Getenv("IWS_PRIVATE_KEY"), "私钥（十六进制，也可通过环境变量 IWS_PRIVATE_KEY 设置）")
+	value := fs.String("value", "0", "随交易转入的 ETH 数量（wei，支持 1e18 写法）")
+	gasLimit := fs.Uint64("gas-limit", 0, "Gas 上限，0 表示自动估算")
+	accessList := fs.Bool("access-list", false, "通过 eth_createAccessList 生成访问列表，输出访问的地址和存储槽，节省 gas 时附加")
+	wait := fs.Bool("wait", true, "等待交易确认，执行失败时返回退出码 1，等待超时等无法确认结果时返回退出码 2")
+	waitTimeout := fs.Duration("wait-timeout", 5*time.Minute, "等待交易确认的超时时间，从交易发送后开始计算（-timeout 只用于连接、估算和发送）")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("需要方法名")
+	}
+	if *keyHex == "" {
+		return fmt.Errorf("需要 -key 或环境变量 IWS_PRIVATE_KEY")
+	}
+	key, err := crypto.HexToECDSA(strings.TrimPrefix(*keyHex, "0x"))
+	if err != nil {
+		return fmt.Errorf("无效的私钥: %w", err)
+	}
+	amount, err := contract.Coerce(uint256Type, *value)
+	if err != nil {
+		return fmt.Errorf("无效的 -value: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *f.timeout)
+	defer cancel()
+	ct, client, err := f.open(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+	if err != nil {
+		return fmt.Errorf("创建交易签名器失败: %w", err)
+	}
+	auth.Context = ctx
+	auth.Value = amount.(*big.Int)
+	auth.GasLimit = *gasLimit
+
+	if *accessList {
+		_, data, err := ct.Pack(fs.Arg(0), stringArgs(fs.Args()[1:])...)
+		if err != nil {
+			return err
+		}
+		report, err := txbuild.New(client.Client()).Attach(ctx, auth, &ct.Address, data)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(stdout, report)
+	}
+
+	tx, err := ct.Transact(auth, fs.Arg(0), stringArgs(fs.Args()[1:])...)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "交易已发送: %s\n", tx.Hash().Hex())
+	if !*wait {
+		return nil
+	}
+	waitCtx, waitCancel := context.WithTimeout(context.Background(), *waitTimeout)
+	defer waitCancel()
+	receipt, err := txutil.WaitSuccess(waitCtx, client, tx, time.Second)
+	if receipt != nil {
+		fmt.Fprintf(stdout, "区块: %d  Gas: %d\n", receipt.BlockNumber.Uint64(), receipt.GasUsed)
+	}
+	if err != nil {
+		// 交易已经发出：只有拿到收据且执行失败才返回 1，超时等情况结果未知，返回 2
+		if receipt == nil {
+			return &ExitError{Code: 2, Err: fmt.Errorf("交易 %s 已发送，但未能确认结果: %w", tx.Hash().Hex(), err)}
+		}
+		return &ExitError{Code: 1, Err: err}
+	}
+	fmt.Fprintln(stdout, "✅ 交易执行成功")
+	return nil
+}
@@ -0,0 +1,154 @@
+// Package cmd 实现 iws 命令行工具的子命令
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Command 描述一个子命令，Name 为完整命令路径，例如 "tx decode"
+type Command struct {
+	Name  string
+	Usage string // 参数说明，例如 "[-to 地址] <calldata>"
+	Short string // 一句话说明
+	Run   func(args []string) error
+}
+
+// ExitError 让子命令以指定退出码结束（例如 CI 检查失败）
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("退出码 %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error { return e.Err }
+
+var (
+	commands []*Command
+	stdout   io.Writer = os.Stdout
+	stderr   io.Writer = os.Stderr
+)
+
+// register 在 init 中注册子命令
+func register(c *Command) {
+	commands = append(commands, c)
+}
+
+// Execute 解析命令行参数并执行匹配的子命令，返回进程退出码
+func Execute(args []string) int {
+	c, rest := match(args)
+	if c == nil {
+		usage()
+		if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+			return 0
+		}
+		return 2
+	}
+
+	err := c.Run(rest)
+	if err == nil {
+		return 0
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) {
+		if exitErr.Err != nil {
+			fmt.Fprintf(stderr, "❌ %v\n", exitErr.Err)
+		}
+		return exitErr.Code
+	}
+	fmt.Fprintf(stderr, "❌ %v\n", err)
+	return 1
+}
+
+// match 按最长前缀匹配子命令，例如 ["tx", "decode", "0x..."] 匹配 "tx decode"
+func match(args []string) (*Command, []string) {
+	var best *Command
+	var bestLen int
+	for _, c := range commands {
+		words := strings.Fields(c.Name)
+		if len(words) > len(args) || len(words) <= bestLen {
+			continue
+		}
+		matched := true
+		for i, w := range words {
+			if args[i] != w {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			best, bestLen = c, len(words)
+		}
+	}
+	if best == nil {
+		return nil, nil
+	}
+	return best, args[bestLen:]
+}
+
+func usage() {
+	fmt.Fprintln(stderr, "用法: iws <命令> [参数]")
+	fmt.Fprintln(stderr, "\n可用命令:")
+
+	sorted := append([]*Command(nil), commands...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	for _, c := range sorted {
+		fmt.Fprintf(stderr, "  %-20s %s\n", c.Name, c.Short)
+	}
+	fmt.Fprintln(stderr, "\n使用 \"iws <命令> -h\" 查看命令参数")
+}
+
+// newFlagSet 创建子命令的参数解析器，错误时返回而不是直接退出
+func newFlagSet(c *Command) *flag.FlagSet {
+	fs := flag.NewFlagSet(c.Name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "用法: iws %s %s\n\n%s\n\n", c.Name, c.Usage, c.Short)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// isSet 判断命令行中是否显式指定了 name 参数，用于区分 -from 0 和未指定
+func isSet(fs *flag.FlagSet, name string) bool {
+	set := false
+	fs.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
+}
+
+// defaultRPC 返回默认 RPC 地址，优先使用环境变量 IWS_RPC_URL
+func defaultRPC() string {
+	if url := os.Getenv("IWS_RPC_URL"); url != "" {
+		return url
+	}
+	return "http://127.0.0.1:8545"
+}
+
+// listFlag 支持重复出现的字符串参数，例如 -abi a.json -abi b.json
+type listFlag []string
+
+func (l *listFlag) String() string { return strings.Join(*l, ",") }
+
+func (l *listFlag) Set(v string) error {
+	*l = append(*l, v)
+	return nil
+}
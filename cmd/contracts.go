@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/solbuild"
+)
+
+func init() {
+	c := &Command{
+		Name:  "contracts build",
+		Usage: "[-dir 目录] [-solc 路径 | -solc-output 文件] [-print-input] [-check]",
+		Short: "编译合约目录下的 .sol，重新生成 ABI、字节码和 abigen 绑定；-check 只检查提交的产物是否与源码一致",
+	}
+	c.Run = func(args []string) error { return runContractsBuild(c, args) }
+	register(c)
+}
+
+func runContractsBuild(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	dir := fs.String("dir", "pkg/contracts", "合约目录（包含 "+solbuild.ConfigFile+"）")
+	solcPath := fs.String("solc", "solc", "solc 可执行文件，版本须与配置一致")
+	outputPath := fs.String("solc-output", "", "使用预先生成的 solc --standard-json 输出，不调用 solc")
+	printInput := fs.Bool("print-input", false, "只输出 solc --standard-json 的输入，便于在其他机器上编译")
+	check := fs.Bool("check", false, "只检查不写入，产物与源码不一致时返回退出码 1")
+	timeout := fs.Duration("timeout", 2*time.Minute, "超时时间")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if *printInput {
+		cfg, err := solbuild.LoadConfig(*dir)
+		if err != nil {
+			return err
+		}
+		input, err := cfg.Input(*dir)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(stdout, string(input))
+		return err
+	}
+
+	var compiler solbuild.Compiler = &solbuild.Solc{Path: *solcPath}
+	if *outputPath != "" {
+		data, err := os.ReadFile(*outputPath)
+		if err != nil {
+			return fmt.Errorf("读取 solc 输出失败: %w", err)
+		}
+		compiler = solbuild.Output(data)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	files, err := solbuild.Generate(ctx, *dir, compiler)
+	if err != nil {
+		return err
+	}
+
+	if *check {
+		drifted, err := solbuild.Check(*dir, files)
+		if err != nil {
+			return err
+		}
+		if len(drifted) > 0 {
+			return &ExitError{Code: 1, Err: fmt.Errorf("%w（运行 iws contracts build 重新生成）:\n  %s", solbuild.ErrDrift, strings.Join(drifted, "\n  "))}
+		}
+		fmt.Fprintf(stdout, "✅ %d 个构建产物与源码一致\n", len(files))
+		return nil
+	}
+
+	written, err := solbuild.Write(*dir, files)
+	if err != nil {
+		return err
+	}
+	for _, path := range written {
+		fmt.Fprintf(stdout, "已更新 %s\n", path)
+	}
+	fmt.Fprintf(stdout, "✅ %d 个构建产物，更新 %d 个\n", len(files), len(written))
+	return nil
+}
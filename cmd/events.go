@@ -0,0 +1,168 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/eventdb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func init() {
+	index := &Command{
+		Name:  "events index",
+		Usage: "-address 合约地址 [-rpc URL] [-db 文件] [-from 区块] [-to 区块] [-step 区块数]",
+		Short: "把合约事件及相关区块、交易、收据写入本地 SQLite 数据库",
+	}
+	index.Run = func(args []string) error { return runEventsIndex(index, args) }
+	register(index)
+
+	transfers := &Command{
+		Name:  "events transfers",
+		Usage: "-token 代币地址 [-db 文件] [-chain 链ID] [-from 地址] [-to 地址] [-from-block A] [-to-block B] [-json]",
+		Short: "从本地数据库查询 ERC20 转账记录",
+	}
+	transfers.Run = func(args []string) error { return runEventsTransfers(transfers, args) }
+	register(transfers)
+}
+
+func runEventsIndex(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	rpcURL := fs.String("rpc", defaultRPC(), "节点 RPC 地址（也可通过环境变量 IWS_RPC_URL 设置）")
+	dbPath := fs.String("db", "events.db", "SQLite 数据库文件")
+	var addresses listFlag
+	fs.Var(&addresses, "address", "要索引的合约地址，可重复")
+	from := fs.Uint64("from", 0, "起始区块（默认为结束区块往前 1000 个，-from 0 表示从创世区块开始）")
+	to := fs.Uint64("to", 0, "结束区块（默认为最新区块）")
+	step := fs.Uint64("step", 1000, "每次 eth_getLogs 查询的区块数")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if len(addresses) == 0 {
+		fs.Usage()
+		return fmt.Errorf("至少需要一个 -address")
+	}
+	contracts, err := parseAddresses(addresses)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client, err := ethclient.DialContext(ctx, *rpcURL)
+	if err != nil {
+		return fmt.Errorf("连接节点失败: %w", err)
+	}
+	defer client.Close()
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("获取 Chain ID 失败: %w", err)
+	}
+	if !isSet(fs, "to") {
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("获取最新区块失败: %w", err)
+		}
+		*to = head
+	}
+	if !isSet(fs, "from") && *to > 1000 {
+		*from = *to - 1000
+	}
+	if *from > *to {
+		return fmt.Errorf("起始区块 %d 大于结束区块 %d", *from, *to)
+	}
+
+	db, err := eventdb.Open(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	fmt.Fprintf(stdout, "🔍 索引链 %s 区块 %d ~ %d ...\n", chainID, *from, *to)
+	n, err := eventdb.NewIndexer(db, client, chainID, nil).IndexRange(ctx, contracts, *from, *to, *step)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "✅ 写入 %d 条日志到 %s\n", n, *dbPath)
+	return nil
+}
+
+func runEventsTransfers(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	dbPath := fs.String("db", "events.db", "SQLite 数据库文件")
+	chain := fs.Int64("chain", 11155111, "链 ID")
+	tokenAddr := fs.String("token", "", "代币合约地址")
+	fromAddr := fs.String("from", "", "只查询从该地址转出的记录")
+	toAddr := fs.String("to", "", "只查询转入该地址的记录")
+	fromBlock := fs.Uint64("from-block", 0, "起始区块")
+	toBlock := fs.Uint64("to-block", 0, "结束区块（0 表示不限制）")
+	asJSON := fs.Bool("json", false, "以 JSON 输出")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !common.IsHexAddress(*tokenAddr) {
+		fs.Usage()
+		return fmt.Errorf("需要有效的 -token 地址")
+	}
+
+	q := eventdb.TransferQuery{
+		ChainID:   big.NewInt(*chain),
+		Token:     common.HexToAddress(*tokenAddr),
+		FromBlock: *fromBlock,
+		ToBlock:   *toBlock,
+	}
+	var err error
+	if q.From, err = optionalAddress(*fromAddr); err != nil {
+		return err
+	}
+	if q.To, err = optionalAddress(*toAddr); err != nil {
+		return err
+	}
+
+	db, err := eventdb.Open(*dbPath)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	transfers, err := db.Transfers(context.Background(), q)
+	if err != nil {
+		return err
+	}
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(transfers)
+	}
+	for _, tr := range transfers {
+		fmt.Fprintf(stdout, "#%d %s[%d] %s → %s %s\n",
+			tr.BlockNumber, tr.TxHash.Hex(), tr.LogIndex, tr.From.Hex(), tr.To.Hex(), tr.Value)
+	}
+	fmt.Fprintf(stdout, "共 %d 条转账记录\n", len(transfers))
+	return nil
+}
+
+func parseAddresses(list []string) ([]common.Address, error) {
+	out := make([]common.Address, 0, len(list))
+	for _, s := range list {
+		if !common.IsHexAddress(s) {
+			return nil, fmt.Errorf("无效的地址: %s", s)
+		}
+		out = append(out, common.HexToAddress(s))
+	}
+	return out, nil
+}
+
+func optionalAddress(s string) (*common.Address, error) {
+	if s == "" {
+		return nil, nil
+	}
+	if !common.IsHexAddress(s) {
+		return nil, fmt.Errorf("无效的地址: %s", s)
+	}
+	addr := common.HexToAddress(s)
+	return &addr, nil
+}
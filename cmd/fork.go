@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/forkcheck"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func init() {
+	c := &Command{
+		Name:  "fork check",
+		Usage: "[-rpc URL] (-config 文件 | -preset 名称) [-chain-id ID] [-strict] [-timeout 时长] [-json]",
+		Short: "检查本地分叉节点的链 ID、区块高度、合约代码、存储槽和余额，不满足时以非零退出码结束",
+	}
+	c.Run = func(args []string) error { return runForkCheck(c, args) }
+	register(c)
+}
+
+func runForkCheck(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	rpcURL := fs.String("rpc", defaultRPC(), "节点 RPC 地址（也可通过环境变量 IWS_RPC_URL 设置）")
+	configPath := fs.String("config", "", "JSON 格式的分叉断言配置文件")
+	preset := fs.String("preset", "", "内置配置: "+strings.Join(presetNames(), ", "))
+	chainID := fs.Uint64("chain-id", 0, "覆盖配置中的期望链 ID")
+	strict := fs.Bool("strict", false, "警告也视为失败")
+	asJSON := fs.Bool("json", false, "以 JSON 输出报告")
+	timeout := fs.Duration("timeout", 30*time.Second, "检查超时时间")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var cfg *forkcheck.Config
+	switch {
+	case *configPath != "" && *preset != "":
+		return fmt.Errorf("-config 和 -preset 只能指定一个")
+	case *configPath != "":
+		loaded, err := forkcheck.LoadConfig(*configPath)
+		if err != nil {
+			return err
+		}
+		cfg = loaded
+	case *preset != "":
+		p, ok := forkcheck.Presets[*preset]
+		if !ok {
+			return fmt.Errorf("未知的内置配置 %q，可选: %s", *preset, strings.Join(presetNames(), ", "))
+		}
+		copied := *p
+		cfg = &copied
+	default:
+		fs.Usage()
+		return fmt.Errorf("需要 -config 或 -preset")
+	}
+	if *chainID != 0 {
+		cfg.ChainID = *chainID
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, *rpcURL)
+	if err != nil {
+		return fmt.Errorf("连接节点失败: %w", err)
+	}
+	defer client.Close()
+
+	report, err := forkcheck.Check(ctx, client, cfg)
+	if err != nil {
+		return &ExitError{Code: 2, Err: err}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printForkReport(report)
+	}
+
+	if report.Status == forkcheck.StatusFail || (*strict && report.Status == forkcheck.StatusWarn) {
+		return &ExitError{Code: 1}
+	}
+	return nil
+}
+
+func printForkReport(r *forkcheck.Report) {
+	fmt.Fprintf(stdout, "🔍 分叉检查: %s\n", r.Name)
+	if r.Node != "" {
+		fmt.Fprintf(stdout, "🖥️  节点: %s\n", r.Node)
+	}
+	fmt.Fprintf(stdout, "🌐 链 ID: %d  📦 区块: %d\n\n", r.ChainID, r.Block)
+
+	icons := map[forkcheck.Status]string{
+		forkcheck.StatusPass: "✅",
+		forkcheck.StatusWarn: "⚠️ ",
+		forkcheck.StatusFail: "❌",
+	}
+	for _, res := range r.Results {
+		fmt.Fprintf(stdout, "%s [%s] %s: %s", icons[res.Status], res.Kind, res.Name, res.Actual)
+		if res.Expected != "" {
+			fmt.Fprintf(stdout, "（期望 %s）", res.Expected)
+		}
+		fmt.Fprintln(stdout)
+		if res.Message != "" {
+			fmt.Fprintf(stdout, "     %s\n", res.Message)
+		}
+	}
+	fmt.Fprintf(stdout, "\n%s 结果: %s（通过 %d，警告 %d，失败 %d）\n", icons[r.Status], r.Status,
+		r.Count(forkcheck.StatusPass), r.Count(forkcheck.StatusWarn), r.Count(forkcheck.StatusFail))
+}
+
+func presetNames() []string {
+	names := make([]string, 0, len(forkcheck.Presets))
+	for name := range forkcheck.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
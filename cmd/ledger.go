@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/ledger"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func init() {
+	c := &Command{
+		Name:  "ledger",
+		Usage: "-token 代币地址 [-rpc URL] [-from 区块] [-to 区块] [-step 区块数] [-address 地址] [-at 区块] [-checkpoints A,B,...] [-csv 文件] [-json]",
+		Short: "重放 Transfer 日志生成代币账本，重建历史余额并与链上 balanceOf 对账",
+	}
+	c.Run = func(args []string) error { return runLedger(c, args) }
+	register(c)
+}
+
+func runLedger(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	rpcURL := fs.String("rpc", defaultRPC(), "节点 RPC 地址（也可通过环境变量 IWS_RPC_URL 设置）")
+	tokenAddr := fs.String("token", "", "代币合约地址")
+	from := fs.Uint64("from", 0, "起始区块（应为代币部署区块，否则历史余额不完整）")
+	to := fs.Uint64("to", 0, "结束区块（默认为最新区块）")
+	step := fs.Uint64("step", 1000, "每次 eth_getLogs 查询的区块数")
+	var addresses listFlag
+	fs.Var(&addresses, "address", "只输出这些地址，可重复（默认输出全部持有人）")
+	at := fs.Uint64("at", 0, "重建该区块结束时的余额（默认为 -to）")
+	checkpoints := fs.String("checkpoints", "", "逗号分隔的对账区块，在这些区块比较账本余额与 balanceOf")
+	csvPath := fs.String("csv", "", "把账本记录导出为 CSV 文件（- 表示标准输出）")
+	asJSON := fs.Bool("json", false, "以 JSON 输出")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if !common.IsHexAddress(*tokenAddr) {
+		fs.Usage()
+		return fmt.Errorf("需要有效的 -token 地址")
+	}
+	holders, err := parseAddresses(addresses)
+	if err != nil {
+		return err
+	}
+	points, err := parseBlocks(*checkpoints)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	client, err := ethclient.DialContext(ctx, *rpcURL)
+	if err != nil {
+		return fmt.Errorf("连接节点失败: %w", err)
+	}
+	defer client.Close()
+
+	if *to == 0 {
+		head, err := client.BlockNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("获取最新区块失败: %w", err)
+		}
+		*to = head
+	}
+	if *at == 0 {
+		*at = *to
+	}
+
+	token := common.HexToAddress(*tokenAddr)
+	transfers, err := ledger.Fetch(ctx, client, token, *from, *to, *step)
+	if err != nil {
+		return err
+	}
+	l := ledger.New(token)
+	for _, t := range transfers {
+		if err := l.Apply(t); err != nil {
+			return err
+		}
+	}
+
+	if *csvPath != "" {
+		if err := writeLedgerCSV(l, *csvPath, holders); err != nil {
+			return err
+		}
+	}
+
+	var report *ledger.Report
+	if len(points) > 0 {
+		if report, err = l.Reconcile(ctx, ledger.CallReader{Caller: client}, points, holders); err != nil {
+			return err
+		}
+	}
+
+	var balances []ledger.Holder
+	if len(holders) == 0 {
+		balances = l.Holders(*at)
+	} else {
+		for _, h := range holders {
+			balances = append(balances, ledger.Holder{Address: h, Balance: l.BalanceAt(h, *at)})
+		}
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(struct {
+			Token     common.Address  `json:"token"`
+			Block     uint64          `json:"block"`
+			Transfers int             `json:"transfers"`
+			Supply    string          `json:"supply"`
+			Balances  []ledger.Holder `json:"balances"`
+			Reconcile *ledger.Report  `json:"reconcile,omitempty"`
+		}{token, *at, len(transfers), l.SupplyAt(*at).String(), balances, report})
+	}
+
+	fmt.Fprintf(stdout, "📒 代币 %s 区块 %d ~ %d 共 %d 笔转账\n", token.Hex(), *from, *to, len(transfers))
+	fmt.Fprintf(stdout, "区块 %d 时的余额（铸造净额 %s）:\n", *at, l.SupplyAt(*at))
+	for _, h := range balances {
+		fmt.Fprintf(stdout, "  %s %s\n", h.Address.Hex(), h.Balance)
+	}
+	if report != nil {
+		fmt.Fprintf(stdout, "🔎 对账 %d 项，差异 %d 项，结论: %s\n", report.Checked, len(report.Discrepancies), report.Verdict)
+		for _, d := range report.Discrepancies {
+			fmt.Fprintf(stdout, "  #%d %s 账本 %s 链上 %s 差额 %s\n", d.Block, d.Address.Hex(), d.Ledger, d.Actual, d.Diff)
+		}
+	}
+	return nil
+}
+
+func writeLedgerCSV(l *ledger.Ledger, path string, holders []common.Address) error {
+	w := stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return fmt.Errorf("创建 CSV 文件失败: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if len(holders) == 1 {
+		return l.WriteAddressCSV(w, holders[0])
+	}
+	return l.WriteCSV(w)
+}
+
+// parseBlocks 解析逗号分隔的区块号列表
+func parseBlocks(s string) ([]uint64, error) {
+	if s == "" {
+		return nil, nil
+	}
+	var out []uint64
+	for _, part := range strings.Split(s, ",") {
+		n, err := strconv.ParseUint(strings.TrimSpace(part), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("无效的区块号: %s", part)
+		}
+		out = append(out, n)
+	}
+	return out, nil
+}
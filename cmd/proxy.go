@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/proxy"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func init() {
+	c := &Command{
+		Name:  "proxy",
+		Usage: "[-rpc URL] [-abi [名称=]文件]... [-label 地址=标签]... [-json] 地址...",
+		Short: "识别代理合约（EIP-1967/透明/UUPS/信标/EIP-1822/EIP-1167），解析实现合约、admin 和信标地址",
+	}
+	c.Run = func(args []string) error { return runProxy(c, args) }
+	register(c)
+}
+
+// proxyResult 是一个地址的检测结果，Layers 为逐层的代理信息
+type proxyResult struct {
+	Address        common.Address `json:"address"`
+	Label          string         `json:"label,omitempty"`
+	Implementation common.Address `json:"implementation"`
+	ABI            string         `json:"abi,omitempty"`
+	Layers         []*proxy.Info  `json:"layers"`
+}
+
+func runProxy(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	rpcURL := fs.String("rpc", defaultRPC(), "节点 RPC 地址（也可通过环境变量 IWS_RPC_URL 设置）")
+	var abiFiles, labels listFlag
+	fs.Var(&abiFiles, "abi", "额外加载的 ABI 文件，格式为 文件 或 名称=文件，可重复")
+	fs.Var(&labels, "label", "地址标签，格式为 地址=标签，可重复")
+	asJSON := fs.Bool("json", false, "以 JSON 输出")
+	timeout := fs.Duration("timeout", 30*time.Second, "超时时间")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return fmt.Errorf("需要至少一个地址")
+	}
+	addrs, err := parseAddresses(fs.Args())
+	if err != nil {
+		return err
+	}
+	reg, err := buildRegistry(abiFiles, labels)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, *rpcURL)
+	if err != nil {
+		return fmt.Errorf("连接节点失败: %w", err)
+	}
+	defer client.Close()
+
+	in := proxy.New(client)
+	resolved, err := in.BindRegistry(ctx, reg, addrs...)
+	if err != nil {
+		return &ExitError{Code: 2, Err: err}
+	}
+	results := make([]proxyResult, 0, len(resolved))
+	for _, res := range resolved {
+		r := proxyResult{Address: res.Address, Label: reg.Label(res.Address), Implementation: res.Implementation, Layers: res.Layers}
+		if name, _, ok := reg.BoundABI(res.Address); ok {
+			r.ABI = name
+		}
+		results = append(results, r)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(results)
+	}
+	for _, r := range results {
+		title := r.Address.Hex()
+		if r.Label != "" {
+			title += " (" + r.Label + ")"
+		}
+		if len(r.Layers) == 0 {
+			fmt.Fprintf(stdout, "%s: 不是代理\n", title)
+			continue
+		}
+		fmt.Fprintf(stdout, "%s\n", title)
+		for _, layer := range r.Layers {
+			fmt.Fprintf(stdout, "  %s\n", layer)
+		}
+		fmt.Fprintf(stdout, "  实现合约: %s", r.Implementation.Hex())
+		if r.ABI != "" {
+			fmt.Fprintf(stdout, "（使用 ABI %s）", r.ABI)
+		}
+		fmt.Fprintln(stdout)
+	}
+	return nil
+}
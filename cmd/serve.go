@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/decoder"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/eventdb"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/explorer"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func init() {
+	c := &Command{
+		Name:  "serve",
+		Usage: "[-rpc URL] [-listen 地址] [-db 文件] [-max-range N] [-abi 名称=文件] [-label 地址=标签]",
+		Short: "启动只读的本地区块浏览器 HTTP 服务（JSON 与 HTML）",
+	}
+	c.Run = func(args []string) error { return runServe(c, args) }
+	register(c)
+}
+
+func runServe(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	rpcURL := fs.String("rpc", defaultRPC(), "节点 RPC 地址（也可通过环境变量 IWS_RPC_URL 设置）")
+	listen := fs.String("listen", "127.0.0.1:8080", "HTTP 监听地址")
+	dbPath := fs.String("db", "", "可选的本地事件索引（iws events index 生成的 SQLite 文件）")
+	maxRange := fs.Uint64("max-range", explorer.DefaultMaxRange, "未配置 -db 时单次请求通过 eth_getLogs 扫描的最大区块数")
+	var abiFiles, labels listFlag
+	fs.Var(&abiFiles, "abi", "额外加载的 ABI 文件，格式为 文件 或 名称=文件，可重复")
+	fs.Var(&labels, "label", "地址标签，格式为 地址=标签，可重复")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	reg, err := buildRegistry(abiFiles, labels)
+	if err != nil {
+		return err
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := ethclient.DialContext(ctx, *rpcURL)
+	if err != nil {
+		return fmt.Errorf("连接节点失败: %w", err)
+	}
+	defer client.Close()
+
+	var db *eventdb.DB
+	if *dbPath != "" {
+		if db, err = eventdb.Open(*dbPath); err != nil {
+			return err
+		}
+		defer db.Close()
+	}
+
+	handler := explorer.New(client, db, decoder.New(reg))
+	handler.MaxRange = *maxRange
+	srv := &http.Server{
+		Addr:              *listen,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	fmt.Fprintf(stdout, "🌐 区块浏览器已启动: http://%s/ （节点 %s）\n", *listen, *rpcURL)
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return fmt.Errorf("HTTP 服务异常退出: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/decoder"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/proxy"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func init() {
+	c := &Command{
+		Name:  "tx decode",
+		Usage: "[-to 地址] [-rpc URL] [-abi 名称=文件] [-label 地址=标签] [-json] <十六进制 calldata 或签名交易 | ->",
+		Short: "解码交易 calldata 并以调用树形式输出",
+	}
+	c.Run = func(args []string) error { return runTxDecode(c, args) }
+	register(c)
+}
+
+func runTxDecode(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	to := fs.String("to", "", "调用目标合约地址（用于优先匹配已绑定的 ABI 和显示标签）")
+	rpcURL := fs.String("rpc", "", "节点 RPC 地址，指定时先解析目标地址的代理并使用实现合约的 ABI 解码")
+	timeout := fs.Duration("timeout", 30*time.Second, "解析代理的超时时间")
+	asJSON := fs.Bool("json", false, "以 JSON 输出解码结果")
+	var abiFiles, labels listFlag
+	fs.Var(&abiFiles, "abi", "额外加载的 ABI 文件，格式为 文件 或 名称=文件，可重复")
+	fs.Var(&labels, "label", "地址标签，格式为 地址=标签，可重复")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("需要提供一个十六进制输入")
+	}
+
+	reg, err := buildRegistry(abiFiles, labels)
+	if err != nil {
+		return err
+	}
+
+	input := fs.Arg(0)
+	if input == "-" {
+		raw, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return fmt.Errorf("读取标准输入失败: %w", err)
+		}
+		input = string(raw)
+	}
+	data, err := hexutil.Decode(ensureHexPrefix(strings.TrimSpace(input)))
+	if err != nil {
+		return fmt.Errorf("无效的十六进制输入: %w", err)
+	}
+
+	var target *common.Address
+	if *to != "" {
+		if !common.IsHexAddress(*to) {
+			return fmt.Errorf("无效的地址: %s", *to)
+		}
+		addr := common.HexToAddress(*to)
+		target = &addr
+	}
+
+	// 输入可能是完整的签名交易，此时从交易中取出目标地址和 calldata
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(data); err == nil {
+		fmt.Fprintf(stderr, "ℹ️  输入为签名交易 %s\n", tx.Hash().Hex())
+		target, data = tx.To(), tx.Data()
+	} else {
+		tx = nil
+	}
+
+	if *rpcURL != "" && target != nil {
+		if err := bindProxy(*rpcURL, *timeout, reg, *target); err != nil {
+			return err
+		}
+	}
+
+	dec := decoder.New(reg)
+	var call *decoder.Call
+	if tx != nil || target != nil {
+		call = dec.Decode(target, data)
+	} else {
+		call = dec.DecodeData(data)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(call)
+	}
+	return call.Render(stdout)
+}
+
+// bindProxy 解析 addr 的代理链，把实现合约的 ABI 和标签绑定到代理地址
+func bindProxy(rpcURL string, timeout time.Duration, reg *decoder.Registry, addr common.Address) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return fmt.Errorf("连接节点失败: %w", err)
+	}
+	defer client.Close()
+
+	resolved, err := proxy.New(client).BindRegistry(ctx, reg, addr)
+	if err != nil {
+		return fmt.Errorf("解析代理失败: %w", err)
+	}
+	if r := resolved[0]; r.IsProxy() {
+		fmt.Fprintf(stderr, "ℹ️  %s 是代理合约，实现合约为 %s\n", addr.Hex(), r.Implementation.Hex())
+	}
+	return nil
+}
+
+// buildRegistry 在默认注册表基础上加载 -abi 文件和 -label 标签
+func buildRegistry(abiFiles, labels []string) (*decoder.Registry, error) {
+	reg := decoder.DefaultRegistry()
+	for _, spec := range abiFiles {
+		name, path := "", spec
+		if i := strings.Index(spec, "="); i >= 0 {
+			name, path = spec[:i], spec[i+1:]
+		}
+		if err := reg.AddABIFile(name, path); err != nil {
+			return nil, err
+		}
+	}
+	for _, spec := range labels {
+		addr, label, ok := strings.Cut(spec, "=")
+		if !ok || !common.IsHexAddress(addr) {
+			return nil, fmt.Errorf("无效的标签参数: %s", spec)
+		}
+		reg.SetLabel(common.HexToAddress(addr), label)
+	}
+	return reg, nil
+}
+
+func ensureHexPrefix(s string) string {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		return s
+	}
+	return "0x" + s
+}
@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/deployments"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/verify"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func init() {
+	c := &Command{
+		Name:  "verify",
+		Usage: "[-rpc URL] -artifact 前缀 (-address 地址 [-tx 哈希] | -name 名称 [-network 网络] [-manifest 文件]) [-exact]",
+		Short: "核对链上合约与本地构建产物（去掉元数据和 immutable 后比较运行时代码，核对构造函数参数）",
+	}
+	c.Run = func(args []string) error { return runVerify(c, args) }
+	register(c)
+}
+
+func runVerify(c *Command, args []string) error {
+	fs := newFlagSet(c)
+	rpcURL := fs.String("rpc", defaultRPC(), "节点 RPC 地址（也可通过环境变量 IWS_RPC_URL 设置）")
+	artifactPrefix := fs.String("artifact", "", "构建产物路径前缀，读取 <前缀>.abi、<前缀>.bin 和可选的 <前缀>.bin-runtime")
+	address := fs.String("address", "", "合约地址")
+	txHash := fs.String("tx", "", "创建交易哈希，用于核对构造函数参数")
+	name := fs.String("name", "", "部署清单中的合约名（代替 -address 和 -tx）")
+	network := fs.String("network", "sepolia", "部署清单中的网络名")
+	manifestPath := fs.String("manifest", "", "部署清单文件，默认使用内置清单")
+	exact := fs.Bool("exact", false, "要求完全一致（元数据不同也视为失败）")
+	timeout := fs.Duration("timeout", 30*time.Second, "超时时间")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *artifactPrefix == "" || (*address == "") == (*name == "") {
+		fs.Usage()
+		return fmt.Errorf("需要 -artifact，以及 -address 或 -name 之一")
+	}
+
+	artifact, err := verify.LoadArtifact(*artifactPrefix)
+	if err != nil {
+		return err
+	}
+	var addr common.Address
+	var opts verify.Options
+	if *name != "" {
+		manifest := deployments.Default()
+		if *manifestPath != "" {
+			if manifest, err = deployments.Load(*manifestPath); err != nil {
+				return err
+			}
+		}
+		d, err := manifest.Lookup(*network, *name)
+		if err != nil {
+			return err
+		}
+		addr, opts.TxHash = d.Address, d.TxHash
+		if len(d.ConstructorArgs) > 0 {
+			if opts.Args, err = artifact.ABI.Constructor.Inputs.Unpack(d.ConstructorArgs); err != nil {
+				return fmt.Errorf("解码清单中的构造函数参数失败: %w", err)
+			}
+		}
+	} else {
+		if !common.IsHexAddress(*address) {
+			return fmt.Errorf("无效的地址: %s", *address)
+		}
+		addr = common.HexToAddress(*address)
+		if *txHash != "" {
+			hash := common.HexToHash(*txHash)
+			opts.TxHash = &hash
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	client, err := ethclient.DialContext(ctx, *rpcURL)
+	if err != nil {
+		return fmt.Errorf("连接节点失败: %w", err)
+	}
+	defer client.Close()
+
+	report, err := verify.New(client).Verify(ctx, addr, artifact, opts)
+	if err != nil {
+		return &ExitError{Code: 2, Err: err}
+	}
+	fmt.Fprintf(stdout, "%s\n", report)
+	fmt.Fprintf(stdout, "  代码哈希: %s\n", report.CodeHash.Hex())
+	for _, im := range report.Immutables {
+		fmt.Fprintf(stdout, "  immutable @%d: %s\n", im.Offset, im.Value.Hex())
+	}
+	if opts.TxHash != nil {
+		fmt.Fprintf(stdout, "  构造函数参数: %v（已核对: %v）\n", report.Args, report.ArgsVerified)
+	}
+
+	if report.Status == verify.Mismatch || (*exact && report.Status != verify.Exact) {
+		return &ExitError{Code: 1}
+	}
+	return nil
+}
@@ -1,5 +1,11 @@
 package main
 
-func main() {
+import (
+	"os"
+
+	"github.com/IJing-WishSnow/IWS-dapp/cmd"
+)
 
+func main() {
+	os.Exit(cmd.Execute(os.Args[1:]))
 }
@@ -0,0 +1,354 @@
+// Package analytics 并发扫描区块范围，统计 gas 使用率、base fee 走势、销毁费用、小费分布、交易类型和热门合约
+package analytics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/decoder"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Backend 是扫描需要的链上查询接口，*ethclient.Client 和模拟链客户端都满足
+type Backend interface {
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error)
+}
+
+// DefaultMaxBlocks 是单次扫描默认允许的最大区块数
+const DefaultMaxBlocks = 100_000
+
+// ErrRangeTooLarge 表示扫描范围超过 Options.MaxBlocks
+var ErrRangeTooLarge = errors.New("扫描范围过大")
+
+// Options 是扫描参数
+type Options struct {
+	Workers   int              // 并发查询的协程数，默认 8
+	Top       int              // 热门合约/方法的数量，默认 10
+	Decoder   *decoder.Decoder // 用于把选择器解析为方法签名，为空时使用默认注册表
+	MaxBlocks uint64           // 单次扫描的最大区块数（每个区块的统计都保存在内存中），默认 DefaultMaxBlocks
+}
+
+// BlockStats 是单个区块的统计
+type BlockStats struct {
+	Number      uint64   `json:"number"`
+	Time        uint64   `json:"time"`
+	TxCount     int      `json:"txCount"`
+	Failed      int      `json:"failed"`
+	GasUsed     uint64   `json:"gasUsed"`
+	GasLimit    uint64   `json:"gasLimit"`
+	Utilization float64  `json:"utilization"` // GasUsed / GasLimit
+	BaseFee     *big.Int `json:"baseFee"`     // London 之前的区块为 nil
+	Burned      *big.Int `json:"burned"`      // baseFee * gasUsed + 销毁的 blob 费用
+	MedianTip   *big.Int `json:"medianTip"`   // 每 gas 实际支付的优先费中位数
+
+	tips  []*big.Int
+	types map[string]int
+	calls []call
+}
+
+// call 是一次对合约的调用
+type call struct {
+	to       common.Address
+	selector [4]byte
+	gasUsed  uint64
+}
+
+// Distribution 是数值分布的分位数
+type Distribution struct {
+	Min *big.Int `json:"min"`
+	P10 *big.Int `json:"p10"`
+	P50 *big.Int `json:"p50"`
+	P90 *big.Int `json:"p90"`
+	Max *big.Int `json:"max"`
+}
+
+// ContractCount 是某个合约被调用的次数
+type ContractCount struct {
+	Address common.Address `json:"address"`
+	Label   string         `json:"label,omitempty"`
+	Calls   int            `json:"calls"`
+	GasUsed uint64         `json:"gasUsed"`
+}
+
+// SelectorCount 是某个方法选择器被调用的次数
+type SelectorCount struct {
+	Selector  string `json:"selector"`
+	Signature string `json:"signature,omitempty"`
+	Calls     int    `json:"calls"`
+}
+
+// Report 是区块范围的统计报告
+type Report struct {
+	From          uint64          `json:"from"`
+	To            uint64          `json:"to"`
+	Blocks        []BlockStats    `json:"blocks"`
+	TxCount       int             `json:"txCount"`
+	Failed        int             `json:"failed"`
+	FailureRate   float64         `json:"failureRate"`
+	GasUsed       uint64          `json:"gasUsed"`
+	GasLimit      uint64          `json:"gasLimit"`
+	Utilization   float64         `json:"utilization"`
+	BaseFee       *Distribution   `json:"baseFee,omitempty"`
+	BaseFeeChange float64         `json:"baseFeeChange"` // 最后一个区块相对第一个区块的 base fee 变化比例
+	Burned        *big.Int        `json:"burned"`
+	Tips          *Distribution   `json:"tips,omitempty"`
+	TxTypes       map[string]int  `json:"txTypes"`
+	TopContracts  []ContractCount `json:"topContracts"`
+	TopSelectors  []SelectorCount `json:"topSelectors"`
+}
+
+// Scan 并发扫描 [from, to] 范围内的区块及收据并汇总统计
+func Scan(ctx context.Context, backend Backend, from, to uint64, opts Options) (*Report, error) {
+	if to < from {
+		return nil, fmt.Errorf("结束区块 %d 小于起始区块 %d", to, from)
+	}
+	if opts.MaxBlocks == 0 {
+		opts.MaxBlocks = DefaultMaxBlocks
+	}
+	if to-from >= opts.MaxBlocks {
+		return nil, fmt.Errorf("%w: %d-%d 共 %d 个区块，上限 %d", ErrRangeTooLarge, from, to, to-from+1, opts.MaxBlocks)
+	}
+	if opts.Workers <= 0 {
+		opts.Workers = 8
+	}
+	if opts.Top <= 0 {
+		opts.Top = 10
+	}
+	if opts.Decoder == nil {
+		opts.Decoder = decoder.New(nil)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	stats := make([]BlockStats, to-from+1)
+	numbers := make(chan uint64)
+	var (
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		firstErr error
+	)
+	for i := 0; i < opts.Workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range numbers {
+				s, err := scanBlock(ctx, backend, n)
+				if err != nil {
+					errOnce.Do(func() { firstErr = err; cancel() })
+					continue
+				}
+				stats[n-from] = *s
+			}
+		}()
+	}
+feed:
+	for n := from; n <= to; n++ {
+		select {
+		case numbers <- n:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(numbers)
+	wg.Wait()
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return aggregate(from, to, stats, opts), nil
+}
+
+func scanBlock(ctx context.Context, backend Backend, n uint64) (*BlockStats, error) {
+	block, err := backend.BlockByNumber(ctx, new(big.Int).SetUint64(n))
+	if err != nil {
+		return nil, fmt.Errorf("查询区块 %d 失败: %w", n, err)
+	}
+	receipts, err := backend.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(block.Hash(), false))
+	if err != nil {
+		return nil, fmt.Errorf("查询区块 %d 收据失败: %w", n, err)
+	}
+	txs := block.Transactions()
+	if len(receipts) != len(txs) {
+		return nil, fmt.Errorf("区块 %d 收据数量 %d 与交易数量 %d 不一致", n, len(receipts), len(txs))
+	}
+
+	s := &BlockStats{
+		Number:   n,
+		Time:     block.Time(),
+		TxCount:  len(txs),
+		GasUsed:  block.GasUsed(),
+		GasLimit: block.GasLimit(),
+		BaseFee:  block.BaseFee(),
+		Burned:   new(big.Int),
+		types:    make(map[string]int),
+	}
+	if s.GasLimit > 0 {
+		s.Utilization = float64(s.GasUsed) / float64(s.GasLimit)
+	}
+	if s.BaseFee != nil {
+		s.Burned.Mul(s.BaseFee, new(big.Int).SetUint64(s.GasUsed))
+	}
+
+	for i, tx := range txs {
+		r := receipts[i]
+		s.types[TypeName(tx.Type())]++
+		if r.Status == types.ReceiptStatusFailed {
+			s.Failed++
+		}
+		if r.BlobGasPrice != nil {
+			s.Burned.Add(s.Burned, new(big.Int).Mul(r.BlobGasPrice, new(big.Int).SetUint64(r.BlobGasUsed)))
+		}
+		if s.BaseFee != nil && r.EffectiveGasPrice != nil {
+			tip := new(big.Int).Sub(r.EffectiveGasPrice, s.BaseFee)
+			if tip.Sign() < 0 {
+				tip.SetInt64(0)
+			}
+			s.tips = append(s.tips, tip)
+		}
+		if tx.To() != nil && len(tx.Data()) >= 4 {
+			c := call{to: *tx.To(), gasUsed: r.GasUsed}
+			copy(c.selector[:], tx.Data()[:4])
+			s.calls = append(s.calls, c)
+		}
+	}
+	if dist := distribution(s.tips); dist != nil {
+		s.MedianTip = dist.P50
+	}
+	return s, nil
+}
+
+func aggregate(from, to uint64, stats []BlockStats, opts Options) *Report {
+	r := &Report{
+		From:    from,
+		To:      to,
+		Blocks:  stats,
+		Burned:  new(big.Int),
+		TxTypes: make(map[string]int),
+	}
+
+	var (
+		baseFees  []*big.Int
+		tips      []*big.Int
+		contracts = make(map[common.Address]*ContractCount)
+		selectors = make(map[string]*SelectorCount)
+	)
+	for _, s := range stats {
+		r.TxCount += s.TxCount
+		r.Failed += s.Failed
+		r.GasUsed += s.GasUsed
+		r.GasLimit += s.GasLimit
+		r.Burned.Add(r.Burned, s.Burned)
+		if s.BaseFee != nil {
+			baseFees = append(baseFees, s.BaseFee)
+		}
+		tips = append(tips, s.tips...)
+		for name, n := range s.types {
+			r.TxTypes[name] += n
+		}
+		for _, c := range s.calls {
+			cc, ok := contracts[c.to]
+			if !ok {
+				cc = &ContractCount{Address: c.to, Label: opts.Decoder.Registry().Label(c.to)}
+				contracts[c.to] = cc
+			}
+			cc.Calls++
+			cc.GasUsed += c.gasUsed
+
+			selector := hexutil.Encode(c.selector[:])
+			sc, ok := selectors[selector]
+			if !ok {
+				to := c.to
+				sc = &SelectorCount{Selector: selector, Signature: opts.Decoder.Signature(&to, c.selector)}
+				selectors[selector] = sc
+			}
+			sc.Calls++
+		}
+	}
+	if r.TxCount > 0 {
+		r.FailureRate = float64(r.Failed) / float64(r.TxCount)
+	}
+	if r.GasLimit > 0 {
+		r.Utilization = float64(r.GasUsed) / float64(r.GasLimit)
+	}
+	r.BaseFee = distribution(baseFees)
+	if len(baseFees) >= 2 && baseFees[0].Sign() > 0 {
+		first := new(big.Float).SetInt(baseFees[0])
+		last := new(big.Float).SetInt(baseFees[len(baseFees)-1])
+		change, _ := new(big.Float).Quo(new(big.Float).Sub(last, first), first).Float64()
+		r.BaseFeeChange = change
+	}
+	r.Tips = distribution(tips)
+
+	r.TopContracts = make([]ContractCount, 0, len(contracts))
+	for _, c := range contracts {
+		r.TopContracts = append(r.TopContracts, *c)
+	}
+	sort.Slice(r.TopContracts, func(i, j int) bool {
+		a, b := r.TopContracts[i], r.TopContracts[j]
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		return a.Address.Cmp(b.Address) < 0
+	})
+	if len(r.TopContracts) > opts.Top {
+		r.TopContracts = r.TopContracts[:opts.Top]
+	}
+
+	r.TopSelectors = make([]SelectorCount, 0, len(selectors))
+	for _, s := range selectors {
+		r.TopSelectors = append(r.TopSelectors, *s)
+	}
+	sort.Slice(r.TopSelectors, func(i, j int) bool {
+		a, b := r.TopSelectors[i], r.TopSelectors[j]
+		if a.Calls != b.Calls {
+			return a.Calls > b.Calls
+		}
+		return a.Selector < b.Selector
+	})
+	if len(r.TopSelectors) > opts.Top {
+		r.TopSelectors = r.TopSelectors[:opts.Top]
+	}
+	return r
+}
+
+// distribution 计算分位数，values 为空时返回 nil
+func distribution(values []*big.Int) *Distribution {
+	if len(values) == 0 {
+		return nil
+	}
+	sorted := append([]*big.Int(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	at := func(p int) *big.Int {
+		return new(big.Int).Set(sorted[(len(sorted)-1)*p/100])
+	}
+	return &Distribution{Min: at(0), P10: at(10), P50: at(50), P90: at(90), Max: at(100)}
+}
+
+// TypeName 返回交易类型的可读名称
+func TypeName(t uint8) string {
+	switch t {
+	case types.LegacyTxType:
+		return "legacy"
+	case types.AccessListTxType:
+		return "access-list"
+	case types.DynamicFeeTxType:
+		return "dynamic-fee"
+	case types.BlobTxType:
+		return "blob"
+	case types.SetCodeTxType:
+		return "set-code"
+	default:
+		return fmt.Sprintf("type-%d", t)
+	}
+}
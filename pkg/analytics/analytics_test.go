@@ -0,0 +1,101 @@
+package analytics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// 在模拟链上构造：部署 Store、两次 setItem、一笔 ETH 转账、一笔 legacy 交易和一笔失败交易，然后扫描
+func TestScan(t *testing.T) {
+	ctx := context.Background()
+	key, _ := crypto.GenerateKey()
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	backend := simulated.NewBackend(types.GenesisAlloc{owner: {Balance: new(big.Int).Mul(big.NewInt(100), big.NewInt(params.Ether))}})
+	defer backend.Close()
+	client := backend.Client()
+
+	chainID, _ := client.ChainID(ctx)
+	signer := types.LatestSignerForChainID(chainID)
+	auth, _ := bind.NewKeyedTransactorWithChainID(key, chainID)
+	addr, _, instance, err := store.DeployStore(auth, client, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ 部署 Store 失败: %v", err)
+	}
+	backend.Commit()
+
+	var k, v [32]byte
+	copy(k[:], "k")
+	for i := 0; i < 2; i++ {
+		v[0] = byte(i)
+		if _, err := instance.SetItem(auth, k, v); err != nil {
+			t.Fatalf("❌ SetItem 失败: %v", err)
+		}
+	}
+
+	send := func(inner types.TxData) {
+		tx, err := types.SignNewTx(key, signer, inner)
+		if err != nil {
+			t.Fatalf("❌ 签名失败: %v", err)
+		}
+		if err := client.SendTransaction(ctx, tx); err != nil {
+			t.Fatalf("❌ 发送交易失败: %v", err)
+		}
+	}
+	head, _ := client.HeaderByNumber(ctx, nil)
+	feeCap := new(big.Int).Mul(head.BaseFee, big.NewInt(2))
+	nonce, _ := client.PendingNonceAt(ctx, owner)
+	to := common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+	send(&types.DynamicFeeTx{ChainID: chainID, Nonce: nonce, GasTipCap: big.NewInt(1e9), GasFeeCap: new(big.Int).Add(feeCap, big.NewInt(1e9)), Gas: 21000, To: &to, Value: big.NewInt(1)})
+	send(&types.LegacyTx{Nonce: nonce + 1, GasPrice: feeCap, Gas: 21000, To: &to, Value: big.NewInt(1)})
+	// Store 没有 fallback，未知选择器的调用会 revert
+	send(&types.DynamicFeeTx{ChainID: chainID, Nonce: nonce + 2, GasTipCap: big.NewInt(2e9), GasFeeCap: new(big.Int).Add(feeCap, big.NewInt(2e9)), Gas: 100000, To: &addr, Data: common.FromHex("0xdeadbeef")})
+	backend.Commit()
+
+	last, _ := client.BlockNumber(ctx)
+	// simulated.Client 接口未声明 BlockReceipts，但底层是 *ethclient.Client
+	report, err := Scan(ctx, client.(Backend), 0, last, Options{Workers: 3, Top: 5})
+	if err != nil {
+		t.Fatalf("❌ 扫描失败: %v", err)
+	}
+	if _, err := Scan(ctx, client.(Backend), 0, last, Options{MaxBlocks: last}); !errors.Is(err, ErrRangeTooLarge) {
+		t.Fatalf("❌ 超过 MaxBlocks 应报错: %v", err)
+	}
+
+	if report.TxCount != 6 || report.Failed != 1 {
+		t.Fatalf("❌ 交易数/失败数不符: %d / %d", report.TxCount, report.Failed)
+	}
+	if report.TxTypes["legacy"] != 1 || report.TxTypes["dynamic-fee"] != 5 {
+		t.Fatalf("❌ 交易类型分布不符: %v", report.TxTypes)
+	}
+	if len(report.TopContracts) != 1 || report.TopContracts[0].Address != addr || report.TopContracts[0].Calls != 3 {
+		t.Fatalf("❌ 热门合约不符: %+v", report.TopContracts)
+	}
+	if top := report.TopSelectors[0]; top.Signature != "setItem(bytes32,bytes32)" || top.Calls != 2 {
+		t.Fatalf("❌ 热门方法不符: %+v", report.TopSelectors)
+	}
+	if report.Burned.Sign() <= 0 || report.Tips == nil || report.Tips.Max.Cmp(big.NewInt(2e9)) != 0 {
+		t.Fatalf("❌ 销毁费用或小费分布不符: burned=%s tips=%+v", report.Burned, report.Tips)
+	}
+	t.Logf("✅ 扫描 %d 个区块: gas 使用率 %.4f%%, 销毁 %s wei, base fee 变化 %.2f%%",
+		len(report.Blocks), report.Utilization*100, report.Burned, report.BaseFeeChange*100)
+
+	var buf bytes.Buffer
+	if err := report.WriteCSV(&buf); err != nil {
+		t.Fatalf("❌ 导出 CSV 失败: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(buf.String()), "\n"); len(lines) != int(last)+2 {
+		t.Fatalf("❌ CSV 行数不符: %d", len(lines))
+	}
+}
@@ -0,0 +1,43 @@
+package analytics
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+)
+
+// WriteCSV 按区块导出统计，每行一个区块
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	header := []string{"block", "time", "tx_count", "failed", "gas_used", "gas_limit", "utilization", "base_fee", "burned", "median_tip"}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for _, b := range r.Blocks {
+		if err := cw.Write([]string{
+			fmt.Sprint(b.Number),
+			fmt.Sprint(b.Time),
+			fmt.Sprint(b.TxCount),
+			fmt.Sprint(b.Failed),
+			fmt.Sprint(b.GasUsed),
+			fmt.Sprint(b.GasLimit),
+			strconv.FormatFloat(b.Utilization, 'f', 4, 64),
+			bigString(b.BaseFee),
+			bigString(b.Burned),
+			bigString(b.MedianTip),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func bigString(v *big.Int) string {
+	if v == nil {
+		return ""
+	}
+	return v.String()
+}
@@ -0,0 +1,326 @@
+// Package chainerr 把节点和服务商返回的各种错误（HTTP 状态码、JSON-RPC 错误码、错误消息）
+// 归类为有限的几种类型，调用方用 errors.Is 判断原因、用 errors.As 取出 revert 数据等结构化信息，
+// 并据此决定是否重试
+package chainerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Class 是错误类别
+type Class int
+
+const (
+	Unknown           Class = iota // 无法归类
+	RateLimited                    // 服务商限流（HTTP 429、-32005 等）
+	Timeout                        // 请求超时
+	Unavailable                    // 节点暂时不可用（连接失败、HTTP 502/503）
+	Reverted                       // 合约执行 revert
+	NonceTooLow                    // nonce 过低或已被使用
+	NonceTooHigh                   // nonce 过高（前面有空缺）
+	Underpriced                    // gas 价格过低，或替换交易加价不足
+	InsufficientFunds              // 余额不足以支付 gas * price + value
+	NotFound                       // 区块、交易或收据不存在
+	ChainMismatch                  // 链 ID 与预期不符
+)
+
+var classNames = map[Class]string{
+	Unknown:           "unknown",
+	RateLimited:       "rate-limited",
+	Timeout:           "timeout",
+	Unavailable:       "unavailable",
+	Reverted:          "reverted",
+	NonceTooLow:       "nonce-too-low",
+	NonceTooHigh:      "nonce-too-high",
+	Underpriced:       "underpriced",
+	InsufficientFunds: "insufficient-funds",
+	NotFound:          "not-found",
+	ChainMismatch:     "chain-mismatch",
+}
+
+func (c Class) String() string {
+	if name, ok := classNames[c]; ok {
+		return name
+	}
+	return fmt.Sprintf("class(%d)", int(c))
+}
+
+// Retryable 返回该类错误是否值得重试：限流、超时和节点暂时不可用
+func (c Class) Retryable() bool {
+	return c == RateLimited || c == Timeout || c == Unavailable
+}
+
+// 各类别对应的哨兵错误，用于 errors.Is
+var (
+	ErrRateLimited       = errors.New("节点限流")
+	ErrTimeout           = errors.New("请求超时")
+	ErrUnavailable       = errors.New("节点暂时不可用")
+	ErrReverted          = errors.New("执行 revert")
+	ErrNonceTooLow       = errors.New("nonce 过低")
+	ErrNonceTooHigh      = errors.New("nonce 过高")
+	ErrUnderpriced       = errors.New("gas 价格过低")
+	ErrInsufficientFunds = errors.New("余额不足")
+	ErrNotFound          = errors.New("未找到")
+	ErrChainMismatch     = errors.New("链 ID 不匹配")
+
+	// ErrNonce 匹配所有 nonce 错误（过低和过高）
+	ErrNonce = errors.New("nonce 错误")
+)
+
+var sentinels = map[Class]error{
+	RateLimited:       ErrRateLimited,
+	Timeout:           ErrTimeout,
+	Unavailable:       ErrUnavailable,
+	Reverted:          ErrReverted,
+	NonceTooLow:       ErrNonceTooLow,
+	NonceTooHigh:      ErrNonceTooHigh,
+	Underpriced:       ErrUnderpriced,
+	InsufficientFunds: ErrInsufficientFunds,
+	NotFound:          ErrNotFound,
+	ChainMismatch:     ErrChainMismatch,
+}
+
+// Error 是归类后的错误，保留原始错误；errors.Is 可同时匹配类别哨兵和原始错误
+type Error struct {
+	Class Class
+	Err   error
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s: %v", sentinels[e.Class], e.Err)
+}
+
+// Unwrap 返回原始错误
+func (e *Error) Unwrap() error { return e.Err }
+
+// Is 匹配类别哨兵
+func (e *Error) Is(target error) bool {
+	return matches(e.Class, target)
+}
+
+func matches(class Class, target error) bool {
+	if target == nil {
+		return false
+	}
+	if target == ErrNonce {
+		return class == NonceTooLow || class == NonceTooHigh
+	}
+	return sentinels[class] == target
+}
+
+// RevertError 是合约 revert，带 revert 数据和解码出的原因
+type RevertError struct {
+	Reason string // Error(string) 的消息或 Panic(uint256) 的说明，无法解码时为空
+	Data   []byte // 原始 revert 数据，可按合约 ABI 解码自定义错误
+	Err    error  // 原始错误
+}
+
+func (e *RevertError) Error() string {
+	switch {
+	case e.Reason != "":
+		return fmt.Sprintf("%s: %s", ErrReverted, e.Reason)
+	case len(e.Data) > 0:
+		return fmt.Sprintf("%s: 0x%x", ErrReverted, e.Data)
+	case e.Err != nil:
+		return fmt.Sprintf("%s: %v", ErrReverted, e.Err)
+	}
+	return ErrReverted.Error()
+}
+
+// Unwrap 返回原始错误
+func (e *RevertError) Unwrap() error { return e.Err }
+
+// Is 匹配 ErrReverted
+func (e *RevertError) Is(target error) bool { return target == ErrReverted }
+
+// NewRevertError 根据 revert 数据创建错误，尽量解码原因
+func NewRevertError(data []byte, err error) *RevertError {
+	e := &RevertError{Data: data, Err: err}
+	if reason, uerr := abi.UnpackRevert(data); uerr == nil {
+		e.Reason = reason
+	}
+	return e
+}
+
+// ChainMismatchError 是连接的链与预期不符
+type ChainMismatchError struct {
+	Want, Got *big.Int
+}
+
+func (e *ChainMismatchError) Error() string {
+	return fmt.Sprintf("%s: 期望 %s，实际 %s", ErrChainMismatch, e.Want, e.Got)
+}
+
+// Is 匹配 ErrChainMismatch
+func (e *ChainMismatchError) Is(target error) bool { return target == ErrChainMismatch }
+
+// CheckChainID 比较链 ID，不一致时返回 *ChainMismatchError
+func CheckChainID(want, got *big.Int) error {
+	if want == nil || got == nil || want.Cmp(got) == 0 {
+		return nil
+	}
+	return &ChainMismatchError{Want: new(big.Int).Set(want), Got: new(big.Int).Set(got)}
+}
+
+// Wrap 归类错误：revert 返回带数据的 *RevertError，其他能归类的错误返回 *Error，否则原样返回
+func Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	var ce *Error
+	var re *RevertError
+	var me *ChainMismatchError
+	if errors.As(err, &ce) || errors.As(err, &re) || errors.As(err, &me) {
+		return err
+	}
+	class := Classify(err)
+	switch class {
+	case Unknown:
+		return err
+	case Reverted:
+		return NewRevertError(revertData(err), err)
+	}
+	return &Error{Class: class, Err: err}
+}
+
+// revertData 从 JSON-RPC 错误的 data 字段取出 revert 数据
+func revertData(err error) []byte {
+	var de rpc.DataError
+	if !errors.As(err, &de) {
+		return nil
+	}
+	s, ok := de.ErrorData().(string)
+	if !ok {
+		return nil
+	}
+	data, derr := hexutil.Decode(s)
+	if derr != nil {
+		return nil
+	}
+	return data
+}
+
+// 错误消息关键字（小写），按顺序匹配
+var patterns = []struct {
+	class    Class
+	keywords []string
+}{
+	{RateLimited, []string{"rate limit", "too many requests", "request limit exceeded", "throughput", "compute units per second", "capacity exceeded"}},
+	{Reverted, []string{"execution reverted", "vm exception while processing transaction: revert", "reverted"}},
+	{NonceTooLow, []string{"nonce too low", "invalid nonce", "nonce has already been used", "nonce is too low"}},
+	{NonceTooHigh, []string{"nonce too high"}},
+	{Underpriced, []string{"underpriced", "fee cap less than block base fee", "max fee per gas less than block base fee", "gas price too low", "tip too low"}},
+	{InsufficientFunds, []string{"insufficient funds"}},
+	{ChainMismatch, []string{"invalid chain id", "chain id mismatch", "incorrect chain id"}},
+	{NotFound, []string{"not found", "unknown block"}},
+	{Timeout, []string{"timeout", "timed out"}},
+	{Unavailable, []string{"connection refused", "connection reset", "no such host", "service unavailable", "bad gateway", "indexing is in progress"}},
+}
+
+// 节点不支持该方法（JSON-RPC -32601），与“交易/区块不存在”无关，重试和轮询都没有意义
+var methodNotFound = []string{"method not found", "does not exist/is not available"}
+
+// Classify 判断错误类别。取消的 context 不属于任何可重试类别
+func Classify(err error) Class {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return Unknown
+	}
+	var ce *Error
+	if errors.As(err, &ce) {
+		return ce.Class
+	}
+	if errors.Is(err, ErrReverted) {
+		return Reverted
+	}
+	if errors.Is(err, ErrChainMismatch) || errors.Is(err, types.ErrInvalidChainId) {
+		return ChainMismatch
+	}
+	if errors.Is(err, ethereum.NotFound) {
+		return NotFound
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, os.ErrDeadlineExceeded) {
+		return Timeout
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return Timeout
+	}
+	if errors.Is(err, syscall.ECONNREFUSED) || errors.Is(err, syscall.ECONNRESET) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return Unavailable
+	}
+
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) {
+		if class := ClassifyStatus(httpErr.StatusCode); class != Unknown {
+			return class
+		}
+	}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		switch rpcErr.ErrorCode() {
+		case -32005: // 常见服务商的 limit exceeded
+			return RateLimited
+		case -32601: // method not found
+			return Unknown
+		case 3: // geth: execution reverted（带 revert 数据）
+			return Reverted
+		}
+	}
+	return ClassifyMessage(err.Error())
+}
+
+// ClassifyStatus 按 HTTP 状态码归类
+func ClassifyStatus(code int) Class {
+	switch code {
+	case http.StatusTooManyRequests:
+		return RateLimited
+	case http.StatusRequestTimeout, http.StatusGatewayTimeout:
+		return Timeout
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return Unavailable
+	}
+	return Unknown
+}
+
+// ClassifyMessage 按错误消息归类
+func ClassifyMessage(msg string) Class {
+	msg = strings.ToLower(msg)
+	for _, k := range methodNotFound {
+		if strings.Contains(msg, k) {
+			return Unknown
+		}
+	}
+	for _, p := range patterns {
+		for _, k := range p.keywords {
+			if strings.Contains(msg, k) {
+				return p.class
+			}
+		}
+	}
+	return Unknown
+}
+
+// Is 判断错误是否属于指定类别
+func Is(err error, class Class) bool {
+	return Classify(err) == class
+}
+
+// Err 返回类别对应的哨兵错误，Unknown 返回 nil
+func (c Class) Err() error {
+	return sentinels[c]
+}
@@ -0,0 +1,125 @@
+package chainerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+type codeError struct {
+	code int
+	msg  string
+}
+
+func (e codeError) Error() string  { return e.msg }
+func (e codeError) ErrorCode() int { return e.code }
+
+var _ rpc.Error = codeError{}
+
+// dataError 模拟 geth 返回的带 data 的 revert 错误
+type dataError struct {
+	codeError
+	data string
+}
+
+func (e dataError) ErrorData() interface{} { return e.data }
+
+// 测试常见节点/服务商错误的归类以及 errors.Is 匹配
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		err  error
+		want Class
+	}{
+		{rpc.HTTPError{StatusCode: 429, Status: "429 Too Many Requests"}, RateLimited},
+		{codeError{-32005, "request rate exceeded"}, RateLimited},
+		{errors.New("Your app has exceeded its compute units per second capacity"), RateLimited},
+		{context.DeadlineExceeded, Timeout},
+		{rpc.HTTPError{StatusCode: 504, Status: "504 Gateway Timeout"}, Timeout},
+		{rpc.HTTPError{StatusCode: 503, Status: "503 Service Unavailable"}, Unavailable},
+		{errors.New("dial tcp 127.0.0.1:8545: connect: connection refused"), Unavailable},
+		{errors.New("transaction indexing is in progress"), Unavailable},
+		{codeError{3, "execution reverted: ERC20: transfer amount exceeds balance"}, Reverted},
+		{errors.New("VM Exception while processing transaction: reverted with reason string 'x'"), Reverted},
+		{errors.New("nonce too low: next nonce 5, tx nonce 3"), NonceTooLow},
+		{errors.New("nonce too high"), NonceTooHigh},
+		{errors.New("replacement transaction underpriced"), Underpriced},
+		{errors.New("max fee per gas less than block base fee: address 0x8c8a..., maxFeePerGas: 1, baseFee: 7"), Underpriced},
+		{errors.New("insufficient funds for gas * price + value: balance 0, tx cost 21000"), InsufficientFunds},
+		{fmt.Errorf("签名失败: %w", types.ErrInvalidChainId), ChainMismatch},
+		{ethereum.NotFound, NotFound},
+		{errors.New("header not found"), NotFound},
+		{context.Canceled, Unknown},
+		{errors.New("gas required exceeds allowance"), Unknown},
+		{codeError{-32005, "request limit exceeded"}, RateLimited},
+		{errors.New("daily request limit exceeded"), RateLimited},
+		{errors.New("gas limit exceeded"), Unknown},
+		{errors.New("exceeds block gas limit"), Unknown},
+		{codeError{-32601, "Method not found"}, Unknown},
+		{codeError{-32601, "the method eth_getTransactionReceipt does not exist/is not available"}, Unknown},
+		{errors.New("method not found"), Unknown},
+	}
+	for _, c := range cases {
+		if got := Classify(c.err); got != c.want {
+			t.Fatalf("❌ %q 应归类为 %s，实际 %s", c.err, c.want, got)
+		}
+	}
+
+	err := Wrap(fmt.Errorf("查询余额失败: %w", rpc.HTTPError{StatusCode: 429}))
+	if !errors.Is(err, ErrRateLimited) || !Classify(err).Retryable() {
+		t.Fatalf("❌ 包装后应能用 errors.Is 判断: %v", err)
+	}
+	var httpErr rpc.HTTPError
+	if !errors.As(err, &httpErr) {
+		t.Fatal("❌ 包装后应保留原始错误")
+	}
+	if Reverted.Retryable() || NonceTooLow.Retryable() || NotFound.Retryable() {
+		t.Fatal("❌ revert/nonce/not found 不应重试")
+	}
+	if plain := errors.New("其他错误"); Wrap(plain) != plain {
+		t.Fatal("❌ 无法归类的错误应原样返回")
+	}
+	t.Logf("✅ %v", err)
+}
+
+// 测试结构化错误：revert 数据与原因、nonce 总类、链 ID 不匹配
+func TestStructuredErrors(t *testing.T) {
+	// Error(string) 编码的 "Ownable: caller is not the owner"
+	data := hexutil.MustDecode("0x08c379a0" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000020" +
+		"4f776e61626c653a2063616c6c6572206973206e6f7420746865206f776e6572")
+	err := Wrap(fmt.Errorf("调用合约失败: %w", dataError{codeError{3, "execution reverted: Ownable: caller is not the owner"}, hexutil.Encode(data)}))
+	var revert *RevertError
+	if !errors.As(err, &revert) || !errors.Is(err, ErrReverted) {
+		t.Fatalf("❌ 应包装为 *RevertError: %v", err)
+	}
+	if revert.Reason != "Ownable: caller is not the owner" || len(revert.Data) != len(data) {
+		t.Fatalf("❌ revert 原因解码错误: %q", revert.Reason)
+	}
+	var rpcErr rpc.Error
+	if !errors.As(err, &rpcErr) || rpcErr.ErrorCode() != 3 {
+		t.Fatal("❌ 应保留原始 JSON-RPC 错误")
+	}
+
+	nonce := Wrap(errors.New("nonce too low"))
+	if !errors.Is(nonce, ErrNonceTooLow) || !errors.Is(nonce, ErrNonce) || errors.Is(nonce, ErrNonceTooHigh) {
+		t.Fatalf("❌ nonce 错误匹配不正确: %v", nonce)
+	}
+
+	mismatch := CheckChainID(big.NewInt(97), big.NewInt(1))
+	var me *ChainMismatchError
+	if !errors.Is(mismatch, ErrChainMismatch) || !errors.As(mismatch, &me) || me.Got.Int64() != 1 {
+		t.Fatalf("❌ 链 ID 不匹配应返回 *ChainMismatchError: %v", mismatch)
+	}
+	if CheckChainID(big.NewInt(97), big.NewInt(97)) != nil {
+		t.Fatal("❌ 链 ID 一致时不应报错")
+	}
+	t.Logf("✅ %v / %v", err, mismatch)
+}
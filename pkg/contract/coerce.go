@@ -0,0 +1,312 @@
+package contract
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Coerce 把参数转换为 ABI 类型 typ 对应的 Go 值。v 可以是已经正确类型的值，也可以是：
+//   - 字符串：地址为十六进制；整数为十进制、0x 十六进制或科学计数法（如 1.5e18）；布尔为 true/false；
+//     bytes/bytesN 为 0x 十六进制，不带 0x 时按 UTF-8 文本（bytesN 右侧补 0）；数组和 tuple 为 JSON
+//   - JSON 解码得到的值：json.Number、float64、bool、[]interface{}、map[string]interface{}（tuple 按字段名）
+func Coerce(typ abi.Type, v interface{}) (interface{}, error) {
+	rv, err := coerce(typ, v)
+	if err != nil {
+		return nil, err
+	}
+	return rv.Interface(), nil
+}
+
+// CoerceArgs 按方法（或构造函数、事件）的参数列表转换参数
+func CoerceArgs(inputs abi.Arguments, args []interface{}) ([]interface{}, error) {
+	if len(args) != len(inputs) {
+		return nil, fmt.Errorf("需要 %d 个参数，传入 %d 个", len(inputs), len(args))
+	}
+	out := make([]interface{}, len(args))
+	for i, in := range inputs {
+		v, err := Coerce(in.Type, args[i])
+		if err != nil {
+			name := in.Name
+			if name == "" {
+				name = fmt.Sprintf("#%d", i)
+			}
+			return nil, fmt.Errorf("参数 %s (%s): %w", name, in.Type, err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func coerce(typ abi.Type, v interface{}) (reflect.Value, error) {
+	goType := typ.GetType()
+	if v != nil && reflect.TypeOf(v) == goType {
+		return reflect.ValueOf(v), nil
+	}
+	// 数组、切片和 tuple 的字符串形式是 JSON
+	if s, ok := v.(string); ok && (typ.T == abi.SliceTy || typ.T == abi.ArrayTy || typ.T == abi.TupleTy) {
+		var decoded interface{}
+		dec := json.NewDecoder(strings.NewReader(s))
+		dec.UseNumber()
+		if err := dec.Decode(&decoded); err != nil {
+			return reflect.Value{}, fmt.Errorf("%s 需要 JSON 格式: %w", typ, err)
+		}
+		v = decoded
+	}
+
+	switch typ.T {
+	case abi.IntTy, abi.UintTy:
+		n, err := toBigInt(v)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return intValue(typ, n)
+
+	case abi.BoolTy:
+		switch b := v.(type) {
+		case bool:
+			return reflect.ValueOf(b), nil
+		case string:
+			parsed, err := strconv.ParseBool(b)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("无效的布尔值 %q", b)
+			}
+			return reflect.ValueOf(parsed), nil
+		}
+
+	case abi.StringTy:
+		if s, ok := v.(string); ok {
+			return reflect.ValueOf(s), nil
+		}
+
+	case abi.AddressTy:
+		switch a := v.(type) {
+		case string:
+			if !common.IsHexAddress(a) {
+				return reflect.Value{}, fmt.Errorf("无效的地址 %q", a)
+			}
+			return reflect.ValueOf(common.HexToAddress(a)), nil
+		case *common.Address:
+			return reflect.ValueOf(*a), nil
+		}
+
+	case abi.BytesTy:
+		switch b := v.(type) {
+		case []byte:
+			return reflect.ValueOf(b), nil
+		case string:
+			return bytesValue(b)
+		}
+
+	case abi.FixedBytesTy:
+		var raw []byte
+		switch b := v.(type) {
+		case []byte:
+			raw = b
+		case common.Hash:
+			raw = b.Bytes()
+		case string:
+			if !has0x(b) {
+				raw = []byte(b) // 文本，右侧补 0，与 copy(key[:], "mykey") 一致
+				break
+			}
+			rv, err := bytesValue(b)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			raw = rv.Bytes()
+			if len(raw) != typ.Size {
+				return reflect.Value{}, fmt.Errorf("bytes%d 需要 %d 字节，传入 %d 字节", typ.Size, typ.Size, len(raw))
+			}
+		default:
+			return reflect.Value{}, fmt.Errorf("无法把 %T 转换为 %s", v, typ)
+		}
+		if len(raw) > typ.Size {
+			return reflect.Value{}, fmt.Errorf("bytes%d 最多 %d 字节，传入 %d 字节", typ.Size, typ.Size, len(raw))
+		}
+		out := reflect.New(goType).Elem()
+		reflect.Copy(out, reflect.ValueOf(raw))
+		return out, nil
+
+	case abi.SliceTy, abi.ArrayTy:
+		items, ok := toSlice(v)
+		if !ok {
+			break
+		}
+		if typ.T == abi.ArrayTy && len(items) != typ.Size {
+			return reflect.Value{}, fmt.Errorf("%s 需要 %d 个元素，传入 %d 个", typ, typ.Size, len(items))
+		}
+		var out reflect.Value
+		if typ.T == abi.SliceTy {
+			out = reflect.MakeSlice(goType, len(items), len(items))
+		} else {
+			out = reflect.New(goType).Elem()
+		}
+		for i, item := range items {
+			elem, err := coerce(*typ.Elem, item)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("第 %d 个元素: %w", i, err)
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+
+	case abi.TupleTy:
+		out := reflect.New(goType).Elem()
+		switch t := v.(type) {
+		case map[string]interface{}:
+			if len(t) != len(typ.TupleElems) {
+				return reflect.Value{}, fmt.Errorf("%s 需要 %d 个字段，传入 %d 个", typ, len(typ.TupleElems), len(t))
+			}
+			for i, name := range typ.TupleRawNames {
+				field, ok := t[name]
+				if !ok {
+					return reflect.Value{}, fmt.Errorf("缺少字段 %s", name)
+				}
+				if err := setField(out, i, *typ.TupleElems[i], field, name); err != nil {
+					return reflect.Value{}, err
+				}
+			}
+			return out, nil
+		case []interface{}:
+			if len(t) != len(typ.TupleElems) {
+				return reflect.Value{}, fmt.Errorf("%s 需要 %d 个字段，传入 %d 个", typ, len(typ.TupleElems), len(t))
+			}
+			for i, field := range t {
+				if err := setField(out, i, *typ.TupleElems[i], field, typ.TupleRawNames[i]); err != nil {
+					return reflect.Value{}, err
+				}
+			}
+			return out, nil
+		}
+	}
+
+	// 其他可以直接转换的 Go 值，例如 int 转 uint8
+	if v != nil {
+		rv := reflect.ValueOf(v)
+		if rv.Type().ConvertibleTo(goType) && rv.Kind() != reflect.String {
+			return rv.Convert(goType), nil
+		}
+	}
+	return reflect.Value{}, fmt.Errorf("无法把 %T 转换为 %s", v, typ)
+}
+
+func setField(out reflect.Value, i int, typ abi.Type, v interface{}, name string) error {
+	field, err := coerce(typ, v)
+	if err != nil {
+		return fmt.Errorf("字段 %s: %w", name, err)
+	}
+	out.Field(i).Set(field)
+	return nil
+}
+
+func toSlice(v interface{}) ([]interface{}, bool) {
+	if items, ok := v.([]interface{}); ok {
+		return items, true
+	}
+	rv := reflect.ValueOf(v)
+	if !rv.IsValid() || (rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array) {
+		return nil, false
+	}
+	items := make([]interface{}, rv.Len())
+	for i := range items {
+		items[i] = rv.Index(i).Interface()
+	}
+	return items, true
+}
+
+func has0x(s string) bool {
+	return strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X")
+}
+
+func bytesValue(s string) (reflect.Value, error) {
+	if !has0x(s) {
+		return reflect.ValueOf([]byte(s)), nil
+	}
+	raw, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("无效的十六进制 %q: %w", s, err)
+	}
+	return reflect.ValueOf(raw), nil
+}
+
+func toBigInt(v interface{}) (*big.Int, error) {
+	switch n := v.(type) {
+	case *big.Int:
+		return n, nil
+	case json.Number:
+		return parseBigInt(n.String())
+	case string:
+		return parseBigInt(n)
+	case float64:
+		return parseBigInt(strconv.FormatFloat(n, 'f', -1, 64))
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return big.NewInt(rv.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	}
+	return nil, fmt.Errorf("无法把 %T 转换为整数", v)
+}
+
+// parseBigInt 解析十进制、0x 十六进制和不带小数部分的科学计数法（1e18、1.5e18）。
+// 前导 0 按十进制处理（"010" 为 10），不支持八进制和二进制写法
+func parseBigInt(s string) (*big.Int, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "_", "")
+	digits := strings.TrimLeft(s, "+-")
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0X") {
+		n, ok := new(big.Int).SetString(digits[2:], 16)
+		if !ok || len(s)-len(digits) > 1 {
+			return nil, fmt.Errorf("无效的十六进制整数 %q", s)
+		}
+		if s[0] == '-' {
+			n.Neg(n)
+		}
+		return n, nil
+	}
+	if n, ok := new(big.Int).SetString(s, 10); ok {
+		return n, nil
+	}
+	// big.Float 也接受 0b/0o 前缀，这里只用它解析科学计数法
+	if len(digits) > 1 && digits[0] == '0' && strings.ContainsRune("bBoO", rune(digits[1])) {
+		return nil, fmt.Errorf("无效的整数 %q", s)
+	}
+	if f, ok := new(big.Float).SetPrec(512).SetString(s); ok {
+		if n, acc := f.Int(nil); acc == big.Exact {
+			return n, nil
+		}
+		return nil, fmt.Errorf("%q 不是整数", s)
+	}
+	return nil, fmt.Errorf("无效的整数 %q", s)
+}
+
+// intValue 检查范围并转换为 ABI 类型对应的 Go 类型（不超过 64 位时为 int8…uint64，否则为 *big.Int）
+func intValue(typ abi.Type, n *big.Int) (reflect.Value, error) {
+	if typ.T == abi.UintTy {
+		if n.Sign() < 0 || n.BitLen() > typ.Size {
+			return reflect.Value{}, fmt.Errorf("%s 超出 %s 的范围", n, typ)
+		}
+	} else {
+		limit := new(big.Int).Lsh(big.NewInt(1), uint(typ.Size-1))
+		if n.Cmp(limit) >= 0 || n.Cmp(new(big.Int).Neg(limit)) < 0 {
+			return reflect.Value{}, fmt.Errorf("%s 超出 %s 的范围", n, typ)
+		}
+	}
+	goType := typ.GetType()
+	switch goType.Kind() {
+	case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return reflect.ValueOf(n.Int64()).Convert(goType), nil
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return reflect.ValueOf(n.Uint64()).Convert(goType), nil
+	}
+	return reflect.ValueOf(new(big.Int).Set(n)), nil
+}
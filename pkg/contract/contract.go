@@ -0,0 +1,396 @@
+// Package contract 是运行时加载 ABI 的通用合约客户端：不需要 abigen 生成代码，
+// 按方法名调用（参数可以是字符串，自动转换为 ABI 类型）、发送交易、查询和订阅事件
+package contract
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// ErrNoMethod 表示 ABI 中没有该方法或事件
+var ErrNoMethod = errors.New("ABI 中没有该方法")
+
+// Contract 是一个按 ABI 动态调用的合约
+type Contract struct {
+	Address common.Address
+	ABI     *abi.ABI
+
+	backend bind.ContractBackend
+	bound   *bind.BoundContract
+}
+
+// New 创建合约客户端
+func New(addr common.Address, contractABI *abi.ABI, backend bind.ContractBackend) *Contract {
+	return &Contract{
+		Address: addr,
+		ABI:     contractABI,
+		backend: backend,
+		bound:   bind.NewBoundContract(addr, *contractABI, backend, backend, backend),
+	}
+}
+
+// Load 从 ABI JSON 文件创建合约客户端（也接受 solc/Hardhat 产物中带 "abi" 字段的 JSON）
+func Load(addr common.Address, abiPath string, backend bind.ContractBackend) (*Contract, error) {
+	data, err := os.ReadFile(abiPath)
+	if err != nil {
+		return nil, fmt.Errorf("读取 ABI 文件失败: %w", err)
+	}
+	parsed, err := ParseABI(data)
+	if err != nil {
+		return nil, err
+	}
+	return New(addr, parsed, backend), nil
+}
+
+// ParseABI 解析 ABI JSON，数组形式或带 "abi" 字段的构建产物都可以
+func ParseABI(data []byte) (*abi.ABI, error) {
+	var artifact struct {
+		ABI json.RawMessage `json:"abi"`
+	}
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "{") {
+		if err := json.Unmarshal(data, &artifact); err == nil && len(artifact.ABI) > 0 {
+			data = artifact.ABI
+		}
+	}
+	parsed, err := abi.JSON(strings.NewReader(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("解析 ABI 失败: %w", err)
+	}
+	return &parsed, nil
+}
+
+// Method 按名字（重载方法为 name、name0…）或签名（如 transfer(address,uint256)）查找方法；
+// 名字有多个重载时按参数个数选择
+func (c *Contract) Method(name string, nargs int) (abi.Method, error) {
+	if m, ok := c.ABI.Methods[name]; ok && (nargs < 0 || len(m.Inputs) == nargs) {
+		return m, nil
+	}
+	var candidates []abi.Method
+	for _, m := range c.ABI.Methods {
+		if m.Sig == strings.ReplaceAll(name, " ", "") {
+			return m, nil
+		}
+		if m.RawName == name && (nargs < 0 || len(m.Inputs) == nargs) {
+			candidates = append(candidates, m)
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+	if len(candidates) > 1 {
+		sigs := make([]string, len(candidates))
+		for i, m := range candidates {
+			sigs[i] = m.Sig
+		}
+		sort.Strings(sigs)
+		return abi.Method{}, fmt.Errorf("%s 有多个重载，请使用完整签名: %s", name, strings.Join(sigs, ", "))
+	}
+	for _, m := range c.ABI.Methods {
+		if m.RawName == name {
+			return abi.Method{}, fmt.Errorf("%s 需要 %d 个参数，传入 %d 个", m.Sig, len(m.Inputs), nargs)
+		}
+	}
+	return abi.Method{}, fmt.Errorf("%w: %s（可用: %s）", ErrNoMethod, name, strings.Join(c.methodNames(), ", "))
+}
+
+func (c *Contract) methodNames() []string {
+	names := make([]string, 0, len(c.ABI.Methods))
+	for _, m := range c.ABI.Methods {
+		names = append(names, m.Sig)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Pack 编码调用数据，参数按 Coerce 的规则转换
+func (c *Contract) Pack(method string, args ...interface{}) (abi.Method, []byte, error) {
+	m, err := c.Method(method, len(args))
+	if err != nil {
+		return abi.Method{}, nil, err
+	}
+	coerced, err := CoerceArgs(m.Inputs, args)
+	if err != nil {
+		return m, nil, fmt.Errorf("%s: %w", m.Sig, err)
+	}
+	packed, err := m.Inputs.Pack(coerced...)
+	if err != nil {
+		return m, nil, fmt.Errorf("编码 %s 参数失败: %w", m.Sig, err)
+	}
+	return m, append(append([]byte{}, m.ID...), packed...), nil
+}
+
+// Call 调用只读方法，返回解码后的返回值
+func (c *Contract) Call(opts *bind.CallOpts, method string, args ...interface{}) ([]interface{}, error) {
+	m, data, err := c.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	if opts == nil {
+		opts = &bind.CallOpts{}
+	}
+	ctx := opts.Context
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	msg := ethereum.CallMsg{From: opts.From, To: &c.Address, Data: data}
+	var out []byte
+	if opts.Pending {
+		pending, ok := c.backend.(bind.PendingContractCaller)
+		if !ok {
+			return nil, errors.New("节点不支持查询 pending 状态")
+		}
+		out, err = pending.PendingCallContract(ctx, msg)
+	} else {
+		out, err = c.backend.CallContract(ctx, msg, opts.BlockNumber)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("调用 %s 失败: %w", m.Sig, chainerr.Wrap(err))
+	}
+	if len(out) == 0 && len(m.Outputs) > 0 {
+		if code, err := c.backend.CodeAt(ctx, c.Address, opts.BlockNumber); err == nil && len(code) == 0 {
+			return nil, bind.ErrNoCode
+		}
+	}
+	values, err := m.Outputs.Unpack(out)
+	if err != nil {
+		return nil, fmt.Errorf("解码 %s 返回值失败: %w", m.Sig, err)
+	}
+	return values, nil
+}
+
+// Transact 发送交易调用方法
+func (c *Contract) Transact(opts *bind.TransactOpts, method string, args ...interface{}) (*types.Transaction, error) {
+	m, data, err := c.Pack(method, args...)
+	if err != nil {
+		return nil, err
+	}
+	if m.IsConstant() {
+		return nil, fmt.Errorf("%s 是只读方法（%s），请使用 Call", m.Sig, m.StateMutability)
+	}
+	if opts.Value != nil && opts.Value.Sign() > 0 && !m.IsPayable() {
+		return nil, fmt.Errorf("%s 不是 payable 方法，不能转入 ETH", m.Sig)
+	}
+	tx, err := c.bound.RawTransact(opts, data)
+	if err != nil {
+		return nil, fmt.Errorf("发送 %s 交易失败: %w", m.Sig, chainerr.Wrap(err))
+	}
+	return tx, nil
+}
+
+// Event 是解码后的事件
+type Event struct {
+	Name string                 `json:"name"`
+	Args map[string]interface{} `json:"args"`
+	Log  types.Log              `json:"log"`
+}
+
+// event 按名字或签名查找事件
+func (c *Contract) event(name string) (abi.Event, error) {
+	if e, ok := c.ABI.Events[name]; ok {
+		return e, nil
+	}
+	for _, e := range c.ABI.Events {
+		if e.Sig == strings.ReplaceAll(name, " ", "") {
+			return e, nil
+		}
+	}
+	return abi.Event{}, fmt.Errorf("%w: 事件 %s", ErrNoMethod, name)
+}
+
+// query 构造过滤条件，filters 按顺序对应 indexed 参数，nil 表示不限
+func (c *Contract) query(name string, filters []interface{}) (abi.Event, ethereum.FilterQuery, error) {
+	e, err := c.event(name)
+	if err != nil {
+		return e, ethereum.FilterQuery{}, err
+	}
+	var indexed abi.Arguments
+	for _, in := range e.Inputs {
+		if in.Indexed {
+			indexed = append(indexed, in)
+		}
+	}
+	if len(filters) > len(indexed) {
+		return e, ethereum.FilterQuery{}, fmt.Errorf("%s 只有 %d 个 indexed 参数", e.Sig, len(indexed))
+	}
+	rules := make([][]interface{}, len(filters))
+	for i, f := range filters {
+		if f == nil {
+			continue
+		}
+		v, err := Coerce(indexed[i].Type, f)
+		if err != nil {
+			return e, ethereum.FilterQuery{}, fmt.Errorf("过滤参数 %s: %w", indexed[i].Name, err)
+		}
+		rules[i] = []interface{}{v}
+	}
+	topics, err := abi.MakeTopics(rules...)
+	if err != nil {
+		return e, ethereum.FilterQuery{}, fmt.Errorf("构造 topic 失败: %w", err)
+	}
+	q := ethereum.FilterQuery{Addresses: []common.Address{c.Address}, Topics: append([][]common.Hash{{e.ID}}, topics...)}
+	return e, q, nil
+}
+
+// FilterEvents 查询 [from, to] 区块范围内的事件，to 为 nil 表示最新区块
+func (c *Contract) FilterEvents(ctx context.Context, name string, from uint64, to *uint64, filters ...interface{}) ([]*Event, error) {
+	e, q, err := c.query(name, filters)
+	if err != nil {
+		return nil, err
+	}
+	q.FromBlock = new(big.Int).SetUint64(from)
+	if to != nil {
+		q.ToBlock = new(big.Int).SetUint64(*to)
+	}
+	logs, err := c.backend.FilterLogs(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s 事件失败: %w", e.Name, chainerr.Wrap(err))
+	}
+	events := make([]*Event, 0, len(logs))
+	for _, l := range logs {
+		ev, err := c.decode(e, l)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// WatchEvents 订阅事件（需要 WebSocket 等支持订阅的连接），解码后发送到 ch，直到订阅取消
+func (c *Contract) WatchEvents(ctx context.Context, name string, ch chan<- *Event, filters ...interface{}) (ethereum.Subscription, error) {
+	e, q, err := c.query(name, filters)
+	if err != nil {
+		return nil, err
+	}
+	logs := make(chan types.Log)
+	sub, err := c.backend.SubscribeFilterLogs(ctx, q, logs)
+	if err != nil {
+		return nil, fmt.Errorf("订阅 %s 事件失败: %w", e.Name, chainerr.Wrap(err))
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case l := <-logs:
+				ev, err := c.decode(e, l)
+				if err != nil {
+					return err
+				}
+				select {
+				case ch <- ev:
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+func (c *Contract) decode(e abi.Event, l types.Log) (*Event, error) {
+	args := make(map[string]interface{})
+	if len(l.Data) > 0 {
+		if err := e.Inputs.UnpackIntoMap(args, l.Data); err != nil {
+			return nil, fmt.Errorf("解码 %s 事件数据失败: %w", e.Name, err)
+		}
+	}
+	var indexed abi.Arguments
+	for _, in := range e.Inputs {
+		if in.Indexed {
+			indexed = append(indexed, in)
+		}
+	}
+	if len(l.Topics) > 0 {
+		if err := abi.ParseTopicsIntoMap(args, indexed, l.Topics[1:]); err != nil {
+			return nil, fmt.Errorf("解码 %s 事件 topic 失败: %w", e.Name, err)
+		}
+	}
+	return &Event{Name: e.Name, Args: args, Log: l}, nil
+}
+
+// Format 把 ABI 解码得到的值格式化为可读字符串：字节为十六进制，地址为校验和格式，数组和 tuple 为 JSON
+func Format(v interface{}) string {
+	plain := Plain(v)
+	if s, ok := plain.(string); ok {
+		return s
+	}
+	data, err := json.Marshal(plain)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+// Plain 把 ABI 解码得到的值转换为适合 JSON 输出的值：整数为十进制字符串，字节为 0x 十六进制，
+// 数组为 []interface{}，tuple 为按字段名索引的 map
+func Plain(v interface{}) interface{} {
+	switch t := v.(type) {
+	case nil:
+		return nil
+	case *big.Int:
+		return t.String()
+	case common.Address:
+		return t.Hex()
+	case common.Hash:
+		return t.Hex()
+	case []byte:
+		return "0x" + hex.EncodeToString(t)
+	case string, bool:
+		return t
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return fmt.Sprint(rv.Int())
+	case reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uint8:
+		return fmt.Sprint(rv.Uint())
+	case reflect.Array:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			raw := make([]byte, rv.Len())
+			reflect.Copy(reflect.ValueOf(raw), rv)
+			return "0x" + hex.EncodeToString(raw)
+		}
+		fallthrough
+	case reflect.Slice:
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = Plain(rv.Index(i).Interface())
+		}
+		return items
+	case reflect.Struct:
+		fields := make(map[string]interface{}, rv.NumField())
+		for i := 0; i < rv.NumField(); i++ {
+			name := rv.Type().Field(i).Name
+			if tag := rv.Type().Field(i).Tag.Get("json"); tag != "" {
+				name = tag
+			}
+			fields[name] = Plain(rv.Field(i).Interface())
+		}
+		return fields
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return nil
+		}
+		return Plain(rv.Elem().Interface())
+	}
+	return fmt.Sprint(v)
+}
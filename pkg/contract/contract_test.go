@@ -0,0 +1,197 @@
+package contract
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/token"
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 覆盖各种参数类型的 ABI，只用于编码测试
+const typesABI = `[
+	{"type":"function","name":"ints","stateMutability":"nonpayable","inputs":[{"name":"a","type":"uint8"},{"name":"b","type":"int256"},{"name":"c","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"fixed","stateMutability":"nonpayable","inputs":[{"name":"key","type":"bytes32"},{"name":"data","type":"bytes"},{"name":"ok","type":"bool"}],"outputs":[]},
+	{"type":"function","name":"lists","stateMutability":"nonpayable","inputs":[{"name":"addrs","type":"address[]"},{"name":"pair","type":"uint16[2]"}],"outputs":[]},
+	{"type":"function","name":"order","stateMutability":"nonpayable","inputs":[{"name":"o","type":"tuple","components":[{"name":"maker","type":"address"},{"name":"amount","type":"uint256"},{"name":"tags","type":"string[]"}]}],"outputs":[]},
+	{"type":"function","name":"set","stateMutability":"nonpayable","inputs":[{"name":"v","type":"uint256"}],"outputs":[]},
+	{"type":"function","name":"set","stateMutability":"nonpayable","inputs":[{"name":"k","type":"bytes32"},{"name":"v","type":"uint256"}],"outputs":[]}
+]`
+
+// 测试字符串参数转换为 ABI 类型
+func TestCoerce(t *testing.T) {
+	parsed, err := ParseABI([]byte(typesABI))
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	c := New(common.Address{}, parsed, nil)
+	addr := "0x70997970C51812dc3A010C7d01b50e0d17dc79C8"
+
+	pack := func(method string, args ...interface{}) []interface{} {
+		t.Helper()
+		m, data, err := c.Pack(method, args...)
+		if err != nil {
+			t.Fatalf("❌ 编码 %s 失败: %v", method, err)
+		}
+		values, err := m.Inputs.Unpack(data[4:])
+		if err != nil {
+			t.Fatalf("❌ 解码 %s 失败: %v", method, err)
+		}
+		return values
+	}
+
+	v := pack("ints", "255", "-0x10", "1.5e18")
+	if v[0].(uint8) != 255 || v[1].(*big.Int).Int64() != -16 || v[2].(*big.Int).String() != "1500000000000000000" {
+		t.Fatalf("❌ 整数转换错误: %v", v)
+	}
+	// 前导 0 按十进制解析，不是八进制
+	v = pack("ints", "010", "0100", "08")
+	if v[0].(uint8) != 10 || v[1].(*big.Int).Int64() != 100 || v[2].(*big.Int).Int64() != 8 {
+		t.Fatalf("❌ 前导 0 应按十进制解析: %v", v)
+	}
+	v = pack("fixed", "mykey", "0xdeadbeef", "true")
+	key := v[0].([32]byte)
+	if string(key[:5]) != "mykey" || key[5] != 0 || Format(v[1]) != "0xdeadbeef" || v[2] != true {
+		t.Fatalf("❌ 字节转换错误: %v", v)
+	}
+	v = pack("lists", `["`+addr+`"]`, []interface{}{1, "2"})
+	if Format(v[0]) != `["`+addr+`"]` || v[1].([2]uint16) != [2]uint16{1, 2} {
+		t.Fatalf("❌ 数组转换错误: %v", v)
+	}
+	v = pack("order", `{"maker":"`+addr+`","amount":"1e3","tags":["a","b"]}`)
+	if got := Format(v[0]); got != `{"amount":"1000","maker":"`+addr+`","tags":["a","b"]}` {
+		t.Fatalf("❌ tuple 转换错误: %s", got)
+	}
+	v = pack("order", []interface{}{addr, 7, []string{}})
+	if got := Format(v[0]); !strings.Contains(got, `"amount":"7"`) {
+		t.Fatalf("❌ 按位置转换 tuple 错误: %s", got)
+	}
+	// 重载方法按参数个数或签名选择
+	if m, _, err := c.Pack("set", "1"); err != nil || m.Sig != "set(uint256)" {
+		t.Fatalf("❌ 重载方法选择错误: %v %v", m.Sig, err)
+	}
+	if m, _, err := c.Pack("set(bytes32,uint256)", "k", "1"); err != nil || m.Sig != "set(bytes32,uint256)" {
+		t.Fatalf("❌ 按签名选择错误: %v %v", m.Sig, err)
+	}
+
+	for _, tc := range []struct {
+		method string
+		args   []interface{}
+		want   string
+	}{
+		{"ints", []interface{}{"256", "0", "0"}, "超出"},
+		{"ints", []interface{}{"1", "0", "-1"}, "超出"},
+		{"ints", []interface{}{"1", "0", "1.5"}, "不是整数"},
+		{"ints", []interface{}{"1", "0", "0b11"}, "无效的整数"},
+		{"ints", []interface{}{"1", "--0x1", "0"}, "无效的十六进制整数"},
+		{"fixed", []interface{}{"0x1234", "0x", "true"}, "需要 32 字节"},
+		{"fixed", []interface{}{strings.Repeat("k", 33), "0x", "true"}, "最多 32 字节"},
+		{"lists", []interface{}{`["0x12"]`, "[1,2]"}, "无效的地址"},
+		{"lists", []interface{}{"[]", "[1]"}, "需要 2 个元素"},
+		{"order", []interface{}{`{"maker":"` + addr + `","amount":1}`}, "需要 3 个字段"},
+		{"ints", []interface{}{"1"}, "需要 3 个参数"},
+		{"nope", nil, ErrNoMethod.Error()},
+	} {
+		if _, _, err := c.Pack(tc.method, tc.args...); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("❌ %s%v 应报错 %q: %v", tc.method, tc.args, tc.want, err)
+		}
+	}
+	t.Log("✅ 参数转换正确")
+}
+
+// 测试在模拟链上调用、发送交易、查询和订阅事件
+func TestContract(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t)
+	addr, _ := chain.DeployStore("v1.0.0")
+	parsed, err := ParseABI([]byte(store.StoreMetaData.ABI))
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	c := New(addr, parsed, chain.Client)
+
+	out, err := c.Call(nil, "version")
+	if err != nil || out[0] != "v1.0.0" {
+		t.Fatalf("❌ 调用 version 失败: %v %v", out, err)
+	}
+	if _, err := c.Transact(chain.Auth(0), "version"); err == nil {
+		t.Fatal("❌ 只读方法发送交易应报错")
+	}
+
+	events := make(chan *Event, 1)
+	sub, err := c.WatchEvents(ctx, "ItemSet", events)
+	if err != nil {
+		t.Fatalf("❌ 订阅失败: %v", err)
+	}
+	defer sub.Unsubscribe()
+
+	tx, err := c.Transact(chain.Auth(0), "setItem", "name", "alice")
+	if err != nil {
+		t.Fatalf("❌ 发送交易失败: %v", err)
+	}
+	receipt := chain.WaitMined(tx)
+
+	out, err = c.Call(&bind.CallOpts{}, "items", "name")
+	if err != nil {
+		t.Fatalf("❌ 读取 items 失败: %v", err)
+	}
+	if value := out[0].([32]byte); string(value[:5]) != "alice" {
+		t.Fatalf("❌ items 的值不符: %s", Format(out[0]))
+	}
+	select {
+	case ev := <-events:
+		if ev.Name != "ItemSet" || ev.Log.TxHash != tx.Hash() {
+			t.Fatalf("❌ 订阅到的事件不符: %+v", ev)
+		}
+	case err := <-sub.Err():
+		t.Fatalf("❌ 订阅出错: %v", err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("❌ 没有收到订阅事件")
+	}
+
+	block := receipt.BlockNumber.Uint64()
+	found, err := c.FilterEvents(ctx, "ItemSet", 0, &block)
+	if err != nil || len(found) != 1 {
+		t.Fatalf("❌ 查询事件失败: %v %v", found, err)
+	}
+	if key := found[0].Args["key"].([32]byte); string(key[:4]) != "name" {
+		t.Fatalf("❌ 事件参数不符: %v", found[0].Args)
+	}
+
+	// indexed 参数过滤
+	tokenAddr, _ := chain.DeployToken("Test", "TST", big.NewInt(1000))
+	tokenABI, err := abi.JSON(strings.NewReader(token.ReferenceERC20ABI))
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	erc20 := New(tokenAddr, &tokenABI, chain.Client)
+	to := chain.Accounts[1].Address.Hex()
+	chain.WaitMined(mustTransact(t, erc20, chain.Auth(0), "transfer", to, "10"))
+	chain.WaitMined(mustTransact(t, erc20, chain.Auth(0), "transfer", chain.Accounts[2].Address.Hex(), "20"))
+	transfers, err := erc20.FilterEvents(ctx, "Transfer", 0, nil, nil, to)
+	if err != nil || len(transfers) != 1 || transfers[0].Args["value"].(*big.Int).Int64() != 10 {
+		t.Fatalf("❌ 按 indexed 参数过滤失败: %v %v", transfers, err)
+	}
+	if _, err := erc20.FilterEvents(ctx, "Nope", 0, nil); !errors.Is(err, ErrNoMethod) {
+		t.Fatalf("❌ 不存在的事件应返回 ErrNoMethod: %v", err)
+	}
+	t.Logf("✅ %s: %d 个 ItemSet 事件, %d 个 Transfer 事件", addr.Hex(), len(found), len(transfers))
+}
+
+func mustTransact(t *testing.T, c *Contract, opts *bind.TransactOpts, method string, args ...interface{}) *types.Transaction {
+	t.Helper()
+	tx, err := c.Transact(opts, method, args...)
+	if err != nil {
+		t.Fatalf("❌ %s 失败: %v", method, err)
+	}
+	return tx
+}
@@ -0,0 +1,12 @@
+package token
+
+import _ "embed"
+
+// ReferenceERC20.sol 的编译产物，离线测试用它在模拟链上部署一个接口与 IWS Token 一致的代币
+var (
+	//go:embed ReferenceERC20_sol_ReferenceERC20.abi
+	ReferenceERC20ABI string
+
+	//go:embed ReferenceERC20_sol_ReferenceERC20.bin
+	ReferenceERC20Bin string
+)
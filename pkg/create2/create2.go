@@ -0,0 +1,161 @@
+// Package create2 通过确定性部署工厂（CREATE2）部署合约：地址只取决于工厂地址、salt 和初始化代码，
+// 可以离线预测，在任意链上相同；已部署时不重复发送交易，并核对链上运行时代码
+package create2
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/txutil"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// FactoryAddress 是 Arachnid 确定性部署代理的地址，已部署在以太坊主网、Sepolia、BSC 等绝大多数链上。
+// 调用数据为 salt(32 字节) ++ 初始化代码，返回新合约地址（20 字节）
+var FactoryAddress = common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+
+// FactoryCode 是确定性部署代理的运行时代码，可写入模拟链创世块或通过 devnode.SetCode 注入本地节点
+var FactoryCode = common.FromHex("0x7fffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffe03601600081602082378035828234f58015156039578182fd5b8082525050506014600cf3")
+
+var (
+	// ErrNoFactory 表示链上没有部署工厂
+	ErrNoFactory = errors.New("链上没有确定性部署工厂")
+	// ErrCodeMismatch 表示链上运行时代码与初始化代码生成的代码不一致
+	ErrCodeMismatch = errors.New("运行时代码不一致")
+)
+
+// Salt 把字符串哈希为 32 字节 salt，便于用可读的名字（如 "IWS Store v1.0.0"）区分部署
+func Salt(s string) [32]byte {
+	return crypto.Keccak256Hash([]byte(s))
+}
+
+// InitCode 拼接初始化代码：合约字节码 ++ ABI 编码的构造函数参数
+func InitCode(contractABI *abi.ABI, bytecode []byte, args ...interface{}) ([]byte, error) {
+	packed, err := contractABI.Pack("", args...)
+	if err != nil {
+		return nil, fmt.Errorf("编码构造函数参数失败: %w", err)
+	}
+	code := make([]byte, 0, len(bytecode)+len(packed))
+	return append(append(code, bytecode...), packed...), nil
+}
+
+// Predict 计算 CREATE2 地址：keccak256(0xff ++ factory ++ salt ++ keccak256(initCode))[12:]
+func Predict(factory common.Address, salt [32]byte, initCode []byte) common.Address {
+	return crypto.CreateAddress2(factory, salt, crypto.Keccak256(initCode))
+}
+
+// Backend 是部署需要的节点接口，*ethclient.Client 满足
+type Backend interface {
+	bind.ContractBackend
+	ethereum.TransactionReader
+}
+
+// Deployment 是一次部署（或已存在的部署）的结果
+type Deployment struct {
+	Address  common.Address
+	Salt     [32]byte
+	Existing bool               // 调用前已部署，没有发送交易
+	Tx       *types.Transaction // Existing 为 true 时为 nil
+	Receipt  *types.Receipt     // Existing 为 true 时为 nil
+	CodeHash common.Hash        // 链上运行时代码的哈希
+}
+
+// Deployer 通过工厂部署合约
+type Deployer struct {
+	backend Backend
+
+	Factory      common.Address // 默认 FactoryAddress
+	PollInterval time.Duration  // 查询收据的间隔，默认 txutil.DefaultPollInterval
+}
+
+// New 创建部署器
+func New(backend Backend) *Deployer {
+	return &Deployer{backend: backend, Factory: FactoryAddress}
+}
+
+// Address 预测 salt 和初始化代码对应的合约地址
+func (d *Deployer) Address(salt [32]byte, initCode []byte) common.Address {
+	return Predict(d.Factory, salt, initCode)
+}
+
+// Deploy 部署合约；地址上已有代码时不发送交易，只核对代码。
+// 新部署的交易失败时返回 *chainerr.RevertError
+func (d *Deployer) Deploy(ctx context.Context, opts *bind.TransactOpts, salt [32]byte, initCode []byte) (*Deployment, error) {
+	addr := d.Address(salt, initCode)
+	code, err := d.backend.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s 代码失败: %w", addr.Hex(), chainerr.Wrap(err))
+	}
+	if len(code) > 0 {
+		if err := d.compare(ctx, addr, code, initCode); err != nil {
+			return nil, err
+		}
+		return &Deployment{Address: addr, Salt: salt, Existing: true, CodeHash: crypto.Keccak256Hash(code)}, nil
+	}
+
+	factoryCode, err := d.backend.CodeAt(ctx, d.Factory, nil)
+	if err != nil {
+		return nil, fmt.Errorf("查询工厂代码失败: %w", chainerr.Wrap(err))
+	}
+	if len(factoryCode) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoFactory, d.Factory.Hex())
+	}
+
+	txOpts := *opts
+	if txOpts.Context == nil {
+		txOpts.Context = ctx
+	}
+	factory := bind.NewBoundContract(d.Factory, abi.ABI{}, d.backend, d.backend, d.backend)
+	tx, err := factory.RawTransact(&txOpts, append(salt[:], initCode...))
+	if err != nil {
+		return nil, fmt.Errorf("发送部署交易失败: %w", chainerr.Wrap(err))
+	}
+	receipt, err := txutil.WaitSuccess(ctx, d.backend, tx, d.PollInterval)
+	if err != nil {
+		return nil, fmt.Errorf("部署交易 %s 失败: %w", tx.Hash().Hex(), err)
+	}
+
+	code, err = d.backend.CodeAt(ctx, addr, receipt.BlockNumber)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s 代码失败: %w", addr.Hex(), chainerr.Wrap(err))
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("部署交易 %s 成功但 %s 上没有代码（构造函数 revert 或地址预测错误）", tx.Hash().Hex(), addr.Hex())
+	}
+	if err := d.compare(ctx, addr, code, initCode); err != nil {
+		return nil, err
+	}
+	return &Deployment{Address: addr, Salt: salt, Tx: tx, Receipt: receipt, CodeHash: crypto.Keccak256Hash(code)}, nil
+}
+
+// Verify 核对地址上的运行时代码与初始化代码在工厂中执行生成的代码一致
+func (d *Deployer) Verify(ctx context.Context, addr common.Address, initCode []byte) error {
+	code, err := d.backend.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return fmt.Errorf("查询 %s 代码失败: %w", addr.Hex(), chainerr.Wrap(err))
+	}
+	return d.compare(ctx, addr, code, initCode)
+}
+
+// compare 用不带 to 的 eth_call 执行初始化代码得到期望的运行时代码。
+// 构造函数写入 address(this) 之类与部署地址相关的 immutable 时，模拟结果会不同，这类合约需自行比对
+func (d *Deployer) compare(ctx context.Context, addr common.Address, code, initCode []byte) error {
+	want, err := d.backend.CallContract(ctx, ethereum.CallMsg{From: d.Factory, Data: initCode}, nil)
+	if err != nil {
+		return fmt.Errorf("模拟执行初始化代码失败: %w", chainerr.Wrap(err))
+	}
+	if !bytes.Equal(code, want) {
+		return fmt.Errorf("%w: %s 上的代码哈希 %s，期望 %s", ErrCodeMismatch, addr.Hex(),
+			crypto.Keccak256Hash(code).Hex(), crypto.Keccak256Hash(want).Hex())
+	}
+	return nil
+}
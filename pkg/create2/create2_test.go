@@ -0,0 +1,96 @@
+package create2
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/token"
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// EIP-1014 中的示例
+func TestPredict(t *testing.T) {
+	cases := []struct {
+		factory  string
+		salt     string
+		initCode string
+		want     string
+	}{
+		{"0x0000000000000000000000000000000000000000", "0x00", "0x00", "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x00", "0x00", "0xB928f69Bb1D91Cd65274e3c79d8986362984fDA3"},
+		{"0x00000000000000000000000000000000deadbeef", "0xcafebabe", "0xdeadbeef", "0x60f3f640a8508fC6a86d45DF051962668E1e8AC7"},
+	}
+	for _, c := range cases {
+		got := Predict(common.HexToAddress(c.factory), common.BytesToHash(common.FromHex(c.salt)), common.FromHex(c.initCode))
+		if got != common.HexToAddress(c.want) {
+			t.Fatalf("❌ 预测地址 %s，期望 %s", got.Hex(), c.want)
+		}
+	}
+}
+
+// 测试通过工厂部署 Store：地址与离线预测一致，重复部署不发送交易，代码被替换时报告不一致
+func TestDeploy(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t, simchain.WithAlloc(FactoryAddress, types.Account{Code: FactoryCode, Balance: big.NewInt(0)}))
+	chain.AutoMine(10 * time.Millisecond)
+
+	storeABI, _ := abi.JSON(strings.NewReader(store.StoreMetaData.ABI))
+	initCode, err := InitCode(&storeABI, common.FromHex(store.StoreMetaData.Bin), "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	salt := Salt("IWS Store v1.0.0")
+	predicted := Predict(FactoryAddress, salt, initCode)
+
+	d := New(chain.Client)
+	d.PollInterval = 5 * time.Millisecond
+	dep, err := d.Deploy(ctx, chain.Auth(0), salt, initCode)
+	if err != nil {
+		t.Fatalf("❌ 部署失败: %v", err)
+	}
+	if dep.Address != predicted || dep.Existing || dep.Receipt == nil {
+		t.Fatalf("❌ 部署结果不符: %+v", dep)
+	}
+	instance, _ := store.NewStore(dep.Address, chain.Client)
+	if v, err := instance.Version(&bind.CallOpts{}); err != nil || v != "v1.0.0" {
+		t.Fatalf("❌ 构造函数参数未生效: %q %v", v, err)
+	}
+
+	// 换一个部署账户再次部署：地址不变，不发送交易
+	again, err := d.Deploy(ctx, chain.Auth(1), salt, initCode)
+	if err != nil || !again.Existing || again.Address != predicted || again.Tx != nil {
+		t.Fatalf("❌ 重复部署应直接返回已有合约: %+v %v", again, err)
+	}
+
+	// 不同构造函数参数得到不同地址
+	other, _ := InitCode(&storeABI, common.FromHex(store.StoreMetaData.Bin), "v2.0.0")
+	if d.Address(salt, other) == predicted {
+		t.Fatal("❌ 不同初始化代码应得到不同地址")
+	}
+
+	// 地址上的代码与初始化代码不一致（version 保存在存储中，运行时代码只取决于合约本身，这里换成 ERC20）
+	tokenABI, _ := abi.JSON(strings.NewReader(token.ReferenceERC20ABI))
+	tokenInit, _ := InitCode(&tokenABI, common.FromHex(token.ReferenceERC20Bin), "IWS Token", "IWS", big.NewInt(1))
+	if err := d.Verify(ctx, predicted, other); err != nil {
+		t.Fatalf("❌ 构造参数只影响存储，代码应一致: %v", err)
+	}
+	if err := d.Verify(ctx, predicted, tokenInit); !errors.Is(err, ErrCodeMismatch) {
+		t.Fatalf("❌ 应报告代码不一致: %v", err)
+	}
+
+	// 没有工厂的链
+	plain := simchain.New(t)
+	if _, err := New(plain.Client).Deploy(ctx, plain.Auth(0), salt, initCode); !errors.Is(err, ErrNoFactory) {
+		t.Fatalf("❌ 应报告缺少工厂: %v", err)
+	}
+	t.Logf("✅ Store 部署在 %s", dep.Address.Hex())
+}
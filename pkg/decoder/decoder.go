@@ -0,0 +1,253 @@
+package decoder
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// 内部调用最大嵌套深度，防止恶意构造的 calldata 无限递归
+const maxDepth = 8
+
+// Value 是解码后的一个参数（或 tuple 字段、数组元素）
+type Value struct {
+	Name   string   `json:"name,omitempty"`
+	Type   string   `json:"type"`
+	Value  string   `json:"value,omitempty"`  // 叶子节点的格式化值
+	Label  string   `json:"label,omitempty"`  // 地址标签
+	Text   string   `json:"text,omitempty"`   // bytes32 等可读文本
+	Fields []*Value `json:"fields,omitempty"` // tuple 字段或数组元素
+	Inner  bool     `json:"inner,omitempty"`  // 该参数已展开为内部调用
+}
+
+// Call 是解码后的一次合约调用，Calls 为 multicall 类调用展开的内部调用
+type Call struct {
+	To        *common.Address `json:"to,omitempty"`
+	Label     string          `json:"label,omitempty"`
+	Selector  string          `json:"selector,omitempty"`
+	Contract  string          `json:"contract,omitempty"`
+	Method    string          `json:"method,omitempty"`
+	Signature string          `json:"signature,omitempty"`
+	Args      []*Value        `json:"args,omitempty"`
+	Calls     []*Call         `json:"calls,omitempty"`
+	Note      string          `json:"note,omitempty"` // 内部调用附加信息，例如 allowFailure
+	Error     string          `json:"error,omitempty"`
+	DataLen   int             `json:"dataLen"`
+}
+
+// Decoder 根据注册表解码交易 calldata
+type Decoder struct {
+	reg *Registry
+}
+
+// New 创建解码器，reg 为空时使用 DefaultRegistry
+func New(reg *Registry) *Decoder {
+	if reg == nil {
+		reg = DefaultRegistry()
+	}
+	return &Decoder{reg: reg}
+}
+
+// Registry 返回解码器使用的注册表
+func (d *Decoder) Registry() *Registry {
+	return d.reg
+}
+
+// Decode 解码发往 to 的 calldata，to 为 nil 表示合约创建
+func (d *Decoder) Decode(to *common.Address, data []byte) *Call {
+	return d.decode(to, data, 0)
+}
+
+// DecodeData 在不知道调用目标时解码 calldata
+func (d *Decoder) DecodeData(data []byte) *Call {
+	call := d.decode(&common.Address{}, data, 0)
+	call.To, call.Label = nil, ""
+	return call
+}
+
+// Signature 返回选择器对应的方法签名（有多个候选时取第一个），未知时返回空字符串
+func (d *Decoder) Signature(to *common.Address, selector [4]byte) string {
+	if candidates := d.reg.lookup(to, selector); len(candidates) > 0 {
+		return candidates[0].method.Sig
+	}
+	return ""
+}
+
+func (d *Decoder) decode(to *common.Address, data []byte, depth int) *Call {
+	call := &Call{To: to, DataLen: len(data)}
+	if to != nil {
+		call.Label = d.reg.Label(*to)
+	}
+
+	switch {
+	case to == nil:
+		call.Method = "合约创建"
+		return call
+	case len(data) == 0:
+		call.Method = "ETH 转账"
+		return call
+	case len(data) < 4:
+		call.Error = fmt.Sprintf("calldata 过短: %d 字节", len(data))
+		return call
+	}
+
+	var selector [4]byte
+	copy(selector[:], data[:4])
+	call.Selector = hexutil.Encode(selector[:])
+
+	candidates := d.reg.lookup(to, selector)
+	if len(candidates) == 0 {
+		call.Error = "未知函数选择器"
+		return call
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		values, err := candidate.method.Inputs.Unpack(data[4:])
+		if err != nil {
+			lastErr = err
+			continue
+		}
+
+		call.Contract = candidate.contract
+		call.Method = candidate.method.RawName
+		call.Signature = candidate.method.Sig
+		for i, input := range candidate.method.Inputs {
+			call.Args = append(call.Args, d.toValue(input.Name, input.Type, values[i]))
+		}
+		if depth < maxDepth {
+			d.expandInner(call, candidate.method, values, depth)
+		}
+		return call
+	}
+
+	call.Error = fmt.Sprintf("参数解码失败: %v", lastErr)
+	return call
+}
+
+// expandInner 识别 multicall 风格的参数并递归解码内部调用：
+//   - (address target, ..., bytes callData)[]：Multicall3 aggregate 系列
+//   - bytes[]：Uniswap 风格 multicall，内部调用目标为当前合约
+func (d *Decoder) expandInner(call *Call, method abi.Method, values []interface{}, depth int) {
+	for i, input := range method.Inputs {
+		typ := input.Type
+		if typ.T != abi.SliceTy && typ.T != abi.ArrayTy {
+			continue
+		}
+		elem := typ.Elem
+		items := reflect.ValueOf(values[i])
+
+		switch {
+		case elem.T == abi.BytesTy && strings.HasPrefix(method.RawName, "multicall"):
+			for j := 0; j < items.Len(); j++ {
+				inner := items.Index(j).Interface().([]byte)
+				call.Calls = append(call.Calls, d.decode(call.To, inner, depth+1))
+			}
+			call.Args[i].Inner = true
+
+		case elem.T == abi.TupleTy:
+			targetIdx, dataIdx := -1, -1
+			for k, field := range elem.TupleElems {
+				switch field.T {
+				case abi.AddressTy:
+					if targetIdx < 0 {
+						targetIdx = k
+					}
+				case abi.BytesTy:
+					dataIdx = k
+				}
+			}
+			if targetIdx < 0 || dataIdx < 0 {
+				continue
+			}
+			for j := 0; j < items.Len(); j++ {
+				item := reflect.Indirect(items.Index(j))
+				target := item.Field(targetIdx).Interface().(common.Address)
+				inner := d.decode(&target, item.Field(dataIdx).Interface().([]byte), depth+1)
+
+				// 其余字段（allowFailure、value 等）作为附加信息
+				var notes []string
+				for k, field := range elem.TupleElems {
+					if k == targetIdx || k == dataIdx {
+						continue
+					}
+					notes = append(notes, fmt.Sprintf("%s=%s", elem.TupleRawNames[k], formatLeaf(*field, item.Field(k).Interface())))
+				}
+				inner.Note = strings.Join(notes, ", ")
+				call.Calls = append(call.Calls, inner)
+			}
+			call.Args[i].Inner = true
+		}
+	}
+}
+
+// toValue 把 go-ethereum 解码得到的 Go 值转换为可渲染的 Value 树
+func (d *Decoder) toValue(name string, typ abi.Type, v interface{}) *Value {
+	val := &Value{Name: name, Type: typ.String()}
+	rv := reflect.ValueOf(v)
+
+	switch typ.T {
+	case abi.TupleTy:
+		rv = reflect.Indirect(rv)
+		for i, elem := range typ.TupleElems {
+			val.Fields = append(val.Fields, d.toValue(typ.TupleRawNames[i], *elem, rv.Field(i).Interface()))
+		}
+	case abi.SliceTy, abi.ArrayTy:
+		val.Fields = []*Value{}
+		for i := 0; i < rv.Len(); i++ {
+			val.Fields = append(val.Fields, d.toValue(fmt.Sprintf("[%d]", i), *typ.Elem, rv.Index(i).Interface()))
+		}
+	case abi.AddressTy:
+		addr := v.(common.Address)
+		val.Value = addr.Hex()
+		val.Label = d.reg.Label(addr)
+	case abi.FixedBytesTy:
+		raw := make([]byte, rv.Len())
+		reflect.Copy(reflect.ValueOf(raw), rv)
+		val.Value = hexutil.Encode(raw)
+		val.Text = printable(raw)
+	default:
+		val.Value = formatLeaf(typ, v)
+	}
+	return val
+}
+
+// formatLeaf 格式化基础类型的值
+func formatLeaf(typ abi.Type, v interface{}) string {
+	switch typ.T {
+	case abi.AddressTy:
+		return v.(common.Address).Hex()
+	case abi.BytesTy:
+		return hexutil.Encode(v.([]byte))
+	case abi.StringTy:
+		return fmt.Sprintf("%q", v)
+	case abi.IntTy, abi.UintTy:
+		if b, ok := v.(*big.Int); ok {
+			return b.String()
+		}
+		return fmt.Sprint(v)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// printable 在字节去掉末尾补零后全部为可打印字符时返回对应文本
+// Store 合约的 key/value 就是用字符串右补零得到的 bytes32
+func printable(raw []byte) string {
+	trimmed := strings.TrimRight(string(raw), "\x00")
+	if trimmed == "" {
+		return ""
+	}
+	for _, r := range trimmed {
+		if r == unicode.ReplacementChar || !unicode.IsPrint(r) {
+			return ""
+		}
+	}
+	return trimmed
+}
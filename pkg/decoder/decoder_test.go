@@ -0,0 +1,111 @@
+package decoder
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var (
+	iwsToken  = common.HexToAddress("0xE5aFC41736bBE96cCB912Cb2d2e6BB503979b657")
+	storeAddr = common.HexToAddress("0x48Bd8C28155a382d872e4758c11b967303fEDD90")
+	multicall = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+	receiver  = common.HexToAddress("0x2281cB9267ABAF264c0A4c0dD2e414b4d68cE634")
+)
+
+// 测试解码普通 ERC20 transfer
+func TestDecodeTransfer(t *testing.T) {
+	reg := DefaultRegistry()
+	erc20, _ := reg.ABI("ERC20")
+
+	data, err := erc20.Pack("transfer", receiver, big.NewInt(1e18))
+	if err != nil {
+		t.Fatalf("❌ 编码 transfer 失败: %v", err)
+	}
+
+	call := New(reg).Decode(&iwsToken, data)
+	if call.Error != "" {
+		t.Fatalf("❌ 解码失败: %s", call.Error)
+	}
+	if call.Signature != "transfer(address,uint256)" || call.Label != "IWS Token" {
+		t.Fatalf("❌ 解码结果不符: %s / %s", call.Signature, call.Label)
+	}
+	if got := call.Args[1].Value; got != "1000000000000000000" {
+		t.Fatalf("❌ 金额解码错误: %s", got)
+	}
+	t.Logf("\n%s", call)
+}
+
+// 测试 Multicall3 aggregate3 中嵌套 Store.setItem 和 ERC20 调用
+func TestDecodeAggregate3(t *testing.T) {
+	reg := DefaultRegistry()
+	erc20, _ := reg.ABI("ERC20")
+	storeABI, _ := reg.ABI("Store")
+	mc, _ := reg.ABI("Multicall")
+
+	var key, value [32]byte
+	copy(key[:], "mykey")
+	copy(value[:], "myvalue")
+	setItem, _ := storeABI.Pack("setItem", key, value)
+	balanceOf, _ := erc20.Pack("balanceOf", receiver)
+
+	type call3 struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	data, err := mc.Pack("aggregate3", []call3{
+		{Target: storeAddr, AllowFailure: false, CallData: setItem},
+		{Target: iwsToken, AllowFailure: true, CallData: balanceOf},
+		{Target: receiver, AllowFailure: true, CallData: []byte{0xde, 0xad, 0xbe, 0xef}},
+	})
+	if err != nil {
+		t.Fatalf("❌ 编码 aggregate3 失败: %v", err)
+	}
+
+	call := New(reg).Decode(&multicall, data)
+	if len(call.Calls) != 3 {
+		t.Fatalf("❌ 期望 3 个内部调用，实际 %d", len(call.Calls))
+	}
+	if call.Calls[0].Method != "setItem" || call.Calls[0].Args[0].Text != "mykey" {
+		t.Fatalf("❌ 内部调用 setItem 解码错误: %+v", call.Calls[0])
+	}
+	if call.Calls[1].Note != "allowFailure=true" {
+		t.Fatalf("❌ allowFailure 未保留: %q", call.Calls[1].Note)
+	}
+	if call.Calls[2].Error == "" {
+		t.Fatal("❌ 未知选择器应返回错误信息")
+	}
+
+	out := call.String()
+	for _, want := range []string{"aggregate3((address,bool,bytes)[])", "↳ setItem(bytes32,bytes32)", `"myvalue"`, "IWS Token (0xE5aF"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("❌ 渲染结果缺少 %q:\n%s", want, out)
+		}
+	}
+	t.Logf("\n%s", out)
+}
+
+// 测试 Uniswap 风格 multicall(bytes[]) 递归解码
+func TestDecodeNestedMulticall(t *testing.T) {
+	reg := DefaultRegistry()
+	erc20, _ := reg.ABI("ERC20")
+	mc, _ := reg.ABI("Multicall")
+
+	approve, _ := erc20.Pack("approve", receiver, big.NewInt(5))
+	inner, _ := mc.Pack("multicall", [][]byte{approve})
+	outer, err := mc.Pack("multicall0", big.NewInt(1700000000), [][]byte{inner, approve})
+	if err != nil {
+		t.Fatalf("❌ 编码 multicall 失败: %v", err)
+	}
+
+	call := New(reg).Decode(&iwsToken, outer)
+	if call.Signature != "multicall(uint256,bytes[])" || len(call.Calls) != 2 {
+		t.Fatalf("❌ 外层 multicall 解码错误: %s, %d 个内部调用", call.Signature, len(call.Calls))
+	}
+	if got := call.Calls[0].Calls; len(got) != 1 || got[0].Method != "approve" {
+		t.Fatalf("❌ 嵌套 multicall 未展开: %+v", got)
+	}
+}
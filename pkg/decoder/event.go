@@ -0,0 +1,122 @@
+package decoder
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrUnknownEvent 表示事件签名不在注册表中
+var ErrUnknownEvent = errors.New("未知事件签名")
+
+// Event 是解码后的事件日志
+type Event struct {
+	Contract  string   `json:"contract"`
+	Name      string   `json:"name"`
+	Signature string   `json:"signature"`
+	Args      []*Value `json:"args"`
+}
+
+// Arg 按名称返回事件参数，不存在时返回 nil
+func (e *Event) Arg(name string) *Value {
+	for _, arg := range e.Args {
+		if arg.Name == name {
+			return arg
+		}
+	}
+	return nil
+}
+
+// Plain 把事件参数转换为 名称 -> 值 的简单结构，便于序列化为 JSON
+func (e *Event) Plain() map[string]interface{} {
+	out := make(map[string]interface{}, len(e.Args))
+	for i, arg := range e.Args {
+		name := arg.Name
+		if name == "" {
+			name = fmt.Sprintf("arg%d", i)
+		}
+		out[name] = arg.Plain()
+	}
+	return out
+}
+
+// Plain 把 Value 树转换为字符串、切片或 map
+func (v *Value) Plain() interface{} {
+	if v.Fields == nil {
+		return v.Value
+	}
+	if v.Type != "" && v.Type[len(v.Type)-1] == ']' {
+		items := make([]interface{}, len(v.Fields))
+		for i, f := range v.Fields {
+			items[i] = f.Plain()
+		}
+		return items
+	}
+	fields := make(map[string]interface{}, len(v.Fields))
+	for _, f := range v.Fields {
+		fields[f.Name] = f.Plain()
+	}
+	return fields
+}
+
+// DecodeLog 根据 topic[0] 匹配注册表中的事件并解码 indexed 与非 indexed 参数
+func (d *Decoder) DecodeLog(log *types.Log) (*Event, error) {
+	if len(log.Topics) == 0 {
+		return nil, fmt.Errorf("%w: 匿名事件", ErrUnknownEvent)
+	}
+	candidates := d.reg.lookupEvent(log.Address, log.Topics[0])
+	if len(candidates) == 0 {
+		return nil, ErrUnknownEvent
+	}
+
+	var lastErr error
+	for _, candidate := range candidates {
+		event, err := d.decodeEvent(candidate, log)
+		if err == nil {
+			return event, nil
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("解码事件失败: %w", lastErr)
+}
+
+func (d *Decoder) decodeEvent(candidate namedEvent, log *types.Log) (*Event, error) {
+	ev := candidate.event
+
+	// 非 indexed 参数在 Data 中
+	values := make(map[string]interface{})
+	if err := ev.Inputs.NonIndexed().UnpackIntoMap(values, log.Data); err != nil {
+		return nil, err
+	}
+	// indexed 参数在 Topics[1:] 中
+	var indexed abi.Arguments
+	for _, input := range ev.Inputs {
+		if input.Indexed {
+			indexed = append(indexed, input)
+		}
+	}
+	if len(indexed) != len(log.Topics)-1 {
+		return nil, fmt.Errorf("indexed 参数数量不符: 期望 %d, 实际 %d", len(indexed), len(log.Topics)-1)
+	}
+	if err := abi.ParseTopicsIntoMap(values, indexed, log.Topics[1:]); err != nil {
+		return nil, err
+	}
+
+	event := &Event{Contract: candidate.contract, Name: ev.RawName, Signature: ev.Sig}
+	for _, input := range ev.Inputs {
+		v, ok := values[input.Name]
+		if !ok {
+			return nil, fmt.Errorf("缺少参数 %s", input.Name)
+		}
+		// 动态类型的 indexed 参数只能拿到哈希
+		if hash, isHash := v.(common.Hash); isHash && input.Indexed && input.Type.T != abi.FixedBytesTy {
+			event.Args = append(event.Args, &Value{Name: input.Name, Type: input.Type.String(), Value: hash.Hex()})
+			continue
+		}
+		event.Args = append(event.Args, d.toValue(input.Name, input.Type, v))
+	}
+	return event, nil
+}
@@ -0,0 +1,233 @@
+package decoder
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/token"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ==================== 编译时嵌入 ABI 文件 ====================
+
+//go:embed abis/Multicall.abi
+var multicallABIJSON string
+
+// 常用地址（标签 + 对应的 ABI 名称）
+var knownAddresses = []struct {
+	address string
+	label   string
+	abiName string
+}{
+	{"0xE5aFC41736bBE96cCB912Cb2d2e6BB503979b657", "IWS Token", "ERC20"},
+	{"0x48Bd8C28155a382d872e4758c11b967303fEDD90", "Store", "Store"},
+	{"0xcA11bde05977b3631167028862bE2a173976CA11", "Multicall3", "Multicall"},
+	{"0xae13d989daC2f0dEbFf460aC112a837C89BAa7cd", "WBNB", "ERC20"},
+	{"0x9Ac64Cc6e4415144C455BD8E4837Fea55603e5c3", "PancakeSwap Router", ""},
+	{"0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266", "Hardhat 账户0", ""},
+	{"0x70997970C51812dc3A010C7d01b50e0d17dc79C8", "Hardhat 账户1", ""},
+}
+
+// Registry 保存已知 ABI、函数选择器索引和地址标签
+type Registry struct {
+	mu      sync.RWMutex
+	abis    map[string]*abi.ABI          // ABI 名称 -> ABI
+	methods map[[4]byte][]namedMethod    // 函数选择器 -> 候选方法
+	events  map[common.Hash][]namedEvent // 事件签名哈希 -> 候选事件
+	bound   map[common.Address]string    // 合约地址 -> ABI 名称
+	labels  map[common.Address]string    // 地址 -> 可读标签
+}
+
+type namedMethod struct {
+	contract string
+	method   abi.Method
+}
+
+type namedEvent struct {
+	contract string
+	event    abi.Event
+}
+
+// NewRegistry 创建空的注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		abis:    make(map[string]*abi.ABI),
+		methods: make(map[[4]byte][]namedMethod),
+		events:  make(map[common.Hash][]namedEvent),
+		bound:   make(map[common.Address]string),
+		labels:  make(map[common.Address]string),
+	}
+}
+
+// DefaultRegistry 创建包含 ERC20、Store、Multicall 以及常用地址标签的注册表
+func DefaultRegistry() *Registry {
+	r := NewRegistry()
+	for name, raw := range map[string]string{
+		"ERC20":     token.TokenMetaData.ABI,
+		"Store":     store.StoreMetaData.ABI,
+		"Multicall": multicallABIJSON,
+	} {
+		if err := r.AddABIJSON(name, raw); err != nil {
+			panic(fmt.Sprintf("内置 ABI %s 解析失败: %v", name, err)) // 内置数据，不应出错
+		}
+	}
+	for _, known := range knownAddresses {
+		addr := common.HexToAddress(known.address)
+		r.SetLabel(addr, known.label)
+		if known.abiName != "" {
+			r.Bind(addr, known.abiName)
+		}
+	}
+	return r
+}
+
+// AddABI 注册一个 ABI，后注册的同名 ABI 会覆盖之前的
+func (r *Registry) AddABI(name string, parsed abi.ABI) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.abis[name] = &parsed
+	for _, method := range parsed.Methods {
+		var selector [4]byte
+		copy(selector[:], method.ID)
+
+		candidates := r.methods[selector][:0:0]
+		for _, existing := range r.methods[selector] {
+			if existing.contract != name {
+				candidates = append(candidates, existing)
+			}
+		}
+		r.methods[selector] = append(candidates, namedMethod{contract: name, method: method})
+	}
+	for _, event := range parsed.Events {
+		candidates := r.events[event.ID][:0:0]
+		for _, existing := range r.events[event.ID] {
+			if existing.contract != name {
+				candidates = append(candidates, existing)
+			}
+		}
+		r.events[event.ID] = append(candidates, namedEvent{contract: name, event: event})
+	}
+}
+
+// AddABIJSON 解析 ABI JSON 并注册
+func (r *Registry) AddABIJSON(name, raw string) error {
+	parsed, err := abi.JSON(strings.NewReader(raw))
+	if err != nil {
+		return fmt.Errorf("解析 ABI %s 失败: %w", name, err)
+	}
+	r.AddABI(name, parsed)
+	return nil
+}
+
+// AddABIFile 从文件加载 ABI，名称默认取文件名（去掉扩展名）
+func (r *Registry) AddABIFile(name, path string) error {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取 ABI 文件失败: %w", err)
+	}
+	if name == "" {
+		name = strings.TrimSuffix(path[strings.LastIndexAny(path, `/\`)+1:], ".abi")
+		name = strings.TrimSuffix(name, ".json")
+	}
+	return r.AddABIJSON(name, string(raw))
+}
+
+// ABI 按名称查找已注册的 ABI
+func (r *Registry) ABI(name string) (*abi.ABI, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	parsed, ok := r.abis[name]
+	return parsed, ok
+}
+
+// Bind 把合约地址和已注册的 ABI 名称关联，解码时优先使用
+func (r *Registry) Bind(addr common.Address, abiName string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bound[addr] = abiName
+}
+
+// BoundABI 返回地址绑定的 ABI 名称和 ABI
+func (r *Registry) BoundABI(addr common.Address) (string, *abi.ABI, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	name, ok := r.bound[addr]
+	if !ok {
+		return "", nil, false
+	}
+	parsed, ok := r.abis[name]
+	return name, parsed, ok
+}
+
+// SetLabel 设置地址的可读标签
+func (r *Registry) SetLabel(addr common.Address, label string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.labels[addr] = label
+}
+
+// Label 返回地址标签，没有则返回空字符串
+func (r *Registry) Label(addr common.Address) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.labels[addr]
+}
+
+// lookup 返回选择器对应的候选方法，绑定到 to 地址的 ABI 排在最前
+func (r *Registry) lookup(to *common.Address, selector [4]byte) []namedMethod {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	candidates := r.methods[selector]
+	if to == nil {
+		return candidates
+	}
+	boundName, ok := r.bound[*to]
+	if !ok {
+		return candidates
+	}
+
+	ordered := make([]namedMethod, 0, len(candidates))
+	for _, c := range candidates {
+		if c.contract == boundName {
+			ordered = append(ordered, c)
+		}
+	}
+	for _, c := range candidates {
+		if c.contract != boundName {
+			ordered = append(ordered, c)
+		}
+	}
+	return ordered
+}
+
+// lookupEvent 返回事件签名对应的候选事件，绑定到 addr 的 ABI 排在最前
+func (r *Registry) lookupEvent(addr common.Address, topic common.Hash) []namedEvent {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	candidates := r.events[topic]
+	boundName, ok := r.bound[addr]
+	if !ok {
+		return candidates
+	}
+
+	ordered := make([]namedEvent, 0, len(candidates))
+	for _, c := range candidates {
+		if c.contract == boundName {
+			ordered = append(ordered, c)
+		}
+	}
+	for _, c := range candidates {
+		if c.contract != boundName {
+			ordered = append(ordered, c)
+		}
+	}
+	return ordered
+}
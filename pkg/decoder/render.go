@@ -0,0 +1,112 @@
+package decoder
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 渲染时 bytes 参数最多显示的十六进制字符数
+const maxHexChars = 138
+
+// Render 以树形结构输出调用及其参数、内部调用
+func (c *Call) Render(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(c.headline())
+	b.WriteByte('\n')
+	c.renderChildren(&b, "")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// String 返回渲染后的调用树
+func (c *Call) String() string {
+	var b strings.Builder
+	_ = c.Render(&b)
+	return b.String()
+}
+
+// headline 生成调用的标题行，例如：transfer(address,uint256) → IWS Token (0xE5aF...)
+func (c *Call) headline() string {
+	var b strings.Builder
+	switch {
+	case c.Signature != "":
+		b.WriteString(c.Signature)
+	case c.Selector != "":
+		b.WriteString("❓ " + c.Selector)
+	default:
+		b.WriteString(c.Method)
+	}
+	if c.To != nil {
+		b.WriteString(" → ")
+		b.WriteString(addressText(c.To.Hex(), c.Label))
+	}
+	if c.Note != "" {
+		b.WriteString(" [" + c.Note + "]")
+	}
+	if c.Error != "" {
+		fmt.Fprintf(&b, " ⚠️  %s (%d 字节)", c.Error, c.DataLen)
+	} else if c.Method == "合约创建" {
+		fmt.Fprintf(&b, " (%d 字节)", c.DataLen)
+	}
+	return b.String()
+}
+
+func (c *Call) renderChildren(b *strings.Builder, prefix string) {
+	total := len(c.Args) + len(c.Calls)
+	idx := 0
+	for _, arg := range c.Args {
+		idx++
+		renderValue(b, prefix, arg, idx == total)
+	}
+	for _, inner := range c.Calls {
+		idx++
+		branch, next := branches(prefix, idx == total)
+		b.WriteString(branch + "↳ " + inner.headline() + "\n")
+		inner.renderChildren(b, next)
+	}
+}
+
+func renderValue(b *strings.Builder, prefix string, v *Value, last bool) {
+	branch, next := branches(prefix, last)
+	label := v.Name
+	if label == "" {
+		label = "(" + v.Type + ")"
+	}
+
+	switch {
+	case v.Inner:
+		fmt.Fprintf(b, "%s%s: %s [%d 个内部调用，见下方]\n", branch, label, v.Type, len(v.Fields))
+	case v.Fields != nil:
+		fmt.Fprintf(b, "%s%s: %s\n", branch, label, v.Type)
+		for i, field := range v.Fields {
+			renderValue(b, next, field, i == len(v.Fields)-1)
+		}
+	case v.Type == "address":
+		fmt.Fprintf(b, "%s%s: %s\n", branch, label, addressText(v.Value, v.Label))
+	default:
+		text := v.Value
+		if len(text) > maxHexChars && strings.HasPrefix(text, "0x") {
+			text = fmt.Sprintf("%s… (%d 字节)", text[:maxHexChars], (len(v.Value)-2)/2)
+		}
+		if v.Text != "" {
+			text += fmt.Sprintf(" (%q)", v.Text)
+		}
+		fmt.Fprintf(b, "%s%s: %s\n", branch, label, text)
+	}
+}
+
+// branches 返回当前节点的分支前缀和子节点使用的缩进前缀
+func branches(prefix string, last bool) (string, string) {
+	if last {
+		return prefix + "└─ ", prefix + "   "
+	}
+	return prefix + "├─ ", prefix + "│  "
+}
+
+func addressText(hex, label string) string {
+	if label == "" {
+		return hex
+	}
+	return fmt.Sprintf("%s (%s)", label, hex)
+}
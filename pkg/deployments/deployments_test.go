@@ -0,0 +1,158 @@
+package deployments
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/token"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/create2"
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 测试内置清单的查找、版本检查和读写
+func TestManifest(t *testing.T) {
+	m := Default()
+	d, err := m.Lookup("sepolia", "IWSToken")
+	if err != nil || d.Address != common.HexToAddress("0xE5aFC41736bBE96cCB912Cb2d2e6BB503979b657") {
+		t.Fatalf("❌ 查找 IWSToken 失败: %+v %v", d, err)
+	}
+	if _, err := d.ParseABI(); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if d, err := m.LookupChain(big.NewInt(11155111), "Store"); err != nil || d.Contract != "Store" {
+		t.Fatalf("❌ 按链 ID 查找 Store 失败: %+v %v", d, err)
+	}
+	if _, err := m.Lookup("sepolia", "Missing"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("❌ 应返回 ErrNotFound: %v", err)
+	}
+	if _, err := m.LookupChain(big.NewInt(97), "Store"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("❌ 未知链应返回 ErrNotFound: %v", err)
+	}
+	if _, err := Parse([]byte(`{"version":99}`)); !errors.Is(err, ErrVersion) {
+		t.Fatalf("❌ 应拒绝未知版本: %v", err)
+	}
+	if err := m.Record("sepolia", 1, "Other", &Deployment{}); err == nil {
+		t.Fatal("❌ 链 ID 不一致时应拒绝记录")
+	}
+
+	path := filepath.Join(t.TempDir(), "deployments.json")
+	if empty, err := Load(path); err != nil || len(empty.Networks) != 0 {
+		t.Fatalf("❌ 文件不存在时应返回空清单: %v", err)
+	}
+	if err := m.Save(path); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if addr, err := loaded.Address("sepolia", "Store"); err != nil || addr != common.HexToAddress("0x48Bd8C28155a382d872e4758c11b967303fEDD90") {
+		t.Fatalf("❌ 读回的清单不一致: %s %v", addr.Hex(), err)
+	}
+	t.Logf("✅ 内置清单包含 %d 个网络", len(m.Networks))
+}
+
+// 测试多步部署：第二步失败后重新运行，已完成的步骤不再发送交易
+func TestRunner(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t, simchain.WithAlloc(create2.FactoryAddress, types.Account{Code: create2.FactoryCode, Balance: big.NewInt(0)}))
+	chain.AutoMine(10 * time.Millisecond)
+	path := filepath.Join(t.TempDir(), "deployments.json")
+
+	salt := create2.Salt("IWS Store test")
+	fail := true
+	steps := []Step{
+		{
+			Name:     "IWSToken",
+			Contract: "ReferenceERC20",
+			ABI:      token.ReferenceERC20ABI,
+			Bytecode: common.FromHex(token.ReferenceERC20Bin),
+			Args: func(map[string]*Deployment) ([]interface{}, error) {
+				return []interface{}{"IWS Token", "IWS", big.NewInt(1000)}, nil
+			},
+		},
+		{
+			Name:     "Store",
+			ABI:      store.StoreMetaData.ABI,
+			Bytecode: common.FromHex(store.StoreMetaData.Bin),
+			Salt:     &salt,
+			Args: func(deployed map[string]*Deployment) ([]interface{}, error) {
+				if fail {
+					return nil, errors.New("模拟失败")
+				}
+				// 引用前一步的部署结果
+				return []interface{}{"v1 " + deployed["IWSToken"].Address.Hex()}, nil
+			},
+		},
+	}
+
+	run := func() (map[string]*Deployment, []string, error) {
+		m, err := Load(path)
+		if err != nil {
+			t.Fatalf("❌ %v", err)
+		}
+		r := NewRunner(chain.Client, m, path, "sim")
+		r.PollInterval = 5 * time.Millisecond
+		var skipped []string
+		r.OnStep = func(name string, _ *Deployment, skip bool) {
+			if skip {
+				skipped = append(skipped, name)
+			}
+		}
+		deployed, err := r.Run(ctx, chain.Auth(0), steps)
+		return deployed, skipped, err
+	}
+
+	if _, _, err := run(); err == nil {
+		t.Fatal("❌ 第二步应失败")
+	}
+	m, _ := Load(path)
+	first, err := m.Lookup("sim", "IWSToken")
+	if err != nil || first.TxHash == nil || first.Block == 0 || first.Deployer == nil || *first.Deployer != chain.Accounts[0].Address {
+		t.Fatalf("❌ 第一步应已写入清单: %+v %v", first, err)
+	}
+	if len(first.Args) != 3 || first.Args[0] != "IWS Token" || first.Args[2] != "1000" {
+		t.Fatalf("❌ 构造参数记录不符: %v", first.Args)
+	}
+
+	fail = false
+	deployed, skipped, err := run()
+	if err != nil {
+		t.Fatalf("❌ 重新运行失败: %v", err)
+	}
+	if len(skipped) != 1 || skipped[0] != "IWSToken" || deployed["IWSToken"].Address != first.Address {
+		t.Fatalf("❌ 已完成的步骤应跳过: %v", skipped)
+	}
+	s := deployed["Store"]
+	if s.Salt == nil || *s.Salt != common.Hash(salt) || s.TxHash == nil {
+		t.Fatalf("❌ Store 应通过 CREATE2 部署: %+v", s)
+	}
+	instance, _ := store.NewStore(s.Address, chain.Client)
+	if v, err := instance.Version(&bind.CallOpts{}); err != nil || v != "v1 "+first.Address.Hex() {
+		t.Fatalf("❌ 构造参数未生效: %q %v", v, err)
+	}
+
+	// 全部完成后再次运行不发送任何交易
+	if _, skipped, err := run(); err != nil || len(skipped) != 2 {
+		t.Fatalf("❌ 应跳过全部步骤: %v %v", skipped, err)
+	}
+
+	// 清单中的网络与链 ID 不一致
+	if err := os.WriteFile(path, []byte(`{"version":1,"networks":{"sim":{"chainId":97,"contracts":{}}}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := run(); !errors.Is(err, chainerr.ErrChainMismatch) {
+		t.Fatalf("❌ 应返回链 ID 不匹配: %v", err)
+	}
+	t.Logf("✅ Store 部署在 %s", s.Address.Hex())
+}
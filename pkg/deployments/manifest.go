@@ -0,0 +1,221 @@
+// Package deployments 按网络记录合约部署（地址、交易、区块、部署者、构造参数、ABI、字节码哈希），
+// 保存为带版本号的 JSON 清单；代码通过名字和网络查找合约，脚本化的多步部署失败后可以从断点继续
+package deployments
+
+import (
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// Version 是当前清单格式版本
+const Version = 1
+
+var (
+	// ErrNotFound 表示清单中没有该网络或合约
+	ErrNotFound = errors.New("清单中没有该部署")
+	// ErrVersion 表示清单格式版本不受支持
+	ErrVersion = errors.New("不支持的清单版本")
+)
+
+// Deployment 是一个已部署的合约
+type Deployment struct {
+	Contract        string          `json:"contract"` // 合约（工件）名，例如 Store、ERC20
+	Address         common.Address  `json:"address"`
+	TxHash          *common.Hash    `json:"txHash,omitempty"`
+	Block           uint64          `json:"block,omitempty"`
+	Deployer        *common.Address `json:"deployer,omitempty"`
+	Args            []string        `json:"args,omitempty"`            // 构造参数的可读形式
+	ConstructorArgs hexutil.Bytes   `json:"constructorArgs,omitempty"` // ABI 编码的构造参数
+	Salt            *common.Hash    `json:"salt,omitempty"`            // 通过 CREATE2 部署时的 salt
+	BytecodeHash    *common.Hash    `json:"bytecodeHash,omitempty"`    // 创建字节码（不含构造参数）的 keccak256
+	ABI             json.RawMessage `json:"abi,omitempty"`
+	DeployedAt      *time.Time      `json:"deployedAt,omitempty"`
+}
+
+// ParseABI 解析记录的 ABI
+func (d *Deployment) ParseABI() (*abi.ABI, error) {
+	if len(d.ABI) == 0 {
+		return nil, fmt.Errorf("%s 没有记录 ABI", d.Contract)
+	}
+	parsed, err := abi.JSON(strings.NewReader(string(d.ABI)))
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 的 ABI 失败: %w", d.Contract, err)
+	}
+	return &parsed, nil
+}
+
+// Network 是一个网络上的所有部署，按名字索引
+type Network struct {
+	ChainID   uint64                 `json:"chainId"`
+	Contracts map[string]*Deployment `json:"contracts"`
+}
+
+// Manifest 是部署清单，按网络名索引
+type Manifest struct {
+	Version  int                 `json:"version"`
+	Networks map[string]*Network `json:"networks"`
+
+	mu sync.RWMutex
+}
+
+// New 创建空清单
+func New() *Manifest {
+	return &Manifest{Version: Version, Networks: make(map[string]*Network)}
+}
+
+// Parse 解析清单内容
+func Parse(data []byte) (*Manifest, error) {
+	m := New()
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, fmt.Errorf("解析部署清单失败: %w", err)
+	}
+	if m.Version != Version {
+		return nil, fmt.Errorf("%w: %d（当前为 %d）", ErrVersion, m.Version, Version)
+	}
+	if m.Networks == nil {
+		m.Networks = make(map[string]*Network)
+	}
+	for name, n := range m.Networks {
+		if n.Contracts == nil {
+			m.Networks[name].Contracts = make(map[string]*Deployment)
+		}
+	}
+	return m, nil
+}
+
+// Load 读取清单文件；文件不存在时返回空清单
+func Load(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return New(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取部署清单失败: %w", err)
+	}
+	return Parse(data)
+}
+
+// Save 写入清单文件（先写临时文件再改名，中途失败不会损坏原文件）
+func (m *Manifest) Save(path string) error {
+	m.mu.RLock()
+	data, err := json.MarshalIndent(m, "", "  ")
+	m.mu.RUnlock()
+	if err != nil {
+		return fmt.Errorf("编码部署清单失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("创建目录失败: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("写入部署清单失败: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("写入部署清单失败: %w", err)
+	}
+	return nil
+}
+
+// Lookup 按网络名和合约名查找部署
+func (m *Manifest) Lookup(network, name string) (*Deployment, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	n, ok := m.Networks[network]
+	if !ok {
+		return nil, fmt.Errorf("%w: 网络 %s（已有: %s）", ErrNotFound, network, strings.Join(m.networkNames(), ", "))
+	}
+	d, ok := n.Contracts[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s 上的 %s（已有: %s）", ErrNotFound, network, name, strings.Join(contractNames(n), ", "))
+	}
+	return d, nil
+}
+
+// LookupChain 按链 ID 和合约名查找部署
+func (m *Manifest) LookupChain(chainID *big.Int, name string) (*Deployment, error) {
+	network, err := m.NetworkName(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return m.Lookup(network, name)
+}
+
+// Address 按网络名和合约名查找地址
+func (m *Manifest) Address(network, name string) (common.Address, error) {
+	d, err := m.Lookup(network, name)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return d.Address, nil
+}
+
+// NetworkName 返回链 ID 对应的网络名
+func (m *Manifest) NetworkName(chainID *big.Int) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, name := range m.networkNames() {
+		if chainID.IsUint64() && m.Networks[name].ChainID == chainID.Uint64() {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("%w: 链 ID %s", ErrNotFound, chainID)
+}
+
+// Record 记录一次部署（同名部署被覆盖）。网络不存在时创建，已存在时链 ID 必须一致
+func (m *Manifest) Record(network string, chainID uint64, name string, d *Deployment) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, ok := m.Networks[network]
+	if !ok {
+		n = &Network{ChainID: chainID, Contracts: make(map[string]*Deployment)}
+		m.Networks[network] = n
+	}
+	if n.ChainID != chainID {
+		return fmt.Errorf("网络 %s 的链 ID 为 %d，不能记录链 %d 上的部署", network, n.ChainID, chainID)
+	}
+	n.Contracts[name] = d
+	return nil
+}
+
+func (m *Manifest) networkNames() []string {
+	names := make([]string, 0, len(m.Networks))
+	for name := range m.Networks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func contractNames(n *Network) []string {
+	names := make([]string, 0, len(n.Contracts))
+	for name := range n.Contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+//go:embed deployments.json
+var defaultManifest []byte
+
+// Default 返回仓库内置的部署清单（pkg/deployments/deployments.json），每次返回新的副本
+func Default() *Manifest {
+	m, err := Parse(defaultManifest)
+	if err != nil {
+		panic(fmt.Sprintf("内置部署清单无效: %v", err)) // 内置数据，不应出错
+	}
+	return m
+}
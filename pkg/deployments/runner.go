@@ -0,0 +1,187 @@
+package deployments
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/create2"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/txutil"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// Backend 是脚本化部署需要的节点接口，*ethclient.Client 满足
+type Backend interface {
+	create2.Backend
+	ethereum.ChainIDReader
+}
+
+// Step 是部署脚本中的一步
+type Step struct {
+	Name     string // 记录在清单中的名字，同一网络内唯一
+	Contract string // 合约（工件）名，默认与 Name 相同
+	ABI      string
+	Bytecode []byte
+	// Args 返回构造函数参数，可以引用之前步骤（或清单中已有）的部署，例如传入代币地址
+	Args func(deployed map[string]*Deployment) ([]interface{}, error)
+	// Salt 不为 nil 时通过 CREATE2 工厂部署，否则普通创建交易
+	Salt *[32]byte
+}
+
+// Runner 按顺序执行部署脚本，每完成一步立即写入清单文件。
+// 清单中已有同名部署、字节码哈希一致且链上有代码的步骤会被跳过，因此失败后重新运行会从断点继续
+type Runner struct {
+	backend Backend
+
+	Manifest     *Manifest
+	Path         string // 清单文件路径，为空时只更新内存中的清单
+	Network      string
+	PollInterval time.Duration // 查询收据的间隔，默认 txutil.DefaultPollInterval
+	// OnStep 在每一步完成（或跳过）后调用，可用于打印进度
+	OnStep func(name string, d *Deployment, skipped bool)
+}
+
+// NewRunner 创建部署脚本执行器
+func NewRunner(backend Backend, manifest *Manifest, path, network string) *Runner {
+	return &Runner{backend: backend, Manifest: manifest, Path: path, Network: network}
+}
+
+// Run 执行部署脚本，返回按名字索引的全部部署（包括跳过的步骤）
+func (r *Runner) Run(ctx context.Context, opts *bind.TransactOpts, steps []Step) (map[string]*Deployment, error) {
+	chainID, err := r.backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取 Chain ID 失败: %w", chainerr.Wrap(err))
+	}
+	deployed := make(map[string]*Deployment)
+	r.Manifest.mu.RLock()
+	if n, ok := r.Manifest.Networks[r.Network]; ok {
+		if err := chainerr.CheckChainID(new(big.Int).SetUint64(n.ChainID), chainID); err != nil {
+			r.Manifest.mu.RUnlock()
+			return nil, fmt.Errorf("网络 %s: %w", r.Network, err)
+		}
+		for name, d := range n.Contracts {
+			deployed[name] = d
+		}
+	}
+	r.Manifest.mu.RUnlock()
+
+	for i, step := range steps {
+		d, skipped, err := r.step(ctx, opts, chainID.Uint64(), step, deployed)
+		if err != nil {
+			return deployed, fmt.Errorf("第 %d 步 %s 失败: %w", i+1, step.Name, err)
+		}
+		deployed[step.Name] = d
+		if r.OnStep != nil {
+			r.OnStep(step.Name, d, skipped)
+		}
+	}
+	return deployed, nil
+}
+
+func (r *Runner) step(ctx context.Context, opts *bind.TransactOpts, chainID uint64, step Step, deployed map[string]*Deployment) (*Deployment, bool, error) {
+	codeHash := crypto.Keccak256Hash(step.Bytecode)
+	if prev, ok := deployed[step.Name]; ok && prev.BytecodeHash != nil && *prev.BytecodeHash == codeHash {
+		code, err := r.backend.CodeAt(ctx, prev.Address, nil)
+		if err != nil {
+			return nil, false, fmt.Errorf("查询 %s 代码失败: %w", prev.Address.Hex(), chainerr.Wrap(err))
+		}
+		if len(code) > 0 {
+			return prev, true, nil
+		}
+		// 清单中有记录但链上没有代码（例如本地链重启），重新部署
+	}
+
+	parsed, err := abi.JSON(strings.NewReader(step.ABI))
+	if err != nil {
+		return nil, false, fmt.Errorf("解析 ABI 失败: %w", err)
+	}
+	var args []interface{}
+	if step.Args != nil {
+		if args, err = step.Args(deployed); err != nil {
+			return nil, false, fmt.Errorf("准备构造函数参数失败: %w", err)
+		}
+	}
+	packed, err := parsed.Pack("", args...)
+	if err != nil {
+		return nil, false, fmt.Errorf("编码构造函数参数失败: %w", err)
+	}
+
+	from := opts.From
+	d := &Deployment{
+		Contract:        step.Contract,
+		Deployer:        &from,
+		ConstructorArgs: packed,
+		BytecodeHash:    &codeHash,
+		ABI:             compactABI(step.ABI),
+	}
+	if d.Contract == "" {
+		d.Contract = step.Name
+	}
+	for _, arg := range args {
+		d.Args = append(d.Args, fmt.Sprint(arg))
+	}
+
+	if step.Salt != nil {
+		deployer := create2.New(r.backend)
+		deployer.PollInterval = r.PollInterval
+		initCode := append(append([]byte{}, step.Bytecode...), packed...)
+		dep, err := deployer.Deploy(ctx, opts, *step.Salt, initCode)
+		if err != nil {
+			return nil, false, err
+		}
+		salt := common.Hash(dep.Salt)
+		d.Address, d.Salt = dep.Address, &salt
+		if dep.Existing {
+			// 其他人已经用相同的 salt 和初始化代码部署过，地址相同，没有交易可记录
+			d.Deployer = nil
+		} else {
+			hash := dep.Tx.Hash()
+			d.TxHash, d.Block = &hash, dep.Receipt.BlockNumber.Uint64()
+		}
+	} else {
+		txOpts := *opts
+		if txOpts.Context == nil {
+			txOpts.Context = ctx
+		}
+		addr, tx, _, err := bind.DeployContract(&txOpts, parsed, step.Bytecode, r.backend, args...)
+		if err != nil {
+			return nil, false, fmt.Errorf("发送部署交易失败: %w", chainerr.Wrap(err))
+		}
+		receipt, err := txutil.WaitSuccess(ctx, r.backend, tx, r.PollInterval)
+		if err != nil {
+			return nil, false, fmt.Errorf("部署交易 %s 失败: %w", tx.Hash().Hex(), err)
+		}
+		hash := tx.Hash()
+		d.Address, d.TxHash, d.Block = addr, &hash, receipt.BlockNumber.Uint64()
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	d.DeployedAt = &now
+
+	if err := r.Manifest.Record(r.Network, chainID, step.Name, d); err != nil {
+		return nil, false, err
+	}
+	if r.Path != "" {
+		if err := r.Manifest.Save(r.Path); err != nil {
+			return nil, false, err
+		}
+	}
+	return d, false, nil
+}
+
+// compactABI 去掉 ABI JSON 中的空白，无效时原样保留
+func compactABI(s string) []byte {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, []byte(s)); err != nil {
+		return []byte(s)
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,285 @@
+// Package devnode 封装 Hardhat/Anvil 开发节点的控制接口：快照回滚、出块、时间推进、
+// 账户模拟、直接修改余额/代码/存储以及重置分叉，并自动识别节点类型
+package devnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Flavor 是开发节点类型
+type Flavor string
+
+const (
+	Hardhat Flavor = "hardhat"
+	Anvil   Flavor = "anvil"
+	Unknown Flavor = "unknown" // 无法识别，调用时按 Hardhat 方法名尝试
+)
+
+// ErrUnsupported 表示节点不支持该方法
+var ErrUnsupported = errors.New("节点不支持该方法")
+
+// ErrSnapshotNotFound 表示快照不存在或已被回滚使用
+var ErrSnapshotNotFound = errors.New("快照不存在")
+
+// methodNotFound 是 JSON-RPC 规范中方法不存在的错误码
+const methodNotFound = -32601
+
+// Node 是开发节点控制客户端
+type Node struct {
+	rpc     *rpc.Client
+	flavor  Flavor
+	version string
+}
+
+// Dial 连接开发节点并识别类型
+func Dial(ctx context.Context, url string) (*Node, error) {
+	c, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("连接节点失败: %w", err)
+	}
+	n, err := New(ctx, c)
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+	return n, nil
+}
+
+// New 使用已有的 RPC 连接创建客户端，通过 web3_clientVersion 识别节点类型
+func New(ctx context.Context, c *rpc.Client) (*Node, error) {
+	n := &Node{rpc: c, flavor: Unknown}
+	if err := c.CallContext(ctx, &n.version, "web3_clientVersion"); err != nil {
+		return nil, fmt.Errorf("获取节点版本失败: %w", err)
+	}
+	version := strings.ToLower(n.version)
+	switch {
+	case strings.HasPrefix(version, "hardhat"):
+		n.flavor = Hardhat
+	case strings.HasPrefix(version, "anvil"):
+		n.flavor = Anvil
+	}
+	return n, nil
+}
+
+// Flavor 返回节点类型
+func (n *Node) Flavor() Flavor { return n.flavor }
+
+// Version 返回 web3_clientVersion
+func (n *Node) Version() string { return n.version }
+
+// Client 返回底层 RPC 连接
+func (n *Node) Client() *rpc.Client { return n.rpc }
+
+// Close 关闭连接
+func (n *Node) Close() { n.rpc.Close() }
+
+// Supports 探测节点是否支持某个方法。JSON-RPC 没有类似 ERC-165 的能力声明，只能探测：
+// 先用一个不存在的方法做对照，节点必须返回“方法不存在”（否则无法区分，一律视为不支持，
+// 包括连接失败），再用无法通过参数校验的参数探测目标方法，节点不会真正执行。
+// 方法名可以省略前缀，例如 "impersonateAccount" 会按节点类型补全为 hardhat_ 或 anvil_ 前缀
+func (n *Node) Supports(ctx context.Context, method string) bool {
+	if !strings.Contains(method, "_") {
+		method = n.vendor(method)
+	}
+	if !isMethodNotFound(n.probe(ctx, probeMethod)) {
+		return false
+	}
+	return !isMethodNotFound(n.probe(ctx, method))
+}
+
+// probeMethod 是对照用的方法名，任何节点都不应实现
+const probeMethod = "iws_noSuchMethod"
+
+// probe 用一个无法解析为任何参数类型的字符串调用方法：参数校验失败时方法不会执行，
+// 只有方法本身不存在时才返回 -32601
+func (n *Node) probe(ctx context.Context, method string) error {
+	var raw interface{}
+	return n.rpc.CallContext(ctx, &raw, method, "__probe__")
+}
+
+// vendor 为 hardhat_/anvil_ 系列方法补全前缀
+func (n *Node) vendor(method string) string {
+	if n.flavor == Anvil {
+		return "anvil_" + method
+	}
+	return "hardhat_" + method
+}
+
+// call 调用节点方法，方法不存在时返回 ErrUnsupported
+func (n *Node) call(ctx context.Context, result interface{}, method string, args ...interface{}) error {
+	err := n.rpc.CallContext(ctx, result, method, args...)
+	if err == nil {
+		return nil
+	}
+	if isMethodNotFound(err) {
+		return fmt.Errorf("%s: %w", method, ErrUnsupported)
+	}
+	return fmt.Errorf("%s 调用失败: %w", method, err)
+}
+
+func isMethodNotFound(err error) bool {
+	var rpcErr rpc.Error
+	return errors.As(err, &rpcErr) && rpcErr.ErrorCode() == methodNotFound
+}
+
+// Snapshot 保存当前链状态，返回快照 ID
+func (n *Node) Snapshot(ctx context.Context) (string, error) {
+	var id string
+	if err := n.call(ctx, &id, "evm_snapshot"); err != nil {
+		return "", err
+	}
+	return id, nil
+}
+
+// Revert 回滚到快照。Hardhat 和 Anvil 的快照都只能使用一次，回滚后需重新保存
+func (n *Node) Revert(ctx context.Context, id string) error {
+	var ok bool
+	if err := n.call(ctx, &ok, "evm_revert", id); err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("回滚到 %s 失败: %w", id, ErrSnapshotNotFound)
+	}
+	return nil
+}
+
+// Mine 立即出 blocks 个块（至少 1 个）；节点不支持批量出块时逐个调用 evm_mine
+func (n *Node) Mine(ctx context.Context, blocks uint64) error {
+	if blocks == 0 {
+		blocks = 1
+	}
+	if blocks > 1 {
+		err := n.call(ctx, nil, n.vendor("mine"), hexutil.Uint64(blocks))
+		if !errors.Is(err, ErrUnsupported) {
+			return err
+		}
+	}
+	for i := uint64(0); i < blocks; i++ {
+		if err := n.call(ctx, nil, "evm_mine"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IncreaseTime 推进链上时间，下一个区块的时间戳生效；返回节点报告的累计偏移秒数
+func (n *Node) IncreaseTime(ctx context.Context, d time.Duration) (int64, error) {
+	var total interface{}
+	if err := n.call(ctx, &total, "evm_increaseTime", hexutil.Uint64(d/time.Second)); err != nil {
+		return 0, err
+	}
+	// Hardhat 返回十进制字符串，Anvil 返回数字
+	switch v := total.(type) {
+	case float64:
+		return int64(v), nil
+	case string:
+		var offset big.Int
+		if _, ok := offset.SetString(v, 0); ok {
+			return offset.Int64(), nil
+		}
+	}
+	return 0, nil
+}
+
+// SetNextBlockTimestamp 设置下一个区块的时间戳
+func (n *Node) SetNextBlockTimestamp(ctx context.Context, t time.Time) error {
+	return n.call(ctx, nil, "evm_setNextBlockTimestamp", hexutil.Uint64(t.Unix()))
+}
+
+// Impersonate 允许不持有私钥直接以 addr 身份发送交易（配合 SendTransaction 使用）
+func (n *Node) Impersonate(ctx context.Context, addr common.Address) error {
+	return n.call(ctx, nil, n.vendor("impersonateAccount"), addr)
+}
+
+// StopImpersonating 取消账户模拟
+func (n *Node) StopImpersonating(ctx context.Context, addr common.Address) error {
+	return n.call(ctx, nil, n.vendor("stopImpersonatingAccount"), addr)
+}
+
+// SetBalance 直接设置账户余额（wei）
+func (n *Node) SetBalance(ctx context.Context, addr common.Address, balance *big.Int) error {
+	return n.call(ctx, nil, n.vendor("setBalance"), addr, (*hexutil.Big)(balance))
+}
+
+// SetNonce 直接设置账户 nonce
+func (n *Node) SetNonce(ctx context.Context, addr common.Address, nonce uint64) error {
+	return n.call(ctx, nil, n.vendor("setNonce"), addr, hexutil.Uint64(nonce))
+}
+
+// SetCode 直接替换账户的运行时代码
+func (n *Node) SetCode(ctx context.Context, addr common.Address, code []byte) error {
+	return n.call(ctx, nil, n.vendor("setCode"), addr, hexutil.Bytes(code))
+}
+
+// SetStorageAt 直接写入存储槽
+func (n *Node) SetStorageAt(ctx context.Context, addr common.Address, slot, value common.Hash) error {
+	// 槽位按 quantity 编码（Hardhat 不接受前导零），值为 32 字节
+	return n.call(ctx, nil, n.vendor("setStorageAt"), addr, hexutil.EncodeBig(slot.Big()), value)
+}
+
+// Reset 把节点重置为从 forkURL 在 block 高度分叉（block 为 0 表示最新区块）；forkURL 为空时重置为非分叉的本地链
+func (n *Node) Reset(ctx context.Context, forkURL string, block uint64) error {
+	if forkURL == "" {
+		return n.call(ctx, nil, n.vendor("reset"))
+	}
+	forking := map[string]interface{}{"jsonRpcUrl": forkURL}
+	if block > 0 {
+		forking["blockNumber"] = block
+	}
+	return n.call(ctx, nil, n.vendor("reset"), map[string]interface{}{"forking": forking})
+}
+
+// SendArgs 是以模拟账户身份发送的交易
+type SendArgs struct {
+	From  common.Address
+	To    *common.Address
+	Value *big.Int
+	Data  []byte
+	Gas   uint64 // 0 表示由节点估算
+}
+
+// SendTransaction 通过 eth_sendTransaction 由节点代为签名发送，用于模拟账户或节点自带的解锁账户
+func (n *Node) SendTransaction(ctx context.Context, args SendArgs) (common.Hash, error) {
+	tx := map[string]interface{}{"from": args.From}
+	if args.To != nil {
+		tx["to"] = args.To
+	}
+	if args.Value != nil {
+		tx["value"] = (*hexutil.Big)(args.Value)
+	}
+	if len(args.Data) > 0 {
+		tx["data"] = hexutil.Bytes(args.Data)
+	}
+	if args.Gas > 0 {
+		tx["gas"] = hexutil.Uint64(args.Gas)
+	}
+	var hash common.Hash
+	if err := n.call(ctx, &hash, "eth_sendTransaction", tx); err != nil {
+		return common.Hash{}, err
+	}
+	return hash, nil
+}
+
+// MappingSlot 计算 Solidity mapping(address => ...) 中 key 对应的存储槽：keccak256(key . slot)
+func MappingSlot(key common.Address, slot uint64) common.Hash {
+	return crypto.Keccak256Hash(
+		common.LeftPadBytes(key.Bytes(), 32),
+		common.LeftPadBytes(new(big.Int).SetUint64(slot).Bytes(), 32),
+	)
+}
+
+// SetTokenBalance 直接改写 ERC20 的 balanceOf 存储，balanceSlot 为 balanceOf mapping 的槽位
+// （WETH9/WBNB 为 3，OpenZeppelin ERC20 通常为 0）。不会同步修改 totalSupply
+func (n *Node) SetTokenBalance(ctx context.Context, token, holder common.Address, balanceSlot uint64, amount *big.Int) error {
+	return n.SetStorageAt(ctx, token, MappingSlot(holder, balanceSlot), common.BigToHash(amount))
+}
@@ -0,0 +1,197 @@
+package devnode
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// stub 是一个最小的开发节点，记录收到的调用
+type stub struct {
+	mu        sync.Mutex
+	calls     []string
+	snapshots int
+	storage   map[common.Hash]common.Hash
+	balances  map[common.Address]*big.Int
+}
+
+func (s *stub) record(format string, args ...interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, fmt.Sprintf(format, args...))
+}
+
+type web3API struct{ version string }
+
+func (a *web3API) ClientVersion() string { return a.version }
+
+type evmAPI struct{ s *stub }
+
+func (a *evmAPI) Snapshot() string {
+	a.s.snapshots++
+	a.s.record("evm_snapshot")
+	return hexutil.EncodeUint64(uint64(a.s.snapshots))
+}
+
+func (a *evmAPI) Revert(id string) bool {
+	a.s.record("evm_revert %s", id)
+	return id == hexutil.EncodeUint64(uint64(a.s.snapshots))
+}
+
+func (a *evmAPI) Mine() { a.s.record("evm_mine") }
+
+func (a *evmAPI) IncreaseTime(seconds hexutil.Uint64) string {
+	a.s.record("evm_increaseTime %d", seconds)
+	return fmt.Sprint(uint64(seconds))
+}
+
+// vendorAPI 注册为 hardhat 或 anvil 命名空间
+type vendorAPI struct{ s *stub }
+
+func (a *vendorAPI) Mine(blocks hexutil.Uint64) { a.s.record("mine %d", blocks) }
+
+func (a *vendorAPI) ImpersonateAccount(addr common.Address) { a.s.record("impersonate %s", addr.Hex()) }
+
+func (a *vendorAPI) SetBalance(addr common.Address, balance *hexutil.Big) {
+	a.s.balances[addr] = balance.ToInt()
+}
+
+func (a *vendorAPI) SetStorageAt(addr common.Address, slot *hexutil.Big, value common.Hash) {
+	a.s.storage[common.BigToHash(slot.ToInt())] = value
+}
+
+func (a *vendorAPI) Reset(opts *map[string]interface{}) {
+	if opts == nil {
+		a.s.record("reset")
+		return
+	}
+	a.s.record("reset %v", (*opts)["forking"])
+}
+
+func newStub(t *testing.T, version, vendor string) (*Node, *stub) {
+	s := &stub{storage: make(map[common.Hash]common.Hash), balances: make(map[common.Address]*big.Int)}
+	server := rpc.NewServer()
+	apis := map[string]interface{}{"web3": &web3API{version}, "evm": &evmAPI{s}}
+	if vendor != "" {
+		apis[vendor] = &vendorAPI{s}
+	}
+	for name, api := range apis {
+		if err := server.RegisterName(name, api); err != nil {
+			t.Fatalf("❌ 注册 %s 失败: %v", name, err)
+		}
+	}
+	t.Cleanup(server.Stop)
+
+	n, err := New(context.Background(), rpc.DialInProc(server))
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	t.Cleanup(n.Close)
+	return n, s
+}
+
+// 测试 Hardhat：快照回滚、批量出块、时间推进、模拟账户和重置分叉
+func TestHardhat(t *testing.T) {
+	ctx := context.Background()
+	n, s := newStub(t, "HardhatNetwork/2.22.0/@ethereumjs/vm/7.0.0", "hardhat")
+	if n.Flavor() != Hardhat {
+		t.Fatalf("❌ 应识别为 Hardhat: %s", n.Flavor())
+	}
+
+	id, err := n.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("❌ 快照失败: %v", err)
+	}
+	if err := n.Revert(ctx, id); err != nil {
+		t.Fatalf("❌ 回滚失败: %v", err)
+	}
+	if err := n.Revert(ctx, "0x99"); !errors.Is(err, ErrSnapshotNotFound) {
+		t.Fatalf("❌ 回滚不存在的快照应返回 ErrSnapshotNotFound: %v", err)
+	}
+
+	if err := n.Mine(ctx, 5); err != nil {
+		t.Fatalf("❌ 出块失败: %v", err)
+	}
+	offset, err := n.IncreaseTime(ctx, time.Hour)
+	if err != nil || offset != 3600 {
+		t.Fatalf("❌ 推进时间失败: %d %v", offset, err)
+	}
+
+	account0 := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	if err := n.Impersonate(ctx, account0); err != nil {
+		t.Fatalf("❌ 模拟账户失败: %v", err)
+	}
+	if err := n.SetBalance(ctx, account0, big.NewInt(1e18)); err != nil || s.balances[account0].Cmp(big.NewInt(1e18)) != 0 {
+		t.Fatalf("❌ 设置余额失败: %v", err)
+	}
+	if err := n.Reset(ctx, "https://bsc-testnet.example", 30_000_000); err != nil {
+		t.Fatalf("❌ 重置分叉失败: %v", err)
+	}
+
+	want := []string{
+		"evm_snapshot", "evm_revert 0x1", "evm_revert 0x99", "mine 5", "evm_increaseTime 3600",
+		"impersonate " + account0.Hex(), "reset map[blockNumber:3e+07 jsonRpcUrl:https://bsc-testnet.example]",
+	}
+	if fmt.Sprint(s.calls) != fmt.Sprint(want) {
+		t.Fatalf("❌ 调用序列不符:\n实际 %v\n期望 %v", s.calls, want)
+	}
+
+	if !n.Supports(ctx, "impersonateAccount") || !n.Supports(ctx, "evm_mine") || n.Supports(ctx, "setNonce") {
+		t.Fatal("❌ 能力探测结果不符")
+	}
+	if fmt.Sprint(s.calls) != fmt.Sprint(want) {
+		t.Fatalf("❌ 能力探测不应真正执行方法: %v", s.calls)
+	}
+	t.Logf("✅ %s 调用序列: %v", n.Version(), s.calls)
+}
+
+// 测试 Anvil：使用 anvil_ 前缀改写 ERC20 余额存储槽
+func TestAnvilTokenBalance(t *testing.T) {
+	ctx := context.Background()
+	n, s := newStub(t, "anvil/v0.2.0", "anvil")
+	if n.Flavor() != Anvil {
+		t.Fatalf("❌ 应识别为 Anvil: %s", n.Flavor())
+	}
+
+	wbnb := common.HexToAddress("0xae13d989daC2f0dEbFf460aC112a837C89BAa7cd")
+	holder := common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266")
+	amount := new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+	if err := n.SetTokenBalance(ctx, wbnb, holder, 3, amount); err != nil {
+		t.Fatalf("❌ 设置代币余额失败: %v", err)
+	}
+	if got := s.storage[MappingSlot(holder, 3)]; got.Big().Cmp(amount) != 0 {
+		t.Fatalf("❌ 余额存储槽不符: %s", got.Hex())
+	}
+}
+
+// 测试不支持厂商方法的节点：批量出块回退为逐个 evm_mine，厂商方法返回 ErrUnsupported，断开后能力探测不可信
+func TestUnsupported(t *testing.T) {
+	ctx := context.Background()
+	n, s := newStub(t, "Geth/v1.16.7", "")
+	if n.Flavor() != Unknown {
+		t.Fatalf("❌ 应识别为未知节点: %s", n.Flavor())
+	}
+	if err := n.Mine(ctx, 3); err != nil {
+		t.Fatalf("❌ 出块失败: %v", err)
+	}
+	if len(s.calls) != 3 {
+		t.Fatalf("❌ 应回退为 3 次 evm_mine: %v", s.calls)
+	}
+	if err := n.Impersonate(ctx, common.Address{}); !errors.Is(err, ErrUnsupported) {
+		t.Fatalf("❌ 应返回 ErrUnsupported: %v", err)
+	}
+
+	// 对照探测失败（例如连接已断开）时无法判断，不能当作支持
+	n.Close()
+	if n.Supports(ctx, "evm_mine") {
+		t.Fatal("❌ 对照探测失败时应视为不支持")
+	}
+}
@@ -0,0 +1,300 @@
+// Package eventdb 把区块、交易、收据和解码后的事件日志写入本地 SQLite 数据库
+package eventdb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+
+	_ "modernc.org/sqlite" // 纯 Go 实现的 SQLite 驱动，无需 cgo
+)
+
+// SchemaVersion 是当前数据库结构版本，写入 PRAGMA user_version
+const SchemaVersion = 1
+
+// 表结构：地址和哈希统一存为 0x 开头的十六进制字符串（地址为校验和格式），
+// 金额等大整数存为十进制字符串
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS blocks (
+		chain_id    INTEGER NOT NULL,
+		hash        TEXT    NOT NULL,
+		number      INTEGER NOT NULL,
+		parent_hash TEXT    NOT NULL,
+		timestamp   INTEGER NOT NULL,
+		PRIMARY KEY (chain_id, hash)
+	)`,
+	`CREATE INDEX IF NOT EXISTS blocks_by_number ON blocks (chain_id, number)`,
+	`CREATE TABLE IF NOT EXISTS transactions (
+		chain_id     INTEGER NOT NULL,
+		hash         TEXT    NOT NULL,
+		block_hash   TEXT    NOT NULL,
+		block_number INTEGER NOT NULL,
+		tx_index     INTEGER NOT NULL,
+		from_addr    TEXT    NOT NULL,
+		to_addr      TEXT,
+		value        TEXT    NOT NULL,
+		nonce        INTEGER NOT NULL,
+		input        TEXT    NOT NULL,
+		PRIMARY KEY (chain_id, hash)
+	)`,
+	`CREATE INDEX IF NOT EXISTS transactions_by_block ON transactions (chain_id, block_hash)`,
+	`CREATE TABLE IF NOT EXISTS receipts (
+		chain_id            INTEGER NOT NULL,
+		tx_hash             TEXT    NOT NULL,
+		block_hash          TEXT    NOT NULL,
+		block_number        INTEGER NOT NULL,
+		status              INTEGER NOT NULL,
+		gas_used            INTEGER NOT NULL,
+		effective_gas_price TEXT,
+		contract_address    TEXT,
+		PRIMARY KEY (chain_id, tx_hash)
+	)`,
+	`CREATE INDEX IF NOT EXISTS receipts_by_block ON receipts (chain_id, block_hash)`,
+	`CREATE TABLE IF NOT EXISTS logs (
+		chain_id     INTEGER NOT NULL,
+		block_hash   TEXT    NOT NULL,
+		log_index    INTEGER NOT NULL,
+		block_number INTEGER NOT NULL,
+		tx_hash      TEXT    NOT NULL,
+		tx_index     INTEGER NOT NULL,
+		address      TEXT    NOT NULL,
+		topic0       TEXT,
+		topic1       TEXT,
+		topic2       TEXT,
+		topic3       TEXT,
+		data         TEXT    NOT NULL,
+		event        TEXT,
+		args         TEXT,
+		PRIMARY KEY (chain_id, block_hash, log_index)
+	)`,
+	`CREATE INDEX IF NOT EXISTS logs_by_address ON logs (chain_id, address, event, block_number)`,
+}
+
+// DB 是事件数据库
+type DB struct {
+	db *sql.DB
+}
+
+// Open 打开（或创建）SQLite 数据库并初始化表结构，path 为 ":memory:" 时使用内存库
+func Open(path string) (*DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("打开数据库失败: %w", err)
+	}
+	// SQLite 同时只允许一个写连接，内存库也必须共享同一连接
+	db.SetMaxOpenConns(1)
+
+	for _, pragma := range []string{"PRAGMA journal_mode = WAL", "PRAGMA foreign_keys = ON"} {
+		if _, err := db.Exec(pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("设置数据库参数失败: %w", err)
+		}
+	}
+
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("读取数据库版本失败: %w", err)
+	}
+	if version > SchemaVersion {
+		db.Close()
+		return nil, fmt.Errorf("数据库版本 %d 高于当前程序支持的版本 %d", version, SchemaVersion)
+	}
+	for _, stmt := range schema {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("初始化表结构失败: %w", err)
+		}
+	}
+	if _, err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion)); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("写入数据库版本失败: %w", err)
+	}
+	return &DB{db: db}, nil
+}
+
+// Close 关闭数据库
+func (d *DB) Close() error {
+	return d.db.Close()
+}
+
+// SQL 返回底层 *sql.DB，用于自定义查询
+func (d *DB) SQL() *sql.DB {
+	return d.db
+}
+
+// Tx 是一次写事务，所有写入在 Commit 后生效
+type Tx struct {
+	tx      *sql.Tx
+	chainID int64
+}
+
+// Begin 开始针对 chainID 的写事务
+func (d *DB) Begin(ctx context.Context, chainID *big.Int) (*Tx, error) {
+	tx, err := d.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("开始事务失败: %w", err)
+	}
+	return &Tx{tx: tx, chainID: chainID.Int64()}, nil
+}
+
+// Commit 提交事务
+func (t *Tx) Commit() error {
+	return t.tx.Commit()
+}
+
+// Rollback 回滚事务
+func (t *Tx) Rollback() error {
+	return t.tx.Rollback()
+}
+
+// UpsertBlock 写入区块头；若同一高度已存在不同哈希的区块（发生重组），先删除旧区块及其数据
+func (t *Tx) UpsertBlock(ctx context.Context, header *types.Header) error {
+	hash := header.Hash().Hex()
+
+	rows, err := t.tx.QueryContext(ctx,
+		`SELECT hash FROM blocks WHERE chain_id = ? AND number = ? AND hash <> ?`,
+		t.chainID, header.Number.Int64(), hash)
+	if err != nil {
+		return fmt.Errorf("查询同高度区块失败: %w", err)
+	}
+	var stale []string
+	for rows.Next() {
+		var h string
+		if err := rows.Scan(&h); err != nil {
+			rows.Close()
+			return err
+		}
+		stale = append(stale, h)
+	}
+	rows.Close()
+	for _, h := range stale {
+		if err := t.DeleteBlock(ctx, common.HexToHash(h)); err != nil {
+			return err
+		}
+	}
+
+	_, err = t.tx.ExecContext(ctx,
+		`INSERT INTO blocks (chain_id, hash, number, parent_hash, timestamp) VALUES (?, ?, ?, ?, ?)
+		 ON CONFLICT (chain_id, hash) DO UPDATE SET number = excluded.number, parent_hash = excluded.parent_hash, timestamp = excluded.timestamp`,
+		t.chainID, hash, header.Number.Int64(), header.ParentHash.Hex(), int64(header.Time))
+	if err != nil {
+		return fmt.Errorf("写入区块失败: %w", err)
+	}
+	return nil
+}
+
+// UpsertTransaction 写入交易，from 为已恢复的发送者地址
+func (t *Tx) UpsertTransaction(ctx context.Context, tx *types.Transaction, from common.Address, blockHash common.Hash, blockNumber uint64, index uint) error {
+	var to interface{}
+	if tx.To() != nil {
+		to = tx.To().Hex()
+	}
+	_, err := t.tx.ExecContext(ctx,
+		`INSERT INTO transactions (chain_id, hash, block_hash, block_number, tx_index, from_addr, to_addr, value, nonce, input)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (chain_id, hash) DO UPDATE SET block_hash = excluded.block_hash, block_number = excluded.block_number, tx_index = excluded.tx_index`,
+		t.chainID, tx.Hash().Hex(), blockHash.Hex(), int64(blockNumber), int64(index),
+		from.Hex(), to, tx.Value().String(), int64(tx.Nonce()), hexutil.Encode(tx.Data()))
+	if err != nil {
+		return fmt.Errorf("写入交易失败: %w", err)
+	}
+	return nil
+}
+
+// UpsertReceipt 写入交易收据
+func (t *Tx) UpsertReceipt(ctx context.Context, receipt *types.Receipt) error {
+	var price, contract interface{}
+	if receipt.EffectiveGasPrice != nil {
+		price = receipt.EffectiveGasPrice.String()
+	}
+	if receipt.ContractAddress != (common.Address{}) {
+		contract = receipt.ContractAddress.Hex()
+	}
+	_, err := t.tx.ExecContext(ctx,
+		`INSERT INTO receipts (chain_id, tx_hash, block_hash, block_number, status, gas_used, effective_gas_price, contract_address)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (chain_id, tx_hash) DO UPDATE SET block_hash = excluded.block_hash, block_number = excluded.block_number,
+		   status = excluded.status, gas_used = excluded.gas_used, effective_gas_price = excluded.effective_gas_price`,
+		t.chainID, receipt.TxHash.Hex(), receipt.BlockHash.Hex(), receipt.BlockNumber.Int64(),
+		int64(receipt.Status), int64(receipt.GasUsed), price, contract)
+	if err != nil {
+		return fmt.Errorf("写入收据失败: %w", err)
+	}
+	return nil
+}
+
+// UpsertLog 写入事件日志，按 (chain, blockHash, logIndex) 去重；event 为 nil 表示无法解码。
+// Removed 为 true 的日志（链重组撤销）会被删除
+func (t *Tx) UpsertLog(ctx context.Context, log *types.Log, event string, args map[string]interface{}) error {
+	if log.Removed {
+		_, err := t.tx.ExecContext(ctx,
+			`DELETE FROM logs WHERE chain_id = ? AND block_hash = ? AND log_index = ?`,
+			t.chainID, log.BlockHash.Hex(), int64(log.Index))
+		if err != nil {
+			return fmt.Errorf("删除已撤销日志失败: %w", err)
+		}
+		return nil
+	}
+
+	var topics [4]interface{}
+	for i := 0; i < len(log.Topics) && i < 4; i++ {
+		topics[i] = log.Topics[i].Hex()
+	}
+	var eventName, argsJSON interface{}
+	if event != "" {
+		eventName = event
+		encoded, err := json.Marshal(args)
+		if err != nil {
+			return fmt.Errorf("序列化事件参数失败: %w", err)
+		}
+		argsJSON = string(encoded)
+	}
+
+	_, err := t.tx.ExecContext(ctx,
+		`INSERT INTO logs (chain_id, block_hash, log_index, block_number, tx_hash, tx_index, address, topic0, topic1, topic2, topic3, data, event, args)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		 ON CONFLICT (chain_id, block_hash, log_index) DO UPDATE SET event = excluded.event, args = excluded.args`,
+		t.chainID, log.BlockHash.Hex(), int64(log.Index), int64(log.BlockNumber), log.TxHash.Hex(), int64(log.TxIndex),
+		log.Address.Hex(), topics[0], topics[1], topics[2], topics[3], hexutil.Encode(log.Data), eventName, argsJSON)
+	if err != nil {
+		return fmt.Errorf("写入日志失败: %w", err)
+	}
+	return nil
+}
+
+// DeleteBlock 删除指定区块及其交易、收据和日志（链重组时使用）
+func (t *Tx) DeleteBlock(ctx context.Context, hash common.Hash) error {
+	for _, stmt := range []string{
+		`DELETE FROM logs WHERE chain_id = ? AND block_hash = ?`,
+		`DELETE FROM receipts WHERE chain_id = ? AND block_hash = ?`,
+		`DELETE FROM transactions WHERE chain_id = ? AND block_hash = ?`,
+		`DELETE FROM blocks WHERE chain_id = ? AND hash = ?`,
+	} {
+		if _, err := t.tx.ExecContext(ctx, stmt, t.chainID, hash.Hex()); err != nil {
+			return fmt.Errorf("删除区块 %s 失败: %w", hash.Hex(), err)
+		}
+	}
+	return nil
+}
+
+// Rewind 删除高度 >= from 的所有数据，用于已知重组深度时整体回滚
+func (t *Tx) Rewind(ctx context.Context, from uint64) error {
+	for _, stmt := range []string{
+		`DELETE FROM logs WHERE chain_id = ? AND block_number >= ?`,
+		`DELETE FROM receipts WHERE chain_id = ? AND block_number >= ?`,
+		`DELETE FROM transactions WHERE chain_id = ? AND block_number >= ?`,
+		`DELETE FROM blocks WHERE chain_id = ? AND number >= ?`,
+	} {
+		if _, err := t.tx.ExecContext(ctx, stmt, t.chainID, int64(from)); err != nil {
+			return fmt.Errorf("回滚到区块 %d 失败: %w", from, err)
+		}
+	}
+	return nil
+}
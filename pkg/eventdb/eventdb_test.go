@@ -0,0 +1,166 @@
+package eventdb
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+var (
+	chainID = big.NewInt(11155111)
+	token   = common.HexToAddress("0xE5aFC41736bBE96cCB912Cb2d2e6BB503979b657")
+	alice   = common.HexToAddress("0x8c8aB9B6178877246B224F8D745A1410C4928373")
+	bob     = common.HexToAddress("0x2281cB9267ABAF264c0A4c0dD2e414b4d68cE634")
+)
+
+func transferLog(header *types.Header, index uint, from, to common.Address, value int64) *types.Log {
+	return &types.Log{
+		Address:     token,
+		Topics:      []common.Hash{TransferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:        common.LeftPadBytes(big.NewInt(value).Bytes(), 32),
+		BlockNumber: header.Number.Uint64(),
+		BlockHash:   header.Hash(),
+		TxHash:      common.BigToHash(big.NewInt(int64(index) + 1)),
+		Index:       index,
+	}
+}
+
+// 测试转账写入、幂等 upsert、按条件查询（忽略 ERC721 Transfer）以及重组删除
+func TestTransfersAndReorg(t *testing.T) {
+	ctx := context.Background()
+	db, err := Open(filepath.Join(t.TempDir(), "events.db"))
+	if err != nil {
+		t.Fatalf("❌ 打开数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	headers := []*types.Header{
+		{Number: big.NewInt(100), Time: 1, Extra: []byte("a")},
+		{Number: big.NewInt(101), Time: 2, Extra: []byte("a")},
+	}
+	write := func(logs ...*types.Log) {
+		tx, err := db.Begin(ctx, chainID)
+		if err != nil {
+			t.Fatalf("❌ 开始事务失败: %v", err)
+		}
+		for _, h := range headers {
+			if err := tx.UpsertBlock(ctx, h); err != nil {
+				t.Fatalf("❌ 写入区块失败: %v", err)
+			}
+		}
+		for _, l := range logs {
+			if err := tx.UpsertLog(ctx, l, "Transfer", map[string]interface{}{"value": "1"}); err != nil {
+				t.Fatalf("❌ 写入日志失败: %v", err)
+			}
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatalf("❌ 提交失败: %v", err)
+		}
+	}
+
+	// 同一合约地址下签名相同的 ERC721 Transfer：tokenId 在 topic3，data 为空，不应计入转账
+	nft := transferLog(headers[0], 2, alice, bob, 0)
+	nft.Topics = append(nft.Topics, common.BigToHash(big.NewInt(5)))
+	nft.Data = nil
+
+	logs := []*types.Log{
+		transferLog(headers[0], 0, alice, bob, 10),
+		transferLog(headers[0], 1, bob, alice, 3),
+		transferLog(headers[1], 0, alice, bob, 7),
+		nft,
+	}
+	write(logs...)
+	write(logs...) // 重复写入不应产生重复记录
+
+	got, err := db.Transfers(ctx, TransferQuery{ChainID: chainID, Token: token, To: &bob, FromBlock: 100, ToBlock: 101})
+	if err != nil {
+		t.Fatalf("❌ 查询失败: %v", err)
+	}
+	if len(got) != 2 || got[0].Value.Int64() != 10 || got[1].Value.Int64() != 7 || got[1].From != alice {
+		t.Fatalf("❌ 查询结果不符: %+v", got)
+	}
+
+	// 区块 101 被重组替换：旧区块的日志应被删除
+	headers = []*types.Header{{Number: big.NewInt(101), Time: 3, Extra: []byte("b")}}
+	write()
+
+	got, err = db.Transfers(ctx, TransferQuery{ChainID: chainID, Token: token, To: &bob})
+	if err != nil {
+		t.Fatalf("❌ 查询失败: %v", err)
+	}
+	if len(got) != 1 || got[0].BlockNumber != 100 {
+		t.Fatalf("❌ 重组后应只剩区块 100 的转账: %+v", got)
+	}
+
+	// Removed 日志应被删除
+	removed := *logs[0]
+	removed.Removed = true
+	write(&removed)
+	if got, _ := db.Transfers(ctx, TransferQuery{ChainID: chainID, Token: token, To: &bob}); len(got) != 0 {
+		t.Fatalf("❌ 已撤销的日志未删除: %+v", got)
+	}
+}
+
+// 在模拟链上部署 Store 并索引 ItemSet 事件
+func TestIndexStoreEvents(t *testing.T) {
+	ctx := context.Background()
+	key, _ := crypto.GenerateKey()
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+
+	backend := simulated.NewBackend(types.GenesisAlloc{owner: {Balance: big.NewInt(1e18)}})
+	defer backend.Close()
+	client := backend.Client()
+
+	simChainID, _ := client.ChainID(ctx)
+	auth, _ := bind.NewKeyedTransactorWithChainID(key, simChainID)
+	addr, _, instance, err := store.DeployStore(auth, client, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ 部署 Store 失败: %v", err)
+	}
+	backend.Commit()
+
+	var k, v [32]byte
+	copy(k[:], "mykey")
+	copy(v[:], "myvalue")
+	if _, err := instance.SetItem(auth, k, v); err != nil {
+		t.Fatalf("❌ SetItem 失败: %v", err)
+	}
+	backend.Commit()
+
+	db, err := Open(":memory:")
+	if err != nil {
+		t.Fatalf("❌ 打开数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	head, _ := client.BlockNumber(ctx)
+	ix := NewIndexer(db, client, simChainID, nil)
+	n, err := ix.IndexRange(ctx, []common.Address{addr}, 0, head, 1)
+	if err != nil {
+		t.Fatalf("❌ 索引失败: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("❌ 期望索引 1 条日志，实际 %d", n)
+	}
+
+	logs, err := db.Logs(ctx, LogQuery{ChainID: simChainID, Address: &addr, Event: "ItemSet"})
+	if err != nil || len(logs) != 1 {
+		t.Fatalf("❌ 查询 ItemSet 失败: %v, %d 条", err, len(logs))
+	}
+	if logs[0].Args["key"] != common.Bytes2Hex(k[:]) && logs[0].Args["key"] != "0x"+common.Bytes2Hex(k[:]) {
+		t.Fatalf("❌ 事件参数不符: %v", logs[0].Args)
+	}
+
+	var status int
+	if err := db.SQL().QueryRow(`SELECT status FROM receipts WHERE tx_hash = ?`, logs[0].TxHash.Hex()).Scan(&status); err != nil || status != 1 {
+		t.Fatalf("❌ 收据未写入: %v, status=%d", err, status)
+	}
+}
@@ -0,0 +1,137 @@
+package eventdb
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/decoder"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Backend 是索引器需要的链上查询接口，*ethclient.Client 和模拟链客户端都满足
+type Backend interface {
+	ethereum.LogFilterer
+	HeaderByHash(ctx context.Context, hash common.Hash) (*types.Header, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// Indexer 从链上拉取日志及其所在区块、交易、收据并写入数据库
+type Indexer struct {
+	DB      *DB
+	Backend Backend
+	ChainID *big.Int
+	Decoder *decoder.Decoder
+}
+
+// NewIndexer 创建索引器，dec 为空时使用默认注册表
+func NewIndexer(db *DB, backend Backend, chainID *big.Int, dec *decoder.Decoder) *Indexer {
+	if dec == nil {
+		dec = decoder.New(nil)
+	}
+	return &Indexer{DB: db, Backend: backend, ChainID: chainID, Decoder: dec}
+}
+
+// IndexRange 按 step 分段查询 [from, to] 范围内匹配 addresses 的日志并写入数据库，返回写入的日志数量
+func (ix *Indexer) IndexRange(ctx context.Context, addresses []common.Address, from, to, step uint64) (int, error) {
+	if step == 0 {
+		step = 1000
+	}
+	total := 0
+	for start := from; start <= to; start += step {
+		end := start + step - 1
+		if end > to {
+			end = to
+		}
+		logs, err := ix.Backend.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: addresses,
+		})
+		if err != nil {
+			return total, fmt.Errorf("查询区块 %d ~ %d 日志失败: %w", start, end, err)
+		}
+		if err := ix.Index(ctx, logs); err != nil {
+			return total, err
+		}
+		total += len(logs)
+	}
+	return total, nil
+}
+
+// Index 把一批日志及相关的区块、交易、收据写入数据库（单个事务）
+func (ix *Indexer) Index(ctx context.Context, logs []types.Log) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	tx, err := ix.DB.Begin(ctx, ix.ChainID)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	signer := types.LatestSignerForChainID(ix.ChainID)
+	seenBlocks := make(map[common.Hash]bool)
+	seenTxs := make(map[common.Hash]bool)
+
+	for i := range logs {
+		l := &logs[i]
+		if l.Removed {
+			if err := tx.UpsertLog(ctx, l, "", nil); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !seenBlocks[l.BlockHash] {
+			header, err := ix.Backend.HeaderByHash(ctx, l.BlockHash)
+			if err != nil {
+				return fmt.Errorf("获取区块 %s 失败: %w", l.BlockHash.Hex(), err)
+			}
+			if err := tx.UpsertBlock(ctx, header); err != nil {
+				return err
+			}
+			seenBlocks[l.BlockHash] = true
+		}
+
+		if !seenTxs[l.TxHash] {
+			if err := ix.indexTransaction(ctx, tx, signer, l); err != nil {
+				return err
+			}
+			seenTxs[l.TxHash] = true
+		}
+
+		var name string
+		var args map[string]interface{}
+		if event, err := ix.Decoder.DecodeLog(l); err == nil {
+			name, args = event.Name, event.Plain()
+		}
+		if err := tx.UpsertLog(ctx, l, name, args); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (ix *Indexer) indexTransaction(ctx context.Context, tx *Tx, signer types.Signer, l *types.Log) error {
+	transaction, _, err := ix.Backend.TransactionByHash(ctx, l.TxHash)
+	if err != nil {
+		return fmt.Errorf("获取交易 %s 失败: %w", l.TxHash.Hex(), err)
+	}
+	from, err := types.Sender(signer, transaction)
+	if err != nil {
+		return fmt.Errorf("恢复交易 %s 发送者失败: %w", l.TxHash.Hex(), err)
+	}
+	if err := tx.UpsertTransaction(ctx, transaction, from, l.BlockHash, l.BlockNumber, l.TxIndex); err != nil {
+		return err
+	}
+
+	receipt, err := ix.Backend.TransactionReceipt(ctx, l.TxHash)
+	if err != nil {
+		return fmt.Errorf("获取交易 %s 收据失败: %w", l.TxHash.Hex(), err)
+	}
+	return tx.UpsertReceipt(ctx, receipt)
+}
@@ -0,0 +1,200 @@
+package eventdb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TransferTopic 是 ERC20 Transfer(address,address,uint256) 的事件签名哈希
+var TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// TransferQuery 描述 ERC20 转账查询条件，From/To 为空表示不限制，ToBlock 为 0 表示不限制上界
+type TransferQuery struct {
+	ChainID   *big.Int
+	Token     common.Address
+	From      *common.Address
+	To        *common.Address
+	FromBlock uint64
+	ToBlock   uint64
+}
+
+// Transfer 是一条 ERC20 转账记录
+type Transfer struct {
+	BlockNumber uint64         `json:"blockNumber"`
+	BlockHash   common.Hash    `json:"blockHash"`
+	TxHash      common.Hash    `json:"txHash"`
+	LogIndex    uint           `json:"logIndex"`
+	From        common.Address `json:"from"`
+	To          common.Address `json:"to"`
+	Value       *big.Int       `json:"value"`
+}
+
+// Transfers 查询代币的转账记录，例如“区块 A 到 B 之间转给地址 X 的所有 IWS 转账”
+func (d *DB) Transfers(ctx context.Context, q TransferQuery) ([]Transfer, error) {
+	// ERC721 的 Transfer 签名相同，但 tokenId 也是 indexed（4 个 topic、data 为空）。
+	// 只取 3 个 topic 且 data 为一个 uint256（"0x" + 64 位十六进制）的日志
+	where := []string{"chain_id = ?", "address = ?", "topic0 = ?", "block_number >= ?",
+		"topic2 IS NOT NULL", "topic3 IS NULL", "length(data) = 66"}
+	args := []interface{}{q.ChainID.Int64(), q.Token.Hex(), TransferTopic.Hex(), int64(q.FromBlock)}
+	if q.ToBlock != 0 {
+		where = append(where, "block_number <= ?")
+		args = append(args, int64(q.ToBlock))
+	}
+	if q.From != nil {
+		where = append(where, "topic1 = ?")
+		args = append(args, common.BytesToHash(q.From.Bytes()).Hex())
+	}
+	if q.To != nil {
+		where = append(where, "topic2 = ?")
+		args = append(args, common.BytesToHash(q.To.Bytes()).Hex())
+	}
+
+	rows, err := d.db.QueryContext(ctx,
+		`SELECT block_number, block_hash, tx_hash, log_index, topic1, topic2, data FROM logs
+		 WHERE `+strings.Join(where, " AND ")+` ORDER BY block_number, log_index`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询转账记录失败: %w", err)
+	}
+	defer rows.Close()
+
+	var transfers []Transfer
+	for rows.Next() {
+		var (
+			number, index           int64
+			blockHash, txHash       string
+			topic1, topic2, dataHex string
+		)
+		if err := rows.Scan(&number, &blockHash, &txHash, &index, &topic1, &topic2, &dataHex); err != nil {
+			return nil, err
+		}
+		data, err := hexutil.Decode(dataHex)
+		if err != nil {
+			return nil, fmt.Errorf("日志数据格式错误: %w", err)
+		}
+		transfers = append(transfers, Transfer{
+			BlockNumber: uint64(number),
+			BlockHash:   common.HexToHash(blockHash),
+			TxHash:      common.HexToHash(txHash),
+			LogIndex:    uint(index),
+			From:        common.BytesToAddress(common.HexToHash(topic1).Bytes()),
+			To:          common.BytesToAddress(common.HexToHash(topic2).Bytes()),
+			Value:       new(big.Int).SetBytes(data),
+		})
+	}
+	return transfers, rows.Err()
+}
+
+// LogQuery 描述通用日志查询条件，Address 为空表示所有合约，Event 为空表示所有事件
+type LogQuery struct {
+	ChainID   *big.Int
+	Address   *common.Address
+	Event     string
+	TxHash    *common.Hash
+	FromBlock uint64
+	ToBlock   uint64
+	Limit     int
+}
+
+// StoredLog 是数据库中的一条日志
+type StoredLog struct {
+	BlockNumber uint64                 `json:"blockNumber"`
+	BlockHash   common.Hash            `json:"blockHash"`
+	TxHash      common.Hash            `json:"txHash"`
+	LogIndex    uint                   `json:"logIndex"`
+	Address     common.Address         `json:"address"`
+	Topics      []common.Hash          `json:"topics"`
+	Data        hexutil.Bytes          `json:"data"`
+	Event       string                 `json:"event,omitempty"`
+	Args        map[string]interface{} `json:"args,omitempty"`
+}
+
+// Logs 按条件查询日志，按区块高度和日志索引排序
+func (d *DB) Logs(ctx context.Context, q LogQuery) ([]StoredLog, error) {
+	where := []string{"chain_id = ?", "block_number >= ?"}
+	args := []interface{}{q.ChainID.Int64(), int64(q.FromBlock)}
+	if q.ToBlock != 0 {
+		where = append(where, "block_number <= ?")
+		args = append(args, int64(q.ToBlock))
+	}
+	if q.Address != nil {
+		where = append(where, "address = ?")
+		args = append(args, q.Address.Hex())
+	}
+	if q.Event != "" {
+		where = append(where, "event = ?")
+		args = append(args, q.Event)
+	}
+	if q.TxHash != nil {
+		where = append(where, "tx_hash = ?")
+		args = append(args, q.TxHash.Hex())
+	}
+	query := `SELECT block_number, block_hash, tx_hash, log_index, address, topic0, topic1, topic2, topic3, data, event, args
+		FROM logs WHERE ` + strings.Join(where, " AND ") + ` ORDER BY block_number, log_index`
+	if q.Limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", q.Limit)
+	}
+
+	rows, err := d.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询日志失败: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []StoredLog
+	for rows.Next() {
+		var (
+			number, index              int64
+			blockHash, txHash, address string
+			topics                     [4]*string
+			dataHex                    string
+			event, argsJSON            *string
+		)
+		if err := rows.Scan(&number, &blockHash, &txHash, &index, &address,
+			&topics[0], &topics[1], &topics[2], &topics[3], &dataHex, &event, &argsJSON); err != nil {
+			return nil, err
+		}
+		l := StoredLog{
+			BlockNumber: uint64(number),
+			BlockHash:   common.HexToHash(blockHash),
+			TxHash:      common.HexToHash(txHash),
+			LogIndex:    uint(index),
+			Address:     common.HexToAddress(address),
+			Data:        common.FromHex(dataHex),
+		}
+		for _, topic := range topics {
+			if topic != nil {
+				l.Topics = append(l.Topics, common.HexToHash(*topic))
+			}
+		}
+		if event != nil {
+			l.Event = *event
+		}
+		if argsJSON != nil {
+			if err := json.Unmarshal([]byte(*argsJSON), &l.Args); err != nil {
+				return nil, fmt.Errorf("事件参数格式错误: %w", err)
+			}
+		}
+		logs = append(logs, l)
+	}
+	return logs, rows.Err()
+}
+
+// LatestBlock 返回数据库中该链的最高区块号，没有数据时返回 0, false
+func (d *DB) LatestBlock(ctx context.Context, chainID *big.Int) (uint64, bool, error) {
+	var number *int64
+	err := d.db.QueryRowContext(ctx, `SELECT MAX(number) FROM blocks WHERE chain_id = ?`, chainID.Int64()).Scan(&number)
+	if err != nil {
+		return 0, false, fmt.Errorf("查询最新区块失败: %w", err)
+	}
+	if number == nil {
+		return 0, false, nil
+	}
+	return uint64(*number), true, nil
+}
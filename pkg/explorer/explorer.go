@@ -0,0 +1,597 @@
+// Package explorer 提供只读的本地区块浏览器 HTTP 服务，数据来自 RPC 节点和可选的本地事件索引
+package explorer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/decoder"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/eventdb"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/ledger"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Backend 是浏览器需要的链上查询接口，*ethclient.Client 和模拟链客户端都满足
+type Backend interface {
+	ethereum.LogFilterer
+	ChainID(ctx context.Context) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	BlockByHash(ctx context.Context, hash common.Hash) (*types.Block, error)
+	TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error)
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+}
+
+// DefaultMaxRange 是未配置索引时单次请求默认允许扫描的区块数
+const DefaultMaxRange = 10_000
+
+// errBadRequest 表示请求参数错误，映射为 HTTP 400
+var errBadRequest = errors.New("请求参数错误")
+
+// Server 是区块浏览器 HTTP 服务，DB 为空时所有查询直接走 RPC
+type Server struct {
+	Backend  Backend
+	DB       *eventdb.DB
+	Decoder  *decoder.Decoder
+	MaxRange uint64 // 未配置索引时单次请求通过 eth_getLogs 扫描的最大区块数，0 表示 DefaultMaxRange
+
+	mux     *http.ServeMux
+	mu      sync.Mutex
+	chainID *big.Int
+}
+
+// New 创建浏览器服务，db 可为空，dec 为空时使用默认注册表
+func New(backend Backend, db *eventdb.DB, dec *decoder.Decoder) *Server {
+	if dec == nil {
+		dec = decoder.New(nil)
+	}
+	s := &Server{Backend: backend, DB: db, Decoder: dec, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /{$}", s.handle(s.index))
+	s.mux.HandleFunc("GET /block/{id}", s.handle(s.block))
+	s.mux.HandleFunc("GET /tx/{hash}", s.handle(s.tx))
+	s.mux.HandleFunc("GET /tx/{hash}/logs", s.handle(s.txLogs))
+	s.mux.HandleFunc("GET /address/{addr}", s.handle(s.address))
+	s.mux.HandleFunc("GET /token/{addr}/holders", s.handle(s.holders))
+	s.mux.HandleFunc("GET /logs", s.handle(s.logs))
+	return s
+}
+
+// ServeHTTP 实现 http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// page 是一个页面的数据，Title 只在 HTML 输出时使用
+type page struct {
+	Title string
+	Data  interface{}
+}
+
+func (s *Server) handle(fn func(r *http.Request) (*page, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, err := fn(r)
+		if err != nil {
+			writeError(w, r, statusOf(err), err)
+			return
+		}
+		write(w, r, http.StatusOK, p)
+	}
+}
+
+func statusOf(err error) int {
+	switch {
+	case errors.Is(err, errBadRequest):
+		return http.StatusBadRequest
+	case errors.Is(err, ethereum.NotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+func (s *Server) getChainID(ctx context.Context) (*big.Int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.chainID == nil {
+		id, err := s.Backend.ChainID(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取 Chain ID 失败: %w", err)
+		}
+		s.chainID = id
+	}
+	return s.chainID, nil
+}
+
+// Summary 是首页数据
+type Summary struct {
+	ChainID     *big.Int `json:"chainId"`
+	LatestBlock uint64   `json:"latestBlock"`
+	Indexed     *uint64  `json:"indexedBlock,omitempty"`
+}
+
+func (s *Server) index(r *http.Request) (*page, error) {
+	ctx := r.Context()
+	chainID, err := s.getChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	head, err := s.Backend.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	sum := Summary{ChainID: chainID, LatestBlock: head}
+	if s.DB != nil {
+		if n, ok, err := s.DB.LatestBlock(ctx, chainID); err == nil && ok {
+			sum.Indexed = &n
+		}
+	}
+	return &page{Title: "IWS 区块浏览器", Data: sum}, nil
+}
+
+// Block 是区块详情
+type Block struct {
+	Number       uint64         `json:"number"`
+	Hash         common.Hash    `json:"hash"`
+	ParentHash   common.Hash    `json:"parentHash"`
+	Time         uint64         `json:"time"`
+	Miner        common.Address `json:"miner"`
+	GasUsed      uint64         `json:"gasUsed"`
+	GasLimit     uint64         `json:"gasLimit"`
+	BaseFee      *big.Int       `json:"baseFee,omitempty"`
+	Transactions []common.Hash  `json:"transactions"`
+}
+
+func (s *Server) block(r *http.Request) (*page, error) {
+	id := r.PathValue("id")
+	var (
+		b   *types.Block
+		err error
+	)
+	switch {
+	case id == "latest":
+		b, err = s.Backend.BlockByNumber(r.Context(), nil)
+	case strings.HasPrefix(id, "0x") && len(id) == 66:
+		b, err = s.Backend.BlockByHash(r.Context(), common.HexToHash(id))
+	default:
+		n, perr := parseUint(id)
+		if perr != nil {
+			return nil, perr
+		}
+		b, err = s.Backend.BlockByNumber(r.Context(), new(big.Int).SetUint64(n))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询区块 %s 失败: %w", id, err)
+	}
+
+	out := Block{
+		Number:       b.NumberU64(),
+		Hash:         b.Hash(),
+		ParentHash:   b.ParentHash(),
+		Time:         b.Time(),
+		Miner:        b.Coinbase(),
+		GasUsed:      b.GasUsed(),
+		GasLimit:     b.GasLimit(),
+		BaseFee:      b.BaseFee(),
+		Transactions: make([]common.Hash, 0, len(b.Transactions())),
+	}
+	for _, tx := range b.Transactions() {
+		out.Transactions = append(out.Transactions, tx.Hash())
+	}
+	return &page{Title: fmt.Sprintf("区块 #%d", out.Number), Data: out}, nil
+}
+
+// Transaction 是交易详情，包含解码后的 calldata 和收据
+type Transaction struct {
+	Hash     common.Hash     `json:"hash"`
+	Pending  bool            `json:"pending"`
+	Type     uint8           `json:"type"`
+	From     common.Address  `json:"from"`
+	To       *common.Address `json:"to"`
+	Label    string          `json:"label,omitempty"`
+	Nonce    uint64          `json:"nonce"`
+	Value    *big.Int        `json:"value"`
+	Gas      uint64          `json:"gas"`
+	GasPrice *big.Int        `json:"gasPrice"`
+	Input    hexutil.Bytes   `json:"input"`
+	Call     *decoder.Call   `json:"call,omitempty"`
+	Receipt  *Receipt        `json:"receipt,omitempty"`
+}
+
+// Receipt 是交易收据
+type Receipt struct {
+	Status            uint64          `json:"status"`
+	BlockNumber       uint64          `json:"blockNumber"`
+	BlockHash         common.Hash     `json:"blockHash"`
+	GasUsed           uint64          `json:"gasUsed"`
+	EffectiveGasPrice *big.Int        `json:"effectiveGasPrice,omitempty"`
+	ContractAddress   *common.Address `json:"contractAddress,omitempty"`
+	Logs              []Log           `json:"logs"`
+}
+
+// Log 是解码后的事件日志
+type Log struct {
+	BlockNumber uint64                 `json:"blockNumber"`
+	TxHash      common.Hash            `json:"txHash"`
+	Index       uint                   `json:"logIndex"`
+	Address     common.Address         `json:"address"`
+	Label       string                 `json:"label,omitempty"`
+	Topics      []common.Hash          `json:"topics"`
+	Data        hexutil.Bytes          `json:"data"`
+	Event       string                 `json:"event,omitempty"`
+	Signature   string                 `json:"signature,omitempty"`
+	Args        map[string]interface{} `json:"args,omitempty"`
+}
+
+func (s *Server) decodeLog(l *types.Log) Log {
+	out := Log{
+		BlockNumber: l.BlockNumber,
+		TxHash:      l.TxHash,
+		Index:       l.Index,
+		Address:     l.Address,
+		Label:       s.Decoder.Registry().Label(l.Address),
+		Topics:      l.Topics,
+		Data:        l.Data,
+	}
+	if ev, err := s.Decoder.DecodeLog(l); err == nil {
+		out.Event, out.Signature, out.Args = ev.Name, ev.Signature, ev.Plain()
+	}
+	return out
+}
+
+func (s *Server) tx(r *http.Request) (*page, error) {
+	ctx := r.Context()
+	hash, err := parseHash(r.PathValue("hash"))
+	if err != nil {
+		return nil, err
+	}
+	tx, pending, err := s.Backend.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, fmt.Errorf("查询交易失败: %w", err)
+	}
+	chainID, err := s.getChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainID), tx)
+	if err != nil {
+		return nil, fmt.Errorf("恢复发送方失败: %w", err)
+	}
+
+	out := Transaction{
+		Hash:     hash,
+		Pending:  pending,
+		Type:     tx.Type(),
+		From:     from,
+		To:       tx.To(),
+		Nonce:    tx.Nonce(),
+		Value:    tx.Value(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Input:    tx.Data(),
+	}
+	if tx.To() != nil {
+		out.Label = s.Decoder.Registry().Label(*tx.To())
+		if len(tx.Data()) > 0 {
+			out.Call = s.Decoder.Decode(tx.To(), tx.Data())
+		}
+	}
+	if !pending {
+		receipt, err := s.Backend.TransactionReceipt(ctx, hash)
+		if err != nil {
+			return nil, fmt.Errorf("查询收据失败: %w", err)
+		}
+		out.Receipt = s.receipt(receipt)
+	}
+	return &page{Title: "交易 " + hash.Hex(), Data: out}, nil
+}
+
+func (s *Server) receipt(r *types.Receipt) *Receipt {
+	out := &Receipt{
+		Status:            r.Status,
+		BlockNumber:       r.BlockNumber.Uint64(),
+		BlockHash:         r.BlockHash,
+		GasUsed:           r.GasUsed,
+		EffectiveGasPrice: r.EffectiveGasPrice,
+		Logs:              make([]Log, 0, len(r.Logs)),
+	}
+	if r.ContractAddress != (common.Address{}) {
+		out.ContractAddress = &r.ContractAddress
+	}
+	for _, l := range r.Logs {
+		out.Logs = append(out.Logs, s.decodeLog(l))
+	}
+	return out
+}
+
+func (s *Server) txLogs(r *http.Request) (*page, error) {
+	hash, err := parseHash(r.PathValue("hash"))
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := s.Backend.TransactionReceipt(r.Context(), hash)
+	if err != nil {
+		return nil, fmt.Errorf("查询收据失败: %w", err)
+	}
+	return &page{Title: "交易日志 " + hash.Hex(), Data: s.receipt(receipt).Logs}, nil
+}
+
+// Account 是地址详情
+type Account struct {
+	Address    common.Address `json:"address"`
+	Label      string         `json:"label,omitempty"`
+	Block      uint64         `json:"block"`
+	Balance    *big.Int       `json:"balance"`
+	Nonce      uint64         `json:"nonce"`
+	IsContract bool           `json:"isContract"`
+	CodeSize   int            `json:"codeSize"`
+	RecentLogs []Log          `json:"recentLogs,omitempty"`
+}
+
+func (s *Server) address(r *http.Request) (*page, error) {
+	ctx := r.Context()
+	addr, err := parseAddress(r.PathValue("addr"))
+	if err != nil {
+		return nil, err
+	}
+	head, err := s.Backend.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	at := new(big.Int).SetUint64(head)
+
+	out := Account{Address: addr, Label: s.Decoder.Registry().Label(addr), Block: head}
+	if out.Balance, err = s.Backend.BalanceAt(ctx, addr, at); err != nil {
+		return nil, fmt.Errorf("查询余额失败: %w", err)
+	}
+	if out.Nonce, err = s.Backend.NonceAt(ctx, addr, at); err != nil {
+		return nil, fmt.Errorf("查询 nonce 失败: %w", err)
+	}
+	code, err := s.Backend.CodeAt(ctx, addr, at)
+	if err != nil {
+		return nil, fmt.Errorf("查询合约代码失败: %w", err)
+	}
+	out.CodeSize, out.IsContract = len(code), len(code) > 0
+
+	// 有本地索引时附带该合约最近的事件
+	if s.DB != nil && out.IsContract {
+		chainID, err := s.getChainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		stored, err := s.DB.Logs(ctx, eventdb.LogQuery{ChainID: chainID, Address: &addr, Limit: 20})
+		if err != nil {
+			return nil, err
+		}
+		out.RecentLogs = s.storedLogs(stored)
+	}
+	return &page{Title: "地址 " + addr.Hex(), Data: out}, nil
+}
+
+// Holders 是代币持有人列表
+type Holders struct {
+	Token     common.Address  `json:"token"`
+	Block     uint64          `json:"block"`
+	Source    string          `json:"source"` // index 或 rpc
+	Transfers int             `json:"transfers"`
+	Holders   []ledger.Holder `json:"holders"`
+}
+
+func (s *Server) holders(r *http.Request) (*page, error) {
+	ctx := r.Context()
+	token, err := parseAddress(r.PathValue("addr"))
+	if err != nil {
+		return nil, err
+	}
+	q := r.URL.Query()
+	from, err := queryUint(q.Get("from"), 0)
+	if err != nil {
+		return nil, err
+	}
+	head, err := s.Backend.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	at, err := queryUint(q.Get("block"), head)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.DB == nil {
+		// 没有索引时逐段 eth_getLogs，必须显式给出起点并限制跨度，避免每个请求都从创世块扫到最新区块
+		if q.Get("from") == "" {
+			return nil, fmt.Errorf("%w: 未配置本地索引时必须指定 from", errBadRequest)
+		}
+		if err := s.checkRange(from, at); err != nil {
+			return nil, err
+		}
+	}
+
+	out := Holders{Token: token, Block: at}
+	var transfers []ledger.Transfer
+	if s.DB != nil {
+		chainID, err := s.getChainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		stored, err := s.DB.Transfers(ctx, eventdb.TransferQuery{ChainID: chainID, Token: token, FromBlock: from, ToBlock: at})
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range stored {
+			transfers = append(transfers, ledger.Transfer{
+				BlockNumber: t.BlockNumber, TxHash: t.TxHash, LogIndex: t.LogIndex, From: t.From, To: t.To, Value: t.Value,
+			})
+		}
+		out.Source = "index"
+	} else {
+		if transfers, err = ledger.Fetch(ctx, s.Backend, token, from, at, 0); err != nil {
+			return nil, err
+		}
+		out.Source = "rpc"
+	}
+
+	l := ledger.New(token)
+	for _, t := range transfers {
+		if err := l.Apply(t); err != nil {
+			return nil, err
+		}
+	}
+	out.Transfers = len(transfers)
+	out.Holders = l.Holders(at)
+	if out.Holders == nil {
+		out.Holders = []ledger.Holder{}
+	}
+	return &page{Title: "代币持有人 " + token.Hex(), Data: out}, nil
+}
+
+// logs 查询解码后的日志：有本地索引时查数据库，否则通过 eth_getLogs 查询（必须指定 address）
+func (s *Server) logs(r *http.Request) (*page, error) {
+	ctx := r.Context()
+	q := r.URL.Query()
+	from, err := queryUint(q.Get("from"), 0)
+	if err != nil {
+		return nil, err
+	}
+	to, err := queryUint(q.Get("to"), 0)
+	if err != nil {
+		return nil, err
+	}
+	limit, err := queryUint(q.Get("limit"), 100)
+	if err != nil {
+		return nil, err
+	}
+	var addr *common.Address
+	if v := q.Get("address"); v != "" {
+		a, err := parseAddress(v)
+		if err != nil {
+			return nil, err
+		}
+		addr = &a
+	}
+	event := q.Get("event")
+
+	if s.DB != nil {
+		chainID, err := s.getChainID(ctx)
+		if err != nil {
+			return nil, err
+		}
+		stored, err := s.DB.Logs(ctx, eventdb.LogQuery{
+			ChainID: chainID, Address: addr, Event: event, FromBlock: from, ToBlock: to, Limit: int(limit),
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &page{Title: "事件日志", Data: s.storedLogs(stored)}, nil
+	}
+
+	if addr == nil {
+		return nil, fmt.Errorf("%w: 未配置本地索引时必须指定 address", errBadRequest)
+	}
+	if to == 0 {
+		head, err := s.Backend.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取最新区块失败: %w", err)
+		}
+		to = head
+	}
+	if err := s.checkRange(from, to); err != nil {
+		return nil, err
+	}
+	fq := ethereum.FilterQuery{Addresses: []common.Address{*addr}, FromBlock: new(big.Int).SetUint64(from), ToBlock: new(big.Int).SetUint64(to)}
+	raw, err := s.Backend.FilterLogs(ctx, fq)
+	if err != nil {
+		return nil, fmt.Errorf("查询日志失败: %w", err)
+	}
+	out := []Log{}
+	for i := range raw {
+		l := s.decodeLog(&raw[i])
+		if event != "" && l.Event != event {
+			continue
+		}
+		if out = append(out, l); uint64(len(out)) >= limit {
+			break
+		}
+	}
+	return &page{Title: "事件日志", Data: out}, nil
+}
+
+// checkRange 检查直接走 RPC 的区块范围不超过 MaxRange
+func (s *Server) checkRange(from, to uint64) error {
+	limit := s.MaxRange
+	if limit == 0 {
+		limit = DefaultMaxRange
+	}
+	if from > to {
+		return fmt.Errorf("%w: 起始区块 %d 大于结束区块 %d", errBadRequest, from, to)
+	}
+	if to-from+1 > limit {
+		return fmt.Errorf("%w: 区块范围 %d-%d 超过 %d 个区块，请缩小范围或配置本地索引", errBadRequest, from, to, limit)
+	}
+	return nil
+}
+
+func (s *Server) storedLogs(stored []eventdb.StoredLog) []Log {
+	out := make([]Log, 0, len(stored))
+	for _, l := range stored {
+		out = append(out, Log{
+			BlockNumber: l.BlockNumber,
+			TxHash:      l.TxHash,
+			Index:       l.LogIndex,
+			Address:     l.Address,
+			Label:       s.Decoder.Registry().Label(l.Address),
+			Topics:      l.Topics,
+			Data:        l.Data,
+			Event:       l.Event,
+			Args:        l.Args,
+		})
+	}
+	return out
+}
+
+func parseUint(s string) (uint64, error) {
+	if strings.HasPrefix(s, "0x") {
+		n, err := hexutil.DecodeUint64(s)
+		if err != nil {
+			return 0, fmt.Errorf("%w: 无效的数字 %s", errBadRequest, s)
+		}
+		return n, nil
+	}
+	n, err := strconv.ParseUint(s, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: 无效的数字 %s", errBadRequest, s)
+	}
+	return n, nil
+}
+
+func queryUint(s string, def uint64) (uint64, error) {
+	if s == "" {
+		return def, nil
+	}
+	return parseUint(s)
+}
+
+func parseHash(s string) (common.Hash, error) {
+	if len(s) != 66 || !strings.HasPrefix(s, "0x") {
+		return common.Hash{}, fmt.Errorf("%w: 无效的哈希 %s", errBadRequest, s)
+	}
+	return common.HexToHash(s), nil
+}
+
+func parseAddress(s string) (common.Address, error) {
+	if !common.IsHexAddress(s) {
+		return common.Address{}, fmt.Errorf("%w: 无效的地址 %s", errBadRequest, s)
+	}
+	return common.HexToAddress(s), nil
+}
@@ -0,0 +1,193 @@
+package explorer
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/eventdb"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient/simulated"
+)
+
+// chain 是部署了 Store 并调用过一次 setItem 的模拟链
+type chain struct {
+	backend *simulated.Backend
+	owner   common.Address
+	store   common.Address
+	setTx   common.Hash
+}
+
+func newChain(t *testing.T) *chain {
+	key, _ := crypto.GenerateKey()
+	owner := crypto.PubkeyToAddress(key.PublicKey)
+	backend := simulated.NewBackend(types.GenesisAlloc{owner: {Balance: big.NewInt(1e18)}})
+	t.Cleanup(func() { backend.Close() })
+
+	client := backend.Client()
+	chainID, _ := client.ChainID(context.Background())
+	auth, _ := bind.NewKeyedTransactorWithChainID(key, chainID)
+	addr, _, instance, err := store.DeployStore(auth, client, "v1.0.0")
+	if err != nil {
+		t.Fatalf("❌ 部署 Store 失败: %v", err)
+	}
+	backend.Commit()
+
+	var k, v [32]byte
+	copy(k[:], "mykey")
+	copy(v[:], "myvalue")
+	tx, err := instance.SetItem(auth, k, v)
+	if err != nil {
+		t.Fatalf("❌ SetItem 失败: %v", err)
+	}
+	backend.Commit()
+	return &chain{backend: backend, owner: owner, store: addr, setTx: tx.Hash()}
+}
+
+func get(t *testing.T, srv *httptest.Server, path string, want int, out interface{}) string {
+	t.Helper()
+	resp, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatalf("❌ 请求 %s 失败: %v", path, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != want {
+		t.Fatalf("❌ %s 状态码 %d，期望 %d: %s", path, resp.StatusCode, want, body)
+	}
+	if out != nil {
+		if err := json.Unmarshal(body, out); err != nil {
+			t.Fatalf("❌ 解析 %s 响应失败: %v\n%s", path, err, body)
+		}
+	}
+	return string(body)
+}
+
+// 测试区块、交易、地址、日志接口（直接走 RPC）
+func TestExplorerRPC(t *testing.T) {
+	c := newChain(t)
+	srv := httptest.NewServer(New(c.backend.Client(), nil, nil))
+	defer srv.Close()
+
+	var tx Transaction
+	get(t, srv, "/tx/"+c.setTx.Hex(), http.StatusOK, &tx)
+	if tx.From != c.owner || tx.Call == nil || tx.Call.Method != "setItem" {
+		t.Fatalf("❌ 交易解码不符: from=%s call=%+v", tx.From.Hex(), tx.Call)
+	}
+	if tx.Receipt == nil || tx.Receipt.Status != 1 || len(tx.Receipt.Logs) != 1 || tx.Receipt.Logs[0].Event != "ItemSet" {
+		t.Fatalf("❌ 收据或日志解码不符: %+v", tx.Receipt)
+	}
+	t.Logf("✅ 交易 %s 解码为 %s", tx.Hash.Hex(), tx.Call.Signature)
+
+	var byNumber, byHash Block
+	get(t, srv, "/block/2", http.StatusOK, &byNumber)
+	get(t, srv, "/block/"+byNumber.Hash.Hex(), http.StatusOK, &byHash)
+	if len(byNumber.Transactions) != 1 || byNumber.Transactions[0] != c.setTx || byHash.Number != 2 {
+		t.Fatalf("❌ 区块查询不符: %+v / %+v", byNumber, byHash)
+	}
+
+	var account Account
+	get(t, srv, "/address/"+c.store.Hex(), http.StatusOK, &account)
+	if !account.IsContract || account.CodeSize == 0 {
+		t.Fatalf("❌ Store 应为合约: %+v", account)
+	}
+
+	var logs []Log
+	get(t, srv, "/logs?address="+c.store.Hex()+"&event=ItemSet", http.StatusOK, &logs)
+	if len(logs) != 1 || logs[0].TxHash != c.setTx || logs[0].Args["key"] == nil {
+		t.Fatalf("❌ 日志查询不符: %+v", logs)
+	}
+
+	// 错误处理
+	get(t, srv, "/tx/0x"+strings.Repeat("ab", 32), http.StatusNotFound, nil)
+	get(t, srv, "/address/not-an-address", http.StatusBadRequest, nil)
+	get(t, srv, "/logs", http.StatusBadRequest, nil)
+
+	// 没有索引时持有人接口必须指定 from，且扫描范围受 MaxRange 限制
+	get(t, srv, "/token/"+c.store.Hex()+"/holders", http.StatusBadRequest, nil)
+	var holders Holders
+	get(t, srv, "/token/"+c.store.Hex()+"/holders?from=0", http.StatusOK, &holders)
+	if holders.Source != "rpc" {
+		t.Fatalf("❌ 未配置索引时应通过 RPC 查询: %+v", holders)
+	}
+	limited := New(c.backend.Client(), nil, nil)
+	limited.MaxRange = 1
+	small := httptest.NewServer(limited)
+	defer small.Close()
+	get(t, small, "/token/"+c.store.Hex()+"/holders?from=0", http.StatusBadRequest, nil)
+	get(t, small, "/logs?address="+c.store.Hex()+"&from=0", http.StatusBadRequest, nil)
+
+	// HTML 输出带链接
+	html := get(t, srv, "/tx/"+c.setTx.Hex()+"?format=html", http.StatusOK, nil)
+	if !strings.Contains(html, `<a href="/address/`+c.owner.Hex()+`">`) || !strings.Contains(html, "setItem") {
+		t.Fatalf("❌ HTML 输出缺少链接或解码结果:\n%s", html)
+	}
+}
+
+// 测试基于本地索引的持有人接口
+func TestExplorerHolders(t *testing.T) {
+	ctx := context.Background()
+	c := newChain(t)
+	client := c.backend.Client()
+	chainID, _ := client.ChainID(ctx)
+
+	db, err := eventdb.Open(":memory:")
+	if err != nil {
+		t.Fatalf("❌ 打开数据库失败: %v", err)
+	}
+	defer db.Close()
+
+	token := common.HexToAddress("0xE5aFC41736bBE96cCB912Cb2d2e6BB503979b657")
+	alice := common.HexToAddress("0x8c8aB9B6178877246B224F8D745A1410C4928373")
+	header, _ := client.HeaderByNumber(ctx, big.NewInt(1))
+	transfer := func(index uint, from, to common.Address, value int64) *types.Log {
+		return &types.Log{
+			Address:     token,
+			Topics:      []common.Hash{eventdb.TransferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+			Data:        common.LeftPadBytes(big.NewInt(value).Bytes(), 32),
+			BlockNumber: 1,
+			BlockHash:   header.Hash(),
+			TxHash:      common.BigToHash(big.NewInt(int64(index) + 1)),
+			Index:       index,
+		}
+	}
+
+	tx, _ := db.Begin(ctx, chainID)
+	tx.UpsertBlock(ctx, header)
+	for _, l := range []*types.Log{
+		transfer(0, common.Address{}, c.owner, 1000),
+		transfer(1, c.owner, alice, 400),
+	} {
+		if err := tx.UpsertLog(ctx, l, "Transfer", nil); err != nil {
+			t.Fatalf("❌ 写入日志失败: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("❌ 提交失败: %v", err)
+	}
+
+	srv := httptest.NewServer(New(client, db, nil))
+	defer srv.Close()
+
+	var holders Holders
+	get(t, srv, "/token/"+token.Hex()+"/holders", http.StatusOK, &holders)
+	if holders.Source != "index" || holders.Transfers != 2 || len(holders.Holders) != 2 ||
+		holders.Holders[0].Address != c.owner || holders.Holders[0].Balance.Int64() != 600 {
+		t.Fatalf("❌ 持有人不符: %+v", holders)
+	}
+
+	var sum Summary
+	get(t, srv, "/", http.StatusOK, &sum)
+	if sum.Indexed == nil || *sum.Indexed != 1 {
+		t.Fatalf("❌ 首页索引高度不符: %+v", sum)
+	}
+}
@@ -0,0 +1,152 @@
+package explorer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"html/template"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// wantsHTML 判断是否以 HTML 输出：?format=html 或浏览器请求（Accept 以 text/html 开头）
+func wantsHTML(r *http.Request) bool {
+	switch r.URL.Query().Get("format") {
+	case "html":
+		return true
+	case "json":
+		return false
+	}
+	return strings.HasPrefix(r.Header.Get("Accept"), "text/html")
+}
+
+func write(w http.ResponseWriter, r *http.Request, status int, p *page) {
+	if wantsHTML(r) {
+		writeHTML(w, status, p)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(p.Data)
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	write(w, r, status, &page{Title: "错误", Data: map[string]string{"error": err.Error()}})
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="zh-CN">
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: ui-monospace, Menlo, monospace; margin: 2em; color: #222; }
+table { border-collapse: collapse; margin: 0.2em 0; }
+td { border: 1px solid #ddd; padding: 0.2em 0.6em; vertical-align: top; }
+td.k { color: #666; white-space: nowrap; }
+ol { margin: 0; padding-left: 1.6em; }
+a { color: #0b5fff; text-decoration: none; }
+</style>
+</head>
+<body>
+<p><a href="/">首页</a> · <a href="/block/latest">最新区块</a></p>
+<h2>{{.Title}}</h2>
+{{.Body}}
+</body>
+</html>
+`))
+
+func writeHTML(w http.ResponseWriter, status int, p *page) {
+	// 先序列化为 JSON 再渲染，保证 HTML 与 JSON 输出的字段一致
+	raw, err := json.Marshal(p.Data)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	var data interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var body strings.Builder
+	renderHTML(&body, "", data)
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	pageTemplate.Execute(w, struct {
+		Title string
+		Body  template.HTML
+	}{p.Title, template.HTML(body.String())})
+}
+
+var (
+	addressPattern = regexp.MustCompile(`^0x[0-9a-fA-F]{40}$`)
+	hashPattern    = regexp.MustCompile(`^0x[0-9a-fA-F]{64}$`)
+)
+
+// renderHTML 把 JSON 值渲染为嵌套表格，地址、交易哈希、区块号等渲染为链接
+func renderHTML(b *strings.Builder, key string, v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		b.WriteString("<table>")
+		for _, k := range keys {
+			fmt.Fprintf(b, `<tr><td class="k">%s</td><td>`, html.EscapeString(k))
+			renderHTML(b, k, v[k])
+			b.WriteString("</td></tr>")
+		}
+		b.WriteString("</table>")
+	case []interface{}:
+		if len(v) == 0 {
+			b.WriteString("—")
+			return
+		}
+		b.WriteString("<ol start=\"0\">")
+		for _, item := range v {
+			b.WriteString("<li>")
+			renderHTML(b, key, item)
+			b.WriteString("</li>")
+		}
+		b.WriteString("</ol>")
+	case nil:
+		b.WriteString("—")
+	default:
+		s := fmt.Sprint(v)
+		if addressPattern.MatchString(s) {
+			s = common.HexToAddress(s).Hex() // JSON 中地址为小写，HTML 中显示校验和格式
+		}
+		if href := link(key, s); href != "" {
+			fmt.Fprintf(b, `<a href="%s">%s</a>`, html.EscapeString(href), html.EscapeString(s))
+			return
+		}
+		b.WriteString(html.EscapeString(s))
+	}
+}
+
+// link 根据字段名和值推断链接目标
+func link(key, s string) string {
+	switch {
+	case addressPattern.MatchString(s):
+		return "/address/" + s
+	case hashPattern.MatchString(s) && (key == "txHash" || key == "transactions"):
+		return "/tx/" + s
+	case hashPattern.MatchString(s) && (key == "blockHash" || key == "parentHash"):
+		return "/block/" + s
+	case key == "blockNumber" || key == "latestBlock" || key == "indexedBlock" || key == "block":
+		return "/block/" + s
+	}
+	return ""
+}
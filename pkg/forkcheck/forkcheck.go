@@ -0,0 +1,325 @@
+// Package forkcheck 检查本地分叉节点（Hardhat/Anvil）是否满足预期：链 ID、区块高度、
+// 合约代码、代码哈希、存储槽和账户余额，并生成 pass/warn/fail 报告
+package forkcheck
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Backend 是检查所需的节点接口，*ethclient.Client 满足该接口
+type Backend interface {
+	ChainID(ctx context.Context) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+}
+
+// Severity 是断言不满足时的级别
+type Severity string
+
+const (
+	SeverityFail Severity = "fail" // 默认：不满足即失败
+	SeverityWarn Severity = "warn" // 不满足只警告
+)
+
+// Contract 断言地址上部署了合约；CodeHash 非空时还要求运行时代码的 keccak256 一致
+type Contract struct {
+	Name        string         `json:"name"`
+	Address     common.Address `json:"address"`
+	CodeHash    *common.Hash   `json:"codeHash,omitempty"`
+	MinCodeSize int            `json:"minCodeSize,omitempty"`
+	Severity    Severity       `json:"severity,omitempty"`
+}
+
+// Storage 断言合约存储槽的值
+type Storage struct {
+	Name     string         `json:"name"`
+	Address  common.Address `json:"address"`
+	Slot     common.Hash    `json:"slot"`
+	Value    common.Hash    `json:"value"`
+	Severity Severity       `json:"severity,omitempty"`
+}
+
+// Balance 断言账户余额范围（wei，十进制或 0x 十六进制字符串，空表示不限制）
+type Balance struct {
+	Name     string         `json:"name"`
+	Address  common.Address `json:"address"`
+	Min      string         `json:"min,omitempty"`
+	Max      string         `json:"max,omitempty"`
+	Severity Severity       `json:"severity,omitempty"`
+}
+
+// Config 描述期望的分叉状态
+type Config struct {
+	Name      string     `json:"name"`
+	ChainID   uint64     `json:"chainId"`
+	MinBlock  uint64     `json:"minBlock,omitempty"` // 最新区块不低于该高度
+	Block     *uint64    `json:"block,omitempty"`    // 在该区块检查状态，默认最新区块
+	Contracts []Contract `json:"contracts,omitempty"`
+	Storage   []Storage  `json:"storage,omitempty"`
+	Balances  []Balance  `json:"balances,omitempty"`
+}
+
+// LoadConfig 读取 JSON 配置文件
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取分叉配置失败: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("解析分叉配置 %s 失败: %w", path, err)
+	}
+	return &cfg, nil
+}
+
+// Presets 是内置的分叉配置
+var Presets = map[string]*Config{
+	// BSC 测试网分叉：原 TestForkStatus 中的期望
+	"bsc-testnet": {
+		Name:     "BSC 测试网",
+		ChainID:  97,
+		MinBlock: 30_000_000,
+		Contracts: []Contract{
+			{Name: "WBNB", Address: common.HexToAddress("0xae13d989daC2f0dEbFf460aC112a837C89BAa7cd")},
+			{Name: "PancakeSwap Router", Address: common.HexToAddress("0x9Ac64Cc6e4415144C455BD8E4837Fea55603e5c3")},
+		},
+		Balances: []Balance{
+			{Name: "Hardhat 账户0", Address: common.HexToAddress("0xf39Fd6e51aad88F6F4ce6aB8827279cffFb92266"), Min: "1000000000000000000", Severity: SeverityWarn},
+			{Name: "Hardhat 账户1", Address: common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8"), Min: "1000000000000000000", Severity: SeverityWarn},
+		},
+	},
+}
+
+// Status 是单项检查结果
+type Status string
+
+const (
+	StatusPass Status = "pass"
+	StatusWarn Status = "warn"
+	StatusFail Status = "fail"
+)
+
+// Result 是一项检查的结果
+type Result struct {
+	Kind     string `json:"kind"` // chain、block、code、storage、balance
+	Name     string `json:"name"`
+	Status   Status `json:"status"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+	Message  string `json:"message,omitempty"`
+}
+
+// Report 是一次检查的完整报告
+type Report struct {
+	Name    string   `json:"name"`
+	Node    string   `json:"node,omitempty"` // web3_clientVersion
+	ChainID uint64   `json:"chainId"`
+	Block   uint64   `json:"block"`
+	Status  Status   `json:"status"` // 所有结果中最差的状态
+	Results []Result `json:"results"`
+}
+
+// Count 返回指定状态的结果数量
+func (r *Report) Count(s Status) int {
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == s {
+			n++
+		}
+	}
+	return n
+}
+
+func (r *Report) add(res Result) {
+	r.Results = append(r.Results, res)
+	if rank(res.Status) > rank(r.Status) {
+		r.Status = res.Status
+	}
+}
+
+func rank(s Status) int {
+	switch s {
+	case StatusFail:
+		return 2
+	case StatusWarn:
+		return 1
+	}
+	return 0
+}
+
+// failed 按断言级别返回不满足时的状态
+func failed(s Severity) Status {
+	if s == SeverityWarn {
+		return StatusWarn
+	}
+	return StatusFail
+}
+
+// Check 按配置检查节点。只有节点无法访问时返回错误，断言不满足体现在报告中
+func Check(ctx context.Context, backend Backend, cfg *Config) (*Report, error) {
+	report := &Report{Name: cfg.Name, Status: StatusPass}
+	if c, ok := backend.(interface{ Client() *rpc.Client }); ok {
+		var version string
+		if err := c.Client().CallContext(ctx, &version, "web3_clientVersion"); err == nil {
+			report.Node = version
+		}
+	}
+
+	chainID, err := backend.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", err)
+	}
+	report.ChainID = chainID.Uint64()
+	res := Result{Kind: "chain", Name: "链 ID", Status: StatusPass, Expected: fmt.Sprint(cfg.ChainID), Actual: chainID.String()}
+	if cfg.ChainID != 0 && chainID.Uint64() != cfg.ChainID {
+		res.Status = StatusFail
+		res.Message = "节点链 ID 与期望不符，可能未分叉或分叉了错误的网络"
+	}
+	report.add(res)
+
+	head, err := backend.BlockNumber(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块失败: %w", err)
+	}
+	report.Block = head
+	if cfg.MinBlock > 0 {
+		res := Result{Kind: "block", Name: "区块高度", Status: StatusPass, Expected: fmt.Sprintf(">= %d", cfg.MinBlock), Actual: fmt.Sprint(head)}
+		if head < cfg.MinBlock {
+			res.Status = StatusFail
+			res.Message = "区块高度低于期望，可能是全新的本地节点"
+		}
+		report.add(res)
+	}
+
+	var at *big.Int
+	if cfg.Block != nil {
+		at = new(big.Int).SetUint64(*cfg.Block)
+		report.Block = *cfg.Block
+	}
+
+	for _, c := range cfg.Contracts {
+		res, err := checkContract(ctx, backend, c, at)
+		if err != nil {
+			return nil, err
+		}
+		report.add(res)
+	}
+	for _, s := range cfg.Storage {
+		res, err := checkStorage(ctx, backend, s, at)
+		if err != nil {
+			return nil, err
+		}
+		report.add(res)
+	}
+	for _, b := range cfg.Balances {
+		res, err := checkBalance(ctx, backend, b, at)
+		if err != nil {
+			return nil, err
+		}
+		report.add(res)
+	}
+	return report, nil
+}
+
+func checkContract(ctx context.Context, backend Backend, c Contract, at *big.Int) (Result, error) {
+	code, err := backend.CodeAt(ctx, c.Address, at)
+	if err != nil {
+		return Result{}, fmt.Errorf("获取 %s 合约代码失败: %w", c.Address.Hex(), err)
+	}
+	res := Result{Kind: "code", Name: label(c.Name, c.Address), Status: StatusPass, Actual: fmt.Sprintf("%d 字节", len(code))}
+	minSize := c.MinCodeSize
+	if minSize <= 0 {
+		minSize = 1
+	}
+	res.Expected = fmt.Sprintf(">= %d 字节", minSize)
+	if len(code) < minSize {
+		res.Status = failed(c.Severity)
+		res.Message = "地址上没有合约代码，分叉区块可能早于合约部署"
+		return res, nil
+	}
+	if c.CodeHash != nil {
+		hash := crypto.Keccak256Hash(code)
+		res.Expected, res.Actual = c.CodeHash.Hex(), hash.Hex()
+		if hash != *c.CodeHash {
+			res.Status = failed(c.Severity)
+			res.Message = "运行时代码哈希不符"
+		}
+	}
+	return res, nil
+}
+
+func checkStorage(ctx context.Context, backend Backend, s Storage, at *big.Int) (Result, error) {
+	value, err := backend.StorageAt(ctx, s.Address, s.Slot, at)
+	if err != nil {
+		return Result{}, fmt.Errorf("读取 %s 存储槽 %s 失败: %w", s.Address.Hex(), s.Slot.Hex(), err)
+	}
+	actual := common.BytesToHash(value)
+	res := Result{Kind: "storage", Name: label(s.Name, s.Address) + " @" + s.Slot.Hex(), Status: StatusPass, Expected: s.Value.Hex(), Actual: actual.Hex()}
+	if actual != s.Value {
+		res.Status = failed(s.Severity)
+		res.Message = "存储槽的值不符"
+	}
+	return res, nil
+}
+
+func checkBalance(ctx context.Context, backend Backend, b Balance, at *big.Int) (Result, error) {
+	minimum, err := parseAmount(b.Min)
+	if err != nil {
+		return Result{}, err
+	}
+	maximum, err := parseAmount(b.Max)
+	if err != nil {
+		return Result{}, err
+	}
+	balance, err := backend.BalanceAt(ctx, b.Address, at)
+	if err != nil {
+		return Result{}, fmt.Errorf("获取 %s 余额失败: %w", b.Address.Hex(), err)
+	}
+
+	var expected []string
+	if minimum != nil {
+		expected = append(expected, ">= "+minimum.String())
+	}
+	if maximum != nil {
+		expected = append(expected, "<= "+maximum.String())
+	}
+	res := Result{Kind: "balance", Name: label(b.Name, b.Address), Status: StatusPass, Expected: strings.Join(expected, " 且 "), Actual: balance.String()}
+	switch {
+	case minimum != nil && balance.Cmp(minimum) < 0:
+		res.Status = failed(b.Severity)
+		res.Message = "余额低于期望"
+	case maximum != nil && balance.Cmp(maximum) > 0:
+		res.Status = failed(b.Severity)
+		res.Message = "余额高于期望"
+	}
+	return res, nil
+}
+
+func parseAmount(s string) (*big.Int, error) {
+	if s == "" {
+		return nil, nil
+	}
+	v, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, fmt.Errorf("无效的余额 %q", s)
+	}
+	return v, nil
+}
+
+func label(name string, addr common.Address) string {
+	if name == "" {
+		return addr.Hex()
+	}
+	return fmt.Sprintf("%s (%s)", name, addr.Hex())
+}
@@ -0,0 +1,79 @@
+package forkcheck
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 测试各类断言在满足、警告和失败时的报告
+func TestCheck(t *testing.T) {
+	ctx := context.Background()
+	code := common.FromHex("0x6001600055")
+	contract := common.HexToAddress("0x00000000000000000000000000000000000000c0")
+	slot := common.BigToHash(big.NewInt(1))
+	value := common.BigToHash(big.NewInt(42))
+	chain := simchain.New(t, simchain.WithAlloc(contract, types.Account{
+		Code:    code,
+		Balance: big.NewInt(0),
+		Storage: map[common.Hash]common.Hash{slot: value},
+	}))
+	chain.Commit()
+	owner := chain.Accounts[0].Address
+	codeHash := crypto.Keccak256Hash(code)
+
+	cfg := &Config{
+		Name:    "模拟链",
+		ChainID: chain.ChainID.Uint64(),
+		Contracts: []Contract{
+			{Name: "合约", Address: contract, CodeHash: &codeHash},
+		},
+		Storage: []Storage{
+			{Name: "合约", Address: contract, Slot: slot, Value: value},
+		},
+		Balances: []Balance{
+			{Name: "账户0", Address: owner, Min: "1000000000000000000"},
+		},
+	}
+	report, err := Check(ctx, chain.Client, cfg)
+	if err != nil {
+		t.Fatalf("❌ 检查失败: %v", err)
+	}
+	if report.Status != StatusPass || report.Count(StatusPass) != 4 {
+		t.Fatalf("❌ 所有断言应通过: %+v", report.Results)
+	}
+	if report.Node == "" {
+		t.Fatal("❌ 报告应包含节点版本")
+	}
+	t.Logf("✅ 节点 %s，区块 %d", report.Node, report.Block)
+
+	// 警告级别的断言不满足只产生警告
+	cfg.Contracts = append(cfg.Contracts, Contract{Name: "不存在", Address: common.HexToAddress("0xdead"), Severity: SeverityWarn})
+	report, err = Check(ctx, chain.Client, cfg)
+	if err != nil || report.Status != StatusWarn || report.Count(StatusWarn) != 1 {
+		t.Fatalf("❌ 应只产生一个警告: %v %+v", err, report)
+	}
+
+	// 链 ID、区块高度、存储槽和余额不符均为失败
+	cfg.ChainID = 97
+	cfg.MinBlock = 30_000_000
+	cfg.Storage[0].Value = common.Hash{}
+	cfg.Balances[0].Max = "1"
+	report, err = Check(ctx, chain.Client, cfg)
+	if err != nil {
+		t.Fatalf("❌ 检查失败: %v", err)
+	}
+	if report.Status != StatusFail || report.Count(StatusFail) != 4 {
+		t.Fatalf("❌ 应有 4 项失败: %+v", report.Results)
+	}
+	for _, res := range report.Results {
+		if res.Status == StatusFail && res.Message == "" {
+			t.Fatalf("❌ 失败项应说明原因: %+v", res)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package ledger
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// WriteCSV 导出全部地址的账本记录（按区块、日志索引、地址排序）
+func (l *Ledger) WriteCSV(w io.Writer) error {
+	var all []Entry
+	for _, history := range l.entries {
+		all = append(all, history...)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].BlockNumber != all[j].BlockNumber {
+			return all[i].BlockNumber < all[j].BlockNumber
+		}
+		if all[i].LogIndex != all[j].LogIndex {
+			return all[i].LogIndex < all[j].LogIndex
+		}
+		return all[i].Address.Cmp(all[j].Address) < 0
+	})
+	return writeEntries(w, all)
+}
+
+// WriteAddressCSV 导出单个地址的账本记录
+func (l *Ledger) WriteAddressCSV(w io.Writer, addr common.Address) error {
+	return writeEntries(w, l.entries[addr])
+}
+
+func writeEntries(w io.Writer, entries []Entry) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"block", "tx_hash", "log_index", "address", "counterparty", "delta", "balance"}); err != nil {
+		return err
+	}
+	for _, e := range entries {
+		if err := cw.Write([]string{
+			fmt.Sprint(e.BlockNumber),
+			e.TxHash.Hex(),
+			fmt.Sprint(e.LogIndex),
+			e.Address.Hex(),
+			e.Counterparty.Hex(),
+			e.Delta.String(),
+			e.Balance.String(),
+		}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
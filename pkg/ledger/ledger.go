@@ -0,0 +1,224 @@
+// Package ledger 通过重放 ERC20 Transfer 日志构建按地址的账本，并重建任意区块的历史余额
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// TransferTopic 是 ERC20 Transfer(address,address,uint256) 的事件签名哈希
+var TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// Transfer 是一次 ERC20 转账
+type Transfer struct {
+	BlockNumber uint64
+	TxHash      common.Hash
+	LogIndex    uint
+	From        common.Address
+	To          common.Address
+	Value       *big.Int
+}
+
+// ParseTransfer 从日志中解析 Transfer 事件，不是 Transfer 时返回 false
+func ParseTransfer(log *types.Log) (Transfer, bool) {
+	if len(log.Topics) != 3 || log.Topics[0] != TransferTopic || len(log.Data) != 32 {
+		return Transfer{}, false
+	}
+	return Transfer{
+		BlockNumber: log.BlockNumber,
+		TxHash:      log.TxHash,
+		LogIndex:    log.Index,
+		From:        common.BytesToAddress(log.Topics[1].Bytes()),
+		To:          common.BytesToAddress(log.Topics[2].Bytes()),
+		Value:       new(big.Int).SetBytes(log.Data),
+	}, true
+}
+
+// Entry 是账本中的一条记录：一次转账对某个地址余额的影响
+type Entry struct {
+	BlockNumber  uint64
+	TxHash       common.Hash
+	LogIndex     uint
+	Address      common.Address
+	Counterparty common.Address
+	Delta        *big.Int // 正数为转入，负数为转出
+	Balance      *big.Int // 本条记录之后的余额
+}
+
+// Ledger 是单个代币的转账账本，Transfer 必须按 (区块, 日志索引) 顺序应用
+type Ledger struct {
+	Token common.Address
+
+	entries map[common.Address][]Entry
+	supply  []Entry // 零地址铸造/销毁引起的总量变化，Balance 为总量
+	lastPos [2]uint64
+	applied int
+}
+
+// New 创建空账本
+func New(token common.Address) *Ledger {
+	return &Ledger{Token: token, entries: make(map[common.Address][]Entry)}
+}
+
+// Apply 应用一次转账；零地址视为铸造/销毁，不单独记账
+func (l *Ledger) Apply(t Transfer) error {
+	pos := [2]uint64{t.BlockNumber, uint64(t.LogIndex)}
+	if l.applied > 0 && (pos[0] < l.lastPos[0] || (pos[0] == l.lastPos[0] && pos[1] <= l.lastPos[1])) {
+		return fmt.Errorf("转账顺序错误: 区块 %d 日志 %d 不晚于上一条", t.BlockNumber, t.LogIndex)
+	}
+	l.lastPos = pos
+	l.applied++
+
+	zero := common.Address{}
+	if t.From == zero {
+		l.supply = append(l.supply, l.next(l.supply, t, zero, t.To, t.Value))
+	} else {
+		l.entries[t.From] = append(l.entries[t.From], l.next(l.entries[t.From], t, t.From, t.To, new(big.Int).Neg(t.Value)))
+	}
+	if t.To == zero {
+		l.supply = append(l.supply, l.next(l.supply, t, zero, t.From, new(big.Int).Neg(t.Value)))
+	} else {
+		l.entries[t.To] = append(l.entries[t.To], l.next(l.entries[t.To], t, t.To, t.From, t.Value))
+	}
+	return nil
+}
+
+func (l *Ledger) next(history []Entry, t Transfer, addr, counterparty common.Address, delta *big.Int) Entry {
+	balance := new(big.Int)
+	if len(history) > 0 {
+		balance.Set(history[len(history)-1].Balance)
+	}
+	return Entry{
+		BlockNumber:  t.BlockNumber,
+		TxHash:       t.TxHash,
+		LogIndex:     t.LogIndex,
+		Address:      addr,
+		Counterparty: counterparty,
+		Delta:        delta,
+		Balance:      balance.Add(balance, delta),
+	}
+}
+
+// ApplyLogs 解析并按顺序应用一批日志，忽略非 Transfer 日志和其他合约的日志
+func (l *Ledger) ApplyLogs(logs []types.Log) error {
+	var transfers []Transfer
+	for i := range logs {
+		if logs[i].Address != l.Token || logs[i].Removed {
+			continue
+		}
+		if t, ok := ParseTransfer(&logs[i]); ok {
+			transfers = append(transfers, t)
+		}
+	}
+	SortTransfers(transfers)
+	for _, t := range transfers {
+		if err := l.Apply(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SortTransfers 按 (区块, 日志索引) 排序
+func SortTransfers(transfers []Transfer) {
+	sort.Slice(transfers, func(i, j int) bool {
+		if transfers[i].BlockNumber != transfers[j].BlockNumber {
+			return transfers[i].BlockNumber < transfers[j].BlockNumber
+		}
+		return transfers[i].LogIndex < transfers[j].LogIndex
+	})
+}
+
+// BalanceAt 返回地址在区块 block 结束时的余额（根据已应用的转账重建）
+func (l *Ledger) BalanceAt(addr common.Address, block uint64) *big.Int {
+	return balanceAt(l.entries[addr], block)
+}
+
+// SupplyAt 返回根据铸造/销毁记录重建的区块 block 结束时的总量
+func (l *Ledger) SupplyAt(block uint64) *big.Int {
+	return balanceAt(l.supply, block)
+}
+
+func balanceAt(history []Entry, block uint64) *big.Int {
+	// 找到第一条区块号大于 block 的记录，其前一条即为该区块结束时的状态
+	i := sort.Search(len(history), func(i int) bool { return history[i].BlockNumber > block })
+	if i == 0 {
+		return new(big.Int)
+	}
+	return new(big.Int).Set(history[i-1].Balance)
+}
+
+// Entries 返回地址的全部账本记录
+func (l *Ledger) Entries(addr common.Address) []Entry {
+	return l.entries[addr]
+}
+
+// Addresses 返回账本中出现过的所有地址（按地址排序）
+func (l *Ledger) Addresses() []common.Address {
+	addrs := make([]common.Address, 0, len(l.entries))
+	for addr := range l.entries {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool { return addrs[i].Cmp(addrs[j]) < 0 })
+	return addrs
+}
+
+// Holder 是某个区块时的持有人及余额
+type Holder struct {
+	Address common.Address `json:"address"`
+	Balance *big.Int       `json:"balance"`
+}
+
+// Holders 返回区块 block 结束时余额大于零的持有人，按余额从大到小排序
+func (l *Ledger) Holders(block uint64) []Holder {
+	var holders []Holder
+	for addr, history := range l.entries {
+		if bal := balanceAt(history, block); bal.Sign() > 0 {
+			holders = append(holders, Holder{Address: addr, Balance: bal})
+		}
+	}
+	sort.Slice(holders, func(i, j int) bool {
+		if c := holders[i].Balance.Cmp(holders[j].Balance); c != 0 {
+			return c > 0
+		}
+		return holders[i].Address.Cmp(holders[j].Address) < 0
+	})
+	return holders
+}
+
+// Fetch 按 step 分段查询 [from, to] 范围内代币的 Transfer 日志，返回按顺序排列的转账
+func Fetch(ctx context.Context, filterer ethereum.LogFilterer, token common.Address, from, to, step uint64) ([]Transfer, error) {
+	if step == 0 {
+		step = 1000
+	}
+	var transfers []Transfer
+	for start := from; start <= to; start += step {
+		end := start + step - 1
+		if end > to {
+			end = to
+		}
+		logs, err := filterer.FilterLogs(ctx, ethereum.FilterQuery{
+			FromBlock: new(big.Int).SetUint64(start),
+			ToBlock:   new(big.Int).SetUint64(end),
+			Addresses: []common.Address{token},
+			Topics:    [][]common.Hash{{TransferTopic}},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("查询区块 %d ~ %d 转账日志失败: %w", start, end, err)
+		}
+		for i := range logs {
+			if t, ok := ParseTransfer(&logs[i]); ok && !logs[i].Removed {
+				transfers = append(transfers, t)
+			}
+		}
+	}
+	SortTransfers(transfers)
+	return transfers, nil
+}
@@ -0,0 +1,133 @@
+package ledger
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var (
+	token = common.HexToAddress("0xE5aFC41736bBE96cCB912Cb2d2e6BB503979b657")
+	alice = common.HexToAddress("0x8c8aB9B6178877246B224F8D745A1410C4928373")
+	bob   = common.HexToAddress("0x2281cB9267ABAF264c0A4c0dD2e414b4d68cE634")
+	carol = common.HexToAddress("0x70997970C51812dc3A010C7d01b50e0d17dc79C8")
+)
+
+func transferLog(block uint64, index uint, from, to common.Address, value int64) types.Log {
+	return types.Log{
+		Address:     token,
+		Topics:      []common.Hash{TransferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())},
+		Data:        common.LeftPadBytes(big.NewInt(value).Bytes(), 32),
+		BlockNumber: block,
+		Index:       index,
+	}
+}
+
+// 构造账本：区块 10 铸造 1000 给 alice，区块 20 alice→bob 300，区块 30 bob→carol 100，区块 40 carol 销毁 50
+func buildLedger(t *testing.T) *Ledger {
+	l := New(token)
+	logs := []types.Log{
+		transferLog(30, 0, bob, carol, 100),
+		transferLog(10, 0, common.Address{}, alice, 1000),
+		transferLog(40, 2, carol, common.Address{}, 50),
+		transferLog(20, 5, alice, bob, 300),
+	}
+	if err := l.ApplyLogs(logs); err != nil {
+		t.Fatalf("❌ 应用日志失败: %v", err)
+	}
+	return l
+}
+
+// 测试历史余额重建
+func TestBalanceAt(t *testing.T) {
+	l := buildLedger(t)
+
+	cases := []struct {
+		addr  common.Address
+		block uint64
+		want  int64
+	}{
+		{alice, 9, 0}, {alice, 10, 1000}, {alice, 25, 700},
+		{bob, 19, 0}, {bob, 20, 300}, {bob, 30, 200},
+		{carol, 35, 100}, {carol, 40, 50},
+	}
+	for _, c := range cases {
+		if got := l.BalanceAt(c.addr, c.block); got.Int64() != c.want {
+			t.Errorf("❌ %s 在区块 %d 余额为 %s，期望 %d", c.addr.Hex()[:8], c.block, got, c.want)
+		}
+	}
+	if got := l.SupplyAt(40); got.Int64() != 950 {
+		t.Errorf("❌ 总量应为 950，实际 %s", got)
+	}
+	if holders := l.Holders(40); len(holders) != 3 || holders[0].Address != alice {
+		t.Errorf("❌ 持有人排序错误: %+v", holders)
+	}
+
+	// 乱序应用应报错
+	if err := l.Apply(Transfer{BlockNumber: 1, From: alice, To: bob, Value: big.NewInt(1)}); err == nil {
+		t.Error("❌ 乱序应用转账应返回错误")
+	}
+}
+
+// fakeReader 用函数模拟链上 balanceOf
+type fakeReader func(holder common.Address, block uint64) *big.Int
+
+func (f fakeReader) BalanceOf(_ context.Context, _ common.Address, holder common.Address, block uint64) (*big.Int, error) {
+	return f(holder, block), nil
+}
+
+// 测试对账：一致、手续费型、弹性供应型
+func TestReconcile(t *testing.T) {
+	l := buildLedger(t)
+	ctx := context.Background()
+	checkpoints := []uint64{20, 40}
+
+	exact := fakeReader(func(h common.Address, b uint64) *big.Int { return l.BalanceAt(h, b) })
+	report, err := l.Reconcile(ctx, exact, checkpoints, nil)
+	if err != nil || report.Verdict != VerdictConsistent || report.Checked != 6 {
+		t.Fatalf("❌ 期望一致: %v %+v", err, report)
+	}
+
+	// 每次转入扣 1% 手续费：bob 在区块 20 实际只收到 297
+	fee := fakeReader(func(h common.Address, b uint64) *big.Int {
+		bal := l.BalanceAt(h, b)
+		if h == bob && b >= 20 {
+			bal.Sub(bal, big.NewInt(3))
+		}
+		return bal
+	})
+	report, _ = l.Reconcile(ctx, fee, checkpoints, []common.Address{bob})
+	if report.Verdict != VerdictFeeOnTransfer {
+		t.Fatalf("❌ 期望识别为 fee-on-transfer，实际 %s", report.Verdict)
+	}
+
+	// 所有余额按 2 倍 rebase
+	rebase := fakeReader(func(h common.Address, b uint64) *big.Int {
+		return new(big.Int).Mul(l.BalanceAt(h, b), big.NewInt(2))
+	})
+	report, _ = l.Reconcile(ctx, rebase, []uint64{40}, nil)
+	if report.Verdict != VerdictRebasing {
+		t.Fatalf("❌ 期望识别为 rebasing，实际 %s", report.Verdict)
+	}
+}
+
+// 测试 CSV 导出
+func TestWriteCSV(t *testing.T) {
+	l := buildLedger(t)
+	var buf bytes.Buffer
+	if err := l.WriteCSV(&buf); err != nil {
+		t.Fatalf("❌ 导出 CSV 失败: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 7 { // 表头 + alice 2 条 + bob 2 条 + carol 2 条，零地址不记账
+		t.Fatalf("❌ CSV 行数错误: %d\n%s", len(lines), buf.String())
+	}
+	if !strings.HasSuffix(lines[len(lines)-1], ",-50,50") {
+		t.Fatalf("❌ 最后一行应为 carol 销毁记录: %s", lines[len(lines)-1])
+	}
+}
@@ -0,0 +1,156 @@
+package ledger
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// BalanceReader 查询代币在指定区块的链上余额
+type BalanceReader interface {
+	BalanceOf(ctx context.Context, token, holder common.Address, block uint64) (*big.Int, error)
+}
+
+// CallReader 通过 eth_call 调用 balanceOf 实现 BalanceReader
+type CallReader struct {
+	Caller ethereum.ContractCaller
+}
+
+var balanceOfABI, _ = abi.JSON(strings.NewReader(`[{"inputs":[{"name":"account","type":"address"}],"name":"balanceOf","outputs":[{"name":"","type":"uint256"}],"stateMutability":"view","type":"function"}]`))
+
+// BalanceOf 调用代币的 balanceOf(holder)
+func (r CallReader) BalanceOf(ctx context.Context, token, holder common.Address, block uint64) (*big.Int, error) {
+	input, err := balanceOfABI.Pack("balanceOf", holder)
+	if err != nil {
+		return nil, err
+	}
+	out, err := r.Caller.CallContract(ctx, ethereum.CallMsg{To: &token, Data: input}, new(big.Int).SetUint64(block))
+	if err != nil {
+		return nil, fmt.Errorf("调用 balanceOf 失败: %w", err)
+	}
+	values, err := balanceOfABI.Unpack("balanceOf", out)
+	if err != nil {
+		return nil, fmt.Errorf("解析 balanceOf 返回值失败: %w", err)
+	}
+	return values[0].(*big.Int), nil
+}
+
+// Verdict 是对账结论
+type Verdict string
+
+const (
+	VerdictConsistent    Verdict = "consistent"      // 账本与链上余额一致
+	VerdictFeeOnTransfer Verdict = "fee-on-transfer" // 转入方实际到账少于事件金额
+	VerdictRebasing      Verdict = "rebasing"        // 余额按比例整体变化，与转账无关
+	VerdictUnknown       Verdict = "unknown"         // 存在差异但无法归类
+)
+
+// Discrepancy 是某个检查点上账本余额与链上余额的差异
+type Discrepancy struct {
+	Block   uint64         `json:"block"`
+	Address common.Address `json:"address"`
+	Ledger  *big.Int       `json:"ledger"`
+	Actual  *big.Int       `json:"actual"`
+	Diff    *big.Int       `json:"diff"` // Actual - Ledger
+}
+
+// Report 是对账结果
+type Report struct {
+	Checked       int           `json:"checked"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+	Verdict       Verdict       `json:"verdict"`
+}
+
+// Reconcile 在每个检查点比较账本余额和链上 balanceOf，并推断代币是否为手续费型或弹性供应型
+func (l *Ledger) Reconcile(ctx context.Context, reader BalanceReader, checkpoints []uint64, holders []common.Address) (*Report, error) {
+	if len(holders) == 0 {
+		holders = l.Addresses()
+	}
+	report := &Report{}
+	for _, block := range checkpoints {
+		for _, holder := range holders {
+			actual, err := reader.BalanceOf(ctx, l.Token, holder, block)
+			if err != nil {
+				return nil, fmt.Errorf("区块 %d 查询 %s 余额失败: %w", block, holder.Hex(), err)
+			}
+			report.Checked++
+
+			expected := l.BalanceAt(holder, block)
+			if actual.Cmp(expected) != 0 {
+				report.Discrepancies = append(report.Discrepancies, Discrepancy{
+					Block:   block,
+					Address: holder,
+					Ledger:  expected,
+					Actual:  actual,
+					Diff:    new(big.Int).Sub(actual, expected),
+				})
+			}
+		}
+	}
+	report.Verdict = l.classify(report.Discrepancies)
+	return report, nil
+}
+
+// classify 根据差异推断原因：
+//   - 所有差异同号且 actual/ledger 比例一致（至少两个地址）→ 弹性供应（rebasing）
+//   - 所有差异为负且都出现在收到过转账的地址 → 转账扣手续费（fee-on-transfer）
+func (l *Ledger) classify(ds []Discrepancy) Verdict {
+	if len(ds) == 0 {
+		return VerdictConsistent
+	}
+
+	sameSign, received := true, true
+	addrs := make(map[common.Address]bool)
+	for _, d := range ds {
+		addrs[d.Address] = true
+		if d.Diff.Sign() != ds[0].Diff.Sign() {
+			sameSign = false
+		}
+		if !l.hasIncoming(d.Address, d.Block) {
+			received = false
+		}
+	}
+
+	if sameSign && len(addrs) >= 2 && proportional(ds) {
+		return VerdictRebasing
+	}
+	if sameSign && ds[0].Diff.Sign() < 0 && received {
+		return VerdictFeeOnTransfer
+	}
+	return VerdictUnknown
+}
+
+func (l *Ledger) hasIncoming(addr common.Address, block uint64) bool {
+	for _, e := range l.entries[addr] {
+		if e.BlockNumber <= block && e.Delta.Sign() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// proportional 判断所有差异的 actual/ledger 比例是否在 0.1% 范围内一致
+func proportional(ds []Discrepancy) bool {
+	var first *big.Float
+	for _, d := range ds {
+		if d.Ledger.Sign() == 0 {
+			return false
+		}
+		ratio := new(big.Float).Quo(new(big.Float).SetInt(d.Actual), new(big.Float).SetInt(d.Ledger))
+		if first == nil {
+			first = ratio
+			continue
+		}
+		delta := new(big.Float).Sub(ratio, first)
+		delta.Abs(delta)
+		if delta.Cmp(new(big.Float).Mul(first, big.NewFloat(0.001))) > 0 {
+			return false
+		}
+	}
+	return true
+}
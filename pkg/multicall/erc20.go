@@ -0,0 +1,74 @@
+package multicall
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// ERC20ABI 是聚合读取代币信息所需的 ERC20 只读方法
+var ERC20ABI = mustParse(`[
+	{"name":"name","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"string"}]},
+	{"name":"symbol","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"string"}]},
+	{"name":"decimals","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"uint8"}]},
+	{"name":"totalSupply","type":"function","stateMutability":"view","inputs":[],"outputs":[{"type":"uint256"}]},
+	{"name":"balanceOf","type":"function","stateMutability":"view","inputs":[{"type":"address"}],"outputs":[{"type":"uint256"}]}
+]`)
+
+// Token 是代币的基本信息
+type Token struct {
+	Address     common.Address
+	Name        string
+	Symbol      string
+	Decimals    uint8
+	TotalSupply *big.Int
+}
+
+// TokenInfo 用一次聚合调用读取 name/symbol/decimals/totalSupply
+func (c *Caller) TokenInfo(ctx context.Context, token common.Address, block *big.Int) (*Token, error) {
+	calls := []Call{
+		NewCall(token, &ERC20ABI, "name"),
+		NewCall(token, &ERC20ABI, "symbol"),
+		NewCall(token, &ERC20ABI, "decimals"),
+		NewCall(token, &ERC20ABI, "totalSupply"),
+	}
+	results, err := c.Aggregate(ctx, calls, block)
+	if err != nil {
+		return nil, err
+	}
+	info := &Token{Address: token}
+	if info.Name, err = Value[string](results[0], 0); err != nil {
+		return nil, fmt.Errorf("读取 name 失败: %w", err)
+	}
+	if info.Symbol, err = Value[string](results[1], 0); err != nil {
+		return nil, fmt.Errorf("读取 symbol 失败: %w", err)
+	}
+	if info.Decimals, err = Value[uint8](results[2], 0); err != nil {
+		return nil, fmt.Errorf("读取 decimals 失败: %w", err)
+	}
+	if info.TotalSupply, err = Value[*big.Int](results[3], 0); err != nil {
+		return nil, fmt.Errorf("读取 totalSupply 失败: %w", err)
+	}
+	return info, nil
+}
+
+// TokenBalances 聚合查询多个地址的代币余额
+func (c *Caller) TokenBalances(ctx context.Context, token common.Address, holders []common.Address, block *big.Int) ([]*big.Int, error) {
+	calls := make([]Call, len(holders))
+	for i, h := range holders {
+		calls[i] = NewCall(token, &ERC20ABI, "balanceOf", h)
+	}
+	results, err := c.Aggregate(ctx, calls, block)
+	if err != nil {
+		return nil, err
+	}
+	balances := make([]*big.Int, len(holders))
+	for i, r := range results {
+		if balances[i], err = Value[*big.Int](r, 0); err != nil {
+			return nil, fmt.Errorf("读取 %s 余额失败: %w", holders[i].Hex(), err)
+		}
+	}
+	return balances, nil
+}
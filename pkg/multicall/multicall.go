@@ -0,0 +1,267 @@
+// Package multicall 通过 Multicall3 把多个合约只读调用合并为一次 eth_call：
+// 每个调用可单独允许失败，结果按 ABI 解码；按调用数、calldata 大小和 gas 分块，
+// 链上没有 Multicall3 时退化为逐个 eth_call
+package multicall
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Address 是 Multicall3 在几乎所有 EVM 链上的部署地址
+var Address = common.HexToAddress("0xcA11bde05977b3631167028862bE2a173976CA11")
+
+var (
+	//go:embed contracts/Multicall3_sol_Multicall3.abi
+	abiJSON string
+
+	//go:embed contracts/Multicall3_sol_Multicall3.bin-runtime
+	runtimeHex string
+
+	// ABI 是 Multicall3 的 ABI
+	ABI = mustParse(abiJSON)
+
+	// RuntimeCode 是 ABI 兼容的 Multicall3 运行时代码，可写入模拟链创世块或通过 devnode.SetCode 注入本地节点
+	RuntimeCode = common.FromHex(runtimeHex)
+)
+
+func mustParse(s string) abi.ABI {
+	parsed, err := abi.JSON(strings.NewReader(s))
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
+
+// ErrCallFailed 表示不允许失败的调用执行失败（revert）
+var ErrCallFailed = errors.New("调用失败")
+
+// Call 是一个只读调用。设置 ABI 和 Method 时按 ABI 编码参数并解码结果，否则直接使用 Data
+type Call struct {
+	Target       common.Address
+	ABI          *abi.ABI
+	Method       string
+	Args         []interface{}
+	Data         []byte
+	AllowFailure bool // 允许失败时，失败只体现在 Result.Err 中
+}
+
+// NewCall 创建按 ABI 编码的调用
+func NewCall(target common.Address, contractABI *abi.ABI, method string, args ...interface{}) Call {
+	return Call{Target: target, ABI: contractABI, Method: method, Args: args}
+}
+
+// Result 是一个调用的结果
+type Result struct {
+	Success    bool
+	ReturnData []byte
+	Values     []interface{} // 按 ABI 解码后的返回值，只有设置了 ABI 时才有
+	Err        error         // 调用失败或解码失败
+}
+
+// Value 取出第 i 个返回值并转换为 T
+func Value[T any](r Result, i int) (T, error) {
+	var zero T
+	if r.Err != nil {
+		return zero, r.Err
+	}
+	if i >= len(r.Values) {
+		return zero, fmt.Errorf("返回值只有 %d 个", len(r.Values))
+	}
+	v, ok := r.Values[i].(T)
+	if !ok {
+		return zero, fmt.Errorf("返回值类型为 %T，不是 %T", r.Values[i], zero)
+	}
+	return v, nil
+}
+
+// Caller 执行聚合调用
+type Caller struct {
+	backend bind.ContractCaller
+
+	Address     common.Address // Multicall3 地址，默认 Address
+	MaxCalls    int            // 每块最多调用数，默认 500
+	MaxCalldata int            // 每块 calldata 总字节数上限，默认 128 KiB
+	MaxGas      uint64         // 每块预估 gas 上限，默认 25,000,000（低于 geth 默认的 eth_call gas 上限 50M）
+	GasPerCall  uint64         // 每个调用的预估 gas，默认 100,000
+
+	mu        sync.Mutex
+	known     bool // 已成功探测过，之后直接使用 available
+	available bool
+}
+
+// New 创建聚合调用器
+func New(backend bind.ContractCaller) *Caller {
+	return &Caller{
+		backend:     backend,
+		Address:     Address,
+		MaxCalls:    500,
+		MaxCalldata: 128 << 10,
+		MaxGas:      25_000_000,
+		GasPerCall:  100_000,
+	}
+}
+
+// Available 返回链上是否部署了 Multicall3。只缓存成功的探测结果，
+// 查询失败（例如调用方的 context 超时）时返回错误，下次调用重新探测
+func (c *Caller) Available(ctx context.Context) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.known {
+		return c.available, nil
+	}
+	code, err := c.backend.CodeAt(ctx, c.Address, nil)
+	if err != nil {
+		return false, fmt.Errorf("检查 Multicall3 部署失败: %w", err)
+	}
+	c.known, c.available = true, len(code) > 0
+	return c.available, nil
+}
+
+// Aggregate 执行所有调用，结果与调用一一对应。block 为 nil 表示最新区块。
+// 不允许失败的调用失败时返回 ErrCallFailed
+func (c *Caller) Aggregate(ctx context.Context, calls []Call, block *big.Int) ([]Result, error) {
+	data := make([][]byte, len(calls))
+	for i, call := range calls {
+		d, err := encode(call)
+		if err != nil {
+			return nil, fmt.Errorf("编码第 %d 个调用失败: %w", i, err)
+		}
+		data[i] = d
+	}
+
+	ok, err := c.Available(ctx)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]Result, len(calls))
+	if !ok {
+		if err := c.individual(ctx, calls, data, results, block); err != nil {
+			return nil, err
+		}
+	} else {
+		for _, r := range c.chunks(data) {
+			if err := c.aggregate3(ctx, calls[r[0]:r[1]], data[r[0]:r[1]], results[r[0]:r[1]], block); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	for i := range results {
+		decode(calls[i], &results[i])
+	}
+	return results, nil
+}
+
+// chunks 按调用数、calldata 大小和预估 gas 划分区间 [start, end)
+func (c *Caller) chunks(data [][]byte) [][2]int {
+	maxByGas := len(data)
+	if c.GasPerCall > 0 && c.MaxGas > 0 {
+		maxByGas = int(c.MaxGas / c.GasPerCall)
+	}
+	var out [][2]int
+	start, size := 0, 0
+	for i, d := range data {
+		n := i - start
+		if n > 0 && ((c.MaxCalls > 0 && n >= c.MaxCalls) || n >= maxByGas || (c.MaxCalldata > 0 && size+len(d) > c.MaxCalldata)) {
+			out = append(out, [2]int{start, i})
+			start, size = i, 0
+		}
+		size += len(d)
+	}
+	if start < len(data) {
+		out = append(out, [2]int{start, len(data)})
+	}
+	return out
+}
+
+// call3 与 Multicall3.Call3 结构对应
+type call3 struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+func (c *Caller) aggregate3(ctx context.Context, calls []Call, data [][]byte, results []Result, block *big.Int) error {
+	args := make([]call3, len(calls))
+	for i, call := range calls {
+		args[i] = call3{Target: call.Target, AllowFailure: call.AllowFailure, CallData: data[i]}
+	}
+	input, err := ABI.Pack("aggregate3", args)
+	if err != nil {
+		return fmt.Errorf("编码 aggregate3 失败: %w", err)
+	}
+	out, err := c.backend.CallContract(ctx, ethereum.CallMsg{To: &c.Address, Data: input}, block)
+	if err != nil {
+		if strings.Contains(err.Error(), "Multicall3: call failed") {
+			return fmt.Errorf("aggregate3: %w", ErrCallFailed)
+		}
+		return fmt.Errorf("调用 Multicall3 失败: %w", err)
+	}
+
+	var decoded []struct {
+		Success    bool
+		ReturnData []byte
+	}
+	if err := ABI.UnpackIntoInterface(&decoded, "aggregate3", out); err != nil {
+		return fmt.Errorf("解码 aggregate3 结果失败: %w", err)
+	}
+	if len(decoded) != len(calls) {
+		return fmt.Errorf("aggregate3 返回 %d 个结果，期望 %d 个", len(decoded), len(calls))
+	}
+	for i, d := range decoded {
+		results[i] = Result{Success: d.Success, ReturnData: d.ReturnData}
+		if !d.Success {
+			results[i].Err = ErrCallFailed
+		}
+	}
+	return nil
+}
+
+// individual 在没有 Multicall3 的链上逐个调用，语义与 aggregate3 一致；
+// 失败的调用同时匹配 ErrCallFailed 和 chainerr.ErrReverted，可取出 revert 数据
+func (c *Caller) individual(ctx context.Context, calls []Call, data [][]byte, results []Result, block *big.Int) error {
+	for i, call := range calls {
+		target := call.Target
+		out, err := c.backend.CallContract(ctx, ethereum.CallMsg{To: &target, Data: data[i]}, block)
+		if err != nil {
+			if !call.AllowFailure {
+				return fmt.Errorf("第 %d 个调用 %s: %w: %w", i, target.Hex(), ErrCallFailed, chainerr.Wrap(err))
+			}
+			results[i] = Result{Err: fmt.Errorf("%w: %w", ErrCallFailed, chainerr.Wrap(err))}
+			continue
+		}
+		results[i] = Result{Success: true, ReturnData: out}
+	}
+	return nil
+}
+
+func encode(call Call) ([]byte, error) {
+	if call.ABI == nil {
+		return call.Data, nil
+	}
+	return call.ABI.Pack(call.Method, call.Args...)
+}
+
+func decode(call Call, r *Result) {
+	if !r.Success || call.ABI == nil {
+		return
+	}
+	values, err := call.ABI.Unpack(call.Method, r.ReturnData)
+	if err != nil {
+		r.Err = fmt.Errorf("解码 %s 返回值失败: %w", call.Method, err)
+		return
+	}
+	r.Values = values
+}
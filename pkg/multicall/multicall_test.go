@@ -0,0 +1,153 @@
+package multicall
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// countingBackend 统计 eth_call 次数
+type countingBackend struct {
+	bind.ContractCaller
+	calls int
+}
+
+func (b *countingBackend) CallContract(ctx context.Context, msg ethereum.CallMsg, block *big.Int) ([]byte, error) {
+	b.calls++
+	return b.ContractCaller.CallContract(ctx, msg, block)
+}
+
+// 测试跨合约聚合、允许失败、类型化解码、分块以及没有 Multicall3 时的退化
+func TestAggregate(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t, simchain.WithAlloc(Address, types.Account{Code: RuntimeCode, Balance: big.NewInt(0)}))
+	supply := new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(1e18))
+	tokenAddr, token := chain.DeployToken("IWS Token", "IWS", supply)
+	storeAddr, _ := chain.DeployStore("v1.0.0")
+	for i := 1; i < len(chain.Accounts); i++ {
+		tx, err := token.Transfer(chain.Auth(0), chain.Accounts[i].Address, big.NewInt(int64(i)))
+		if err != nil {
+			t.Fatalf("❌ 转账失败: %v", err)
+		}
+		chain.WaitMined(tx)
+	}
+
+	backend := &countingBackend{ContractCaller: chain.Client}
+	c := New(backend)
+
+	// 一次 eth_call 读取代币信息（加上一次 eth_getCode 探测）
+	info, err := c.TokenInfo(ctx, tokenAddr, nil)
+	if err != nil {
+		t.Fatalf("❌ 读取代币信息失败: %v", err)
+	}
+	if info.Name != "IWS Token" || info.Symbol != "IWS" || info.Decimals != 18 || info.TotalSupply.Cmp(supply) != 0 {
+		t.Fatalf("❌ 代币信息不符: %+v", info)
+	}
+	if backend.calls != 1 {
+		t.Fatalf("❌ 应只发送 1 次 eth_call，实际 %d", backend.calls)
+	}
+
+	// 跨合约调用，其中一个允许失败
+	storeABI, _ := abi.JSON(strings.NewReader(store.StoreMetaData.ABI))
+	results, err := c.Aggregate(ctx, []Call{
+		NewCall(storeAddr, &storeABI, "version"),
+		NewCall(tokenAddr, &ERC20ABI, "balanceOf", chain.Accounts[2].Address),
+		{Target: tokenAddr, Data: []byte{0xde, 0xad, 0xbe, 0xef}, AllowFailure: true},
+	}, nil)
+	if err != nil {
+		t.Fatalf("❌ 聚合调用失败: %v", err)
+	}
+	if v, err := Value[string](results[0], 0); err != nil || v != "v1.0.0" {
+		t.Fatalf("❌ Store 版本不符: %q %v", v, err)
+	}
+	if v, err := Value[*big.Int](results[1], 0); err != nil || v.Int64() != 2 {
+		t.Fatalf("❌ 余额不符: %v %v", v, err)
+	}
+	if results[2].Success || !errors.Is(results[2].Err, ErrCallFailed) {
+		t.Fatalf("❌ 允许失败的调用应标记失败: %+v", results[2])
+	}
+
+	// 不允许失败的调用失败时整体返回错误
+	_, err = c.Aggregate(ctx, []Call{{Target: tokenAddr, Data: []byte{0xde, 0xad, 0xbe, 0xef}}}, nil)
+	if !errors.Is(err, ErrCallFailed) {
+		t.Fatalf("❌ 应返回 ErrCallFailed: %v", err)
+	}
+
+	// 分块：7 个持有人、每块 3 个调用 => 3 次 eth_call
+	holders := make([]common.Address, 0, 7)
+	for len(holders) < 7 {
+		holders = append(holders, chain.Accounts[len(holders)%len(chain.Accounts)].Address)
+	}
+	c.MaxCalls = 3
+	backend.calls = 0
+	balances, err := c.TokenBalances(ctx, tokenAddr, holders, nil)
+	if err != nil {
+		t.Fatalf("❌ 查询余额失败: %v", err)
+	}
+	if backend.calls != 3 {
+		t.Fatalf("❌ 应分 3 块发送，实际 %d 次", backend.calls)
+	}
+	for i, h := range holders {
+		want, _ := token.BalanceOf(&bind.CallOpts{}, h)
+		if balances[i].Cmp(want) != 0 {
+			t.Fatalf("❌ %s 余额不符", h.Hex())
+		}
+	}
+
+	// 没有 Multicall3 的链：逐个调用，结果一致
+	plain := simchain.New(t)
+	plainToken, _ := plain.DeployToken("IWS Token", "IWS", supply)
+	fallback := New(plain.Client)
+	if ok, _ := fallback.Available(ctx); ok {
+		t.Fatal("❌ 普通模拟链上不应检测到 Multicall3")
+	}
+	info, err = fallback.TokenInfo(ctx, plainToken, nil)
+	if err != nil || info.Symbol != "IWS" {
+		t.Fatalf("❌ 退化为逐个调用失败: %+v %v", info, err)
+	}
+	t.Logf("✅ %s (%s) 精度 %d", info.Name, info.Symbol, info.Decimals)
+}
+
+// flakyCodeBackend 前 failures 次 eth_getCode 失败
+type flakyCodeBackend struct {
+	bind.ContractCaller
+	failures, probes int
+}
+
+func (b *flakyCodeBackend) CodeAt(ctx context.Context, addr common.Address, block *big.Int) ([]byte, error) {
+	b.probes++
+	if b.probes <= b.failures {
+		return nil, context.DeadlineExceeded
+	}
+	return b.ContractCaller.CodeAt(ctx, addr, block)
+}
+
+// 测试探测失败不会被缓存：第一次超时后重新探测，成功后不再查询
+func TestAvailableRetry(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t, simchain.WithAlloc(Address, types.Account{Code: RuntimeCode, Balance: big.NewInt(0)}))
+	backend := &flakyCodeBackend{ContractCaller: chain.Client, failures: 1}
+	c := New(backend)
+
+	if _, err := c.Available(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("❌ 第一次探测应返回超时: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if ok, err := c.Available(ctx); !ok || err != nil {
+			t.Fatalf("❌ 重新探测应成功: %v %v", ok, err)
+		}
+	}
+	if backend.probes != 2 {
+		t.Fatalf("❌ 成功后应缓存结果，实际探测 %d 次", backend.probes)
+	}
+}
@@ -0,0 +1,243 @@
+// Package proxy 识别代理合约（EIP-1967、透明代理、UUPS、信标代理、EIP-1822 和 EIP-1167 最小代理），
+// 读取存储槽和字节码得到实现合约、管理员和信标地址，并让解码器对代理地址使用实现合约的 ABI
+package proxy
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/decoder"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// EIP-1967 和 EIP-1822 规定的存储槽
+var (
+	// ImplementationSlot 是 bytes32(uint256(keccak256("eip1967.proxy.implementation")) - 1)
+	ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+	// AdminSlot 是 bytes32(uint256(keccak256("eip1967.proxy.admin")) - 1)
+	AdminSlot = common.HexToHash("0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103")
+	// BeaconSlot 是 bytes32(uint256(keccak256("eip1967.proxy.beacon")) - 1)
+	BeaconSlot = common.HexToHash("0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50")
+	// ProxiableSlot 是 EIP-1822 的 keccak256("PROXIABLE")
+	ProxiableSlot = common.HexToHash("0xc5f16f0fcc639fa48a6947836d9850f504798523bf8c9a3a87d5876cf622bcf7")
+)
+
+// EIP-1167 最小代理的运行时代码：前缀 ++ 实现地址 ++ 后缀
+var (
+	minimalPrefix = common.FromHex("0x363d3d373d3d3d363d73")
+	minimalSuffix = common.FromHex("0x5af43d82803e903d91602b57fd5bf3")
+)
+
+var (
+	selectorImplementation = common.FromHex("0x5c60da1b") // implementation()
+	selectorProxiableUUID  = common.FromHex("0x52d1902d") // proxiableUUID()
+)
+
+// MaxDepth 是 Resolve 最多跟随的代理层数
+const MaxDepth = 4
+
+// ErrTooDeep 表示代理嵌套超过 MaxDepth 层（或互相指向）
+var ErrTooDeep = errors.New("代理嵌套层数过多")
+
+// Kind 是代理类型
+type Kind string
+
+const (
+	None        Kind = ""            // 不是代理
+	Minimal     Kind = "eip1167"     // EIP-1167 最小代理（克隆）
+	EIP1967     Kind = "eip1967"     // 只有实现槽的 EIP-1967 代理
+	Transparent Kind = "transparent" // 透明代理：EIP-1967 实现槽和 admin 槽
+	UUPS        Kind = "uups"        // UUPS：EIP-1967 实现槽，实现合约提供 proxiableUUID
+	Beacon      Kind = "beacon"      // 信标代理：实现地址由信标合约给出
+	EIP1822     Kind = "eip1822"     // EIP-1822 可代理合约（PROXIABLE 槽）
+)
+
+// Info 是一个地址的代理信息
+type Info struct {
+	Address        common.Address `json:"address"`
+	Kind           Kind           `json:"kind,omitempty"`
+	Implementation common.Address `json:"implementation"`
+	Admin          common.Address `json:"admin"`  // 仅透明代理
+	Beacon         common.Address `json:"beacon"` // 仅信标代理
+	CodeSize       int            `json:"codeSize"`
+}
+
+// IsProxy 判断是否为代理合约
+func (i *Info) IsProxy() bool {
+	return i.Kind != None
+}
+
+func (i *Info) String() string {
+	if !i.IsProxy() {
+		return fmt.Sprintf("%s: 不是代理（代码 %d 字节）", i.Address.Hex(), i.CodeSize)
+	}
+	s := fmt.Sprintf("%s: %s 代理 -> %s", i.Address.Hex(), i.Kind, i.Implementation.Hex())
+	if i.Admin != (common.Address{}) {
+		s += "，admin " + i.Admin.Hex()
+	}
+	if i.Beacon != (common.Address{}) {
+		s += "，beacon " + i.Beacon.Hex()
+	}
+	return s
+}
+
+// Backend 是检测需要的节点接口，*ethclient.Client 满足
+type Backend interface {
+	CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error)
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+	CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error)
+}
+
+// Inspector 检测代理合约
+type Inspector struct {
+	backend Backend
+
+	Block *big.Int // 查询的区块，nil 表示最新
+}
+
+// New 创建检测器
+func New(backend Backend) *Inspector {
+	return &Inspector{backend: backend}
+}
+
+// MinimalProxyCode 返回指向 implementation 的 EIP-1167 运行时代码
+func MinimalProxyCode(implementation common.Address) []byte {
+	code := make([]byte, 0, len(minimalPrefix)+common.AddressLength+len(minimalSuffix))
+	code = append(code, minimalPrefix...)
+	code = append(code, implementation.Bytes()...)
+	return append(code, minimalSuffix...)
+}
+
+// Inspect 检测 addr 是否为代理，只看一层
+func (in *Inspector) Inspect(ctx context.Context, addr common.Address) (*Info, error) {
+	code, err := in.backend.CodeAt(ctx, addr, in.Block)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s 代码失败: %w", addr.Hex(), chainerr.Wrap(err))
+	}
+	info := &Info{Address: addr, CodeSize: len(code)}
+	if len(code) == 0 {
+		return info, nil
+	}
+
+	if len(code) == len(minimalPrefix)+common.AddressLength+len(minimalSuffix) &&
+		bytes.HasPrefix(code, minimalPrefix) && bytes.HasSuffix(code, minimalSuffix) {
+		info.Kind = Minimal
+		info.Implementation = common.BytesToAddress(code[len(minimalPrefix) : len(minimalPrefix)+common.AddressLength])
+		return info, nil
+	}
+
+	impl, err := in.slotAddress(ctx, addr, ImplementationSlot)
+	if err != nil {
+		return nil, err
+	}
+	if impl != (common.Address{}) {
+		info.Implementation = impl
+		if info.Admin, err = in.slotAddress(ctx, addr, AdminSlot); err != nil {
+			return nil, err
+		}
+		switch {
+		case info.Admin != (common.Address{}):
+			info.Kind = Transparent
+		case in.proxiable(ctx, impl):
+			info.Kind = UUPS
+		default:
+			info.Kind = EIP1967
+		}
+		return info, nil
+	}
+
+	beacon, err := in.slotAddress(ctx, addr, BeaconSlot)
+	if err != nil {
+		return nil, err
+	}
+	if beacon != (common.Address{}) {
+		out, err := in.backend.CallContract(ctx, ethereum.CallMsg{To: &beacon, Data: selectorImplementation}, in.Block)
+		if err != nil {
+			return nil, fmt.Errorf("查询信标 %s 的实现地址失败: %w", beacon.Hex(), chainerr.Wrap(err))
+		}
+		if len(out) < 32 {
+			return nil, fmt.Errorf("信标 %s 的 implementation() 返回 %d 字节", beacon.Hex(), len(out))
+		}
+		info.Kind, info.Beacon, info.Implementation = Beacon, beacon, common.BytesToAddress(out[:32])
+		return info, nil
+	}
+
+	if impl, err = in.slotAddress(ctx, addr, ProxiableSlot); err != nil {
+		return nil, err
+	}
+	if impl != (common.Address{}) {
+		info.Kind, info.Implementation = EIP1822, impl
+	}
+	return info, nil
+}
+
+// Resolve 跟随多层代理（例如指向 UUPS 代理的克隆），返回最终的实现合约和每一层的信息。
+// addr 不是代理时返回 addr 本身
+func (in *Inspector) Resolve(ctx context.Context, addr common.Address) (common.Address, []*Info, error) {
+	var chain []*Info
+	target := addr
+	for depth := 0; depth <= MaxDepth; depth++ {
+		info, err := in.Inspect(ctx, target)
+		if err != nil {
+			return common.Address{}, chain, err
+		}
+		if !info.IsProxy() {
+			return target, chain, nil
+		}
+		chain = append(chain, info)
+		target = info.Implementation
+	}
+	return common.Address{}, chain, fmt.Errorf("%w: %s", ErrTooDeep, addr.Hex())
+}
+
+// Resolution 是一个地址跟随代理后的结果
+type Resolution struct {
+	Address        common.Address `json:"address"`
+	Implementation common.Address `json:"implementation"` // 最终的实现合约，不是代理时为 Address 本身
+	Layers         []*Info        `json:"layers"`         // 每一层代理的信息，不是代理时为空
+}
+
+// IsProxy 判断地址是否为代理
+func (r *Resolution) IsProxy() bool { return len(r.Layers) > 0 }
+
+// BindRegistry 跟随 addrs 中的代理合约：实现合约在注册表中绑定了 ABI 时，把代理地址也绑定到该 ABI，
+// 代理没有标签时沿用实现合约的标签。返回每个地址的解析结果，调用方可直接使用而无需再次 Resolve
+func (in *Inspector) BindRegistry(ctx context.Context, reg *decoder.Registry, addrs ...common.Address) ([]*Resolution, error) {
+	out := make([]*Resolution, 0, len(addrs))
+	for _, addr := range addrs {
+		impl, layers, err := in.Resolve(ctx, addr)
+		if err != nil {
+			return out, err
+		}
+		out = append(out, &Resolution{Address: addr, Implementation: impl, Layers: layers})
+		if len(layers) == 0 {
+			continue
+		}
+		if name, _, ok := reg.BoundABI(impl); ok {
+			reg.Bind(addr, name)
+		}
+		if label := reg.Label(impl); label != "" && reg.Label(addr) == "" {
+			reg.SetLabel(addr, label+" (代理)")
+		}
+	}
+	return out, nil
+}
+
+func (in *Inspector) slotAddress(ctx context.Context, addr common.Address, slot common.Hash) (common.Address, error) {
+	value, err := in.backend.StorageAt(ctx, addr, slot, in.Block)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("读取 %s 的存储槽 %s 失败: %w", addr.Hex(), slot.Hex(), chainerr.Wrap(err))
+	}
+	return common.BytesToAddress(value), nil
+}
+
+// proxiable 判断实现合约的 proxiableUUID() 是否返回 EIP-1967 实现槽（UUPS）
+func (in *Inspector) proxiable(ctx context.Context, impl common.Address) bool {
+	out, err := in.backend.CallContract(ctx, ethereum.CallMsg{To: &impl, Data: selectorProxiableUUID}, in.Block)
+	return err == nil && len(out) >= 32 && common.BytesToHash(out[:32]) == ImplementationSlot
+}
@@ -0,0 +1,178 @@
+package proxy
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/decoder"
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// testdata/proxies.json 由 solc 编译 testdata/Proxies.sol 得到
+//
+//go:embed testdata/proxies.json
+var proxiesJSON []byte
+
+type artifact struct {
+	abi abi.ABI
+	bin []byte
+}
+
+func loadArtifacts(t *testing.T) map[string]artifact {
+	t.Helper()
+	var combined struct {
+		Contracts map[string]struct {
+			ABI json.RawMessage `json:"abi"`
+			Bin string          `json:"bin"`
+		} `json:"contracts"`
+	}
+	if err := json.Unmarshal(proxiesJSON, &combined); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	out := make(map[string]artifact)
+	for name, c := range combined.Contracts {
+		parsed, err := abi.JSON(strings.NewReader(string(c.ABI)))
+		if err != nil {
+			t.Fatalf("❌ %v", err)
+		}
+		out[strings.TrimPrefix(name, "Proxies.sol:")] = artifact{abi: parsed, bin: common.FromHex(c.Bin)}
+	}
+	return out
+}
+
+// 测试各类代理的识别、多层代理的解析，以及解码器使用实现合约的 ABI
+func TestInspect(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t)
+	artifacts := loadArtifacts(t)
+	deploy := func(name string, args ...interface{}) common.Address {
+		t.Helper()
+		a := artifacts[name]
+		addr, tx, _, err := bind.DeployContract(chain.Auth(0), a.abi, a.bin, chain.Client, args...)
+		if err != nil {
+			t.Fatalf("❌ 部署 %s 失败: %v", name, err)
+		}
+		chain.WaitMined(tx)
+		return addr
+	}
+	admin := chain.Accounts[1].Address
+
+	counter := deploy("Counter")
+	uupsImpl := deploy("CounterUUPS")
+	plain := deploy("SlotProxy", ImplementationSlot, counter, common.Address{})
+	transparent := deploy("SlotProxy", ImplementationSlot, counter, admin)
+	uups := deploy("SlotProxy", ImplementationSlot, uupsImpl, common.Address{})
+	eip1822 := deploy("SlotProxy", ProxiableSlot, counter, common.Address{})
+	beacon := deploy("Beacon", counter)
+	beaconProxy := deploy("BeaconProxy", beacon)
+	// EIP-1167 的创建代码：把紧随其后的 45 字节运行时代码返回；克隆指向 UUPS 代理，形成两层代理
+	artifacts["Clone"] = artifact{bin: append(common.FromHex("0x3d602d80600a3d3981f3"), MinimalProxyCode(uups)...)}
+	clone := deploy("Clone")
+
+	in := New(chain.Client)
+	cases := []struct {
+		addr common.Address
+		want Info
+	}{
+		{counter, Info{Kind: None}},
+		{plain, Info{Kind: EIP1967, Implementation: counter}},
+		{transparent, Info{Kind: Transparent, Implementation: counter, Admin: admin}},
+		{uups, Info{Kind: UUPS, Implementation: uupsImpl}},
+		{eip1822, Info{Kind: EIP1822, Implementation: counter}},
+		{beaconProxy, Info{Kind: Beacon, Implementation: counter, Beacon: beacon}},
+		{clone, Info{Kind: Minimal, Implementation: uups}},
+	}
+	for _, c := range cases {
+		info, err := in.Inspect(ctx, c.addr)
+		if err != nil {
+			t.Fatalf("❌ %v", err)
+		}
+		if info.Kind != c.want.Kind || info.Implementation != c.want.Implementation || info.Admin != c.want.Admin || info.Beacon != c.want.Beacon {
+			t.Fatalf("❌ %s 检测结果 %v，期望 %s", c.addr.Hex(), info, c.want.Kind)
+		}
+	}
+
+	impl, chainInfo, err := in.Resolve(ctx, clone)
+	if err != nil || impl != uupsImpl || len(chainInfo) != 2 {
+		t.Fatalf("❌ 多层代理应解析到 UUPS 实现: %s %v %v", impl.Hex(), chainInfo, err)
+	}
+	if impl, chainInfo, _ := in.Resolve(ctx, counter); impl != counter || len(chainInfo) != 0 {
+		t.Fatal("❌ 非代理合约应返回自身")
+	}
+
+	if _, _, err := in.Resolve(ctx, deploy("SlotProxy", ImplementationSlot, clone, common.Address{})); err != nil {
+		t.Fatalf("❌ 三层代理在 MaxDepth 之内: %v", err)
+	}
+
+	// 通过代理调用实现合约的方法，确认测试合约本身正确
+	bound := bind.NewBoundContract(beaconProxy, artifacts["Counter"].abi, chain.Client, chain.Client, chain.Client)
+	tx, err := bound.Transact(chain.Auth(0), "increment")
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	chain.WaitMined(tx)
+	var out []interface{}
+	if err := bound.Call(&bind.CallOpts{}, &out, "count"); err != nil || out[0].(*big.Int).Int64() != 1 {
+		t.Fatalf("❌ 通过信标代理调用失败: %v %v", out, err)
+	}
+
+	// 解码器：代理地址使用实现合约的 ABI
+	reg := decoder.NewRegistry()
+	reg.AddABI("Counter", artifacts["Counter"].abi)
+	reg.AddABI("Other", artifacts["Beacon"].abi)
+	reg.Bind(counter, "Counter")
+	reg.SetLabel(counter, "Counter")
+	resolved, err := in.BindRegistry(ctx, reg, transparent, beaconProxy, counter)
+	if err != nil || len(resolved) != 3 || resolved[2].IsProxy() || resolved[2].Implementation != counter {
+		t.Fatalf("❌ %v %v", resolved, err)
+	}
+	for _, r := range resolved[:2] {
+		if !r.IsProxy() || r.Implementation != counter {
+			t.Fatalf("❌ %s 应解析到实现合约 %s: %+v", r.Address.Hex(), counter.Hex(), r)
+		}
+	}
+	for _, addr := range []common.Address{transparent, beaconProxy} {
+		if name, _, ok := reg.BoundABI(addr); !ok || name != "Counter" || reg.Label(addr) != "Counter (代理)" {
+			t.Fatalf("❌ 代理 %s 应绑定实现合约的 ABI: %s %q", addr.Hex(), name, reg.Label(addr))
+		}
+	}
+	call := decoder.New(reg).Decode(&beaconProxy, artifacts["Counter"].abi.Methods["increment"].ID)
+	if call.Contract != "Counter" || call.Method != "increment" {
+		t.Fatalf("❌ 解码结果 %+v", call)
+	}
+	t.Logf("✅ %v", resolved[1].Layers[0])
+}
+
+// codeBackend 只提供代码，存储槽全为 0
+type codeBackend map[common.Address][]byte
+
+func (b codeBackend) CodeAt(_ context.Context, addr common.Address, _ *big.Int) ([]byte, error) {
+	return b[addr], nil
+}
+
+func (b codeBackend) StorageAt(context.Context, common.Address, common.Hash, *big.Int) ([]byte, error) {
+	return make([]byte, 32), nil
+}
+
+func (b codeBackend) CallContract(context.Context, ethereum.CallMsg, *big.Int) ([]byte, error) {
+	return nil, nil
+}
+
+// 互相指向的代理
+func TestResolveLoop(t *testing.T) {
+	a := common.HexToAddress("0x00000000000000000000000000000000000000aa")
+	b := common.HexToAddress("0x00000000000000000000000000000000000000bb")
+	backend := codeBackend{a: MinimalProxyCode(b), b: MinimalProxyCode(a)}
+	if _, _, err := New(backend).Resolve(context.Background(), a); !errors.Is(err, ErrTooDeep) {
+		t.Fatalf("❌ 互相指向的代理应返回 ErrTooDeep: %v", err)
+	}
+}
@@ -0,0 +1,85 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.26;
+
+// 测试用的各类代理合约，只实现检测和转发需要的最小功能
+
+contract Counter {
+    uint256 public count;
+
+    function increment() external {
+        count += 1;
+    }
+}
+
+// UUPS 实现合约：proxiableUUID 返回 EIP-1967 实现槽
+contract CounterUUPS is Counter {
+    function proxiableUUID() external pure returns (bytes32) {
+        return 0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc;
+    }
+}
+
+// 把实现地址保存在指定存储槽的代理（EIP-1967 / EIP-1822），admin 不为 0 时写入 EIP-1967 admin 槽（透明代理）
+contract SlotProxy {
+    bytes32 private immutable slot;
+
+    constructor(bytes32 _slot, address implementation, address admin) {
+        slot = _slot;
+        assembly {
+            sstore(_slot, implementation)
+        }
+        if (admin != address(0)) {
+            assembly {
+                sstore(0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103, admin)
+            }
+        }
+    }
+
+    fallback() external payable {
+        bytes32 s = slot;
+        address implementation;
+        assembly {
+            implementation := sload(s)
+        }
+        _delegate(implementation);
+    }
+}
+
+contract Beacon {
+    address public implementation;
+
+    constructor(address _implementation) {
+        implementation = _implementation;
+    }
+}
+
+// EIP-1967 信标代理：实现地址由信标合约的 implementation() 给出
+contract BeaconProxy {
+    constructor(address beacon) {
+        assembly {
+            sstore(0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50, beacon)
+        }
+    }
+
+    fallback() external payable {
+        address beacon;
+        assembly {
+            beacon := sload(0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50)
+        }
+        _delegate(Beacon(beacon).implementation());
+    }
+}
+
+function _delegate(address implementation) {
+    assembly {
+        calldatacopy(0, 0, calldatasize())
+        let result := delegatecall(gas(), implementation, 0, calldatasize(), 0, 0)
+        returndatacopy(0, 0, returndatasize())
+        switch result
+        case 0 {
+            revert(0, returndatasize())
+        }
+        default {
+            return(0, returndatasize())
+        }
+    }
+}
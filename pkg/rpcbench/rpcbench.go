@@ -0,0 +1,339 @@
+// Package rpcbench 对一个或多个 JSON-RPC 节点做延迟与可靠性压测：按方法组合和并发级别发送请求，
+// 统计 p50/p95/p99 延迟、错误率、限流响应以及节点之间的区块高度差
+package rpcbench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Methods 是支持的压测方法
+var Methods = []string{"blockNumber", "chainId", "gasPrice", "getBalance", "getBlock", "getLogs", "call", "batch"}
+
+// Options 是压测参数
+type Options struct {
+	Mix         []string      // 方法组合，可带权重，例如 "getBalance:3"；为空时使用 blockNumber,getBalance,call
+	Concurrency []int         // 并发级别，为空时为 1
+	Requests    int           // 每个并发级别发送的请求数，默认 100
+	Timeout     time.Duration // 单个请求超时，默认 10 秒
+	Address     common.Address
+	CallTo      common.Address // eth_call 的目标地址，默认与 Address 相同
+	CallData    []byte
+	LogRange    uint64 // eth_getLogs 查询最近多少个区块，默认 100
+	BatchSize   int    // 批量请求的条数，默认 10
+}
+
+func (o *Options) defaults() {
+	if len(o.Mix) == 0 {
+		o.Mix = []string{"blockNumber", "getBalance", "call"}
+	}
+	if len(o.Concurrency) == 0 {
+		o.Concurrency = []int{1}
+	}
+	if o.Requests <= 0 {
+		o.Requests = 100
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.CallTo == (common.Address{}) {
+		o.CallTo = o.Address
+	}
+	if o.LogRange == 0 {
+		o.LogRange = 100
+	}
+	if o.BatchSize <= 0 {
+		o.BatchSize = 10
+	}
+}
+
+// ParseMix 解析方法组合，返回按权重展开的调度序列
+func ParseMix(mix []string) ([]string, error) {
+	var schedule []string
+	for _, item := range mix {
+		name, weight := item, 1
+		if i := strings.IndexByte(item, ':'); i >= 0 {
+			w, err := strconv.Atoi(item[i+1:])
+			if err != nil || w <= 0 {
+				return nil, fmt.Errorf("无效的方法权重 %q", item)
+			}
+			name, weight = item[:i], w
+		}
+		if !known(name) {
+			return nil, fmt.Errorf("不支持的方法 %q，可选: %s", name, strings.Join(Methods, ", "))
+		}
+		for i := 0; i < weight; i++ {
+			schedule = append(schedule, name)
+		}
+	}
+	if len(schedule) == 0 {
+		return nil, errors.New("方法组合为空")
+	}
+	return schedule, nil
+}
+
+func known(name string) bool {
+	for _, m := range Methods {
+		if m == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Endpoint 是一个节点的压测结果
+type Endpoint struct {
+	URL     string   `json:"url"` // 已隐藏 API Key
+	Head    uint64   `json:"head"`
+	HeadLag uint64   `json:"headLag"` // 与所有节点中最高区块的差距
+	Error   string   `json:"error,omitempty"`
+	Levels  []*Level `json:"levels"`
+}
+
+// Level 是一个并发级别的结果
+type Level struct {
+	Concurrency int      `json:"concurrency"`
+	Duration    Millis   `json:"durationMs"`
+	Throughput  float64  `json:"throughput"` // 每秒完成的请求数
+	Total       *Stats   `json:"total"`
+	Methods     []*Stats `json:"methods"`
+}
+
+// Report 是完整压测报告
+type Report struct {
+	Started   time.Time   `json:"started"`
+	Mix       []string    `json:"mix"`
+	Requests  int         `json:"requests"`
+	Endpoints []*Endpoint `json:"endpoints"`
+}
+
+// Run 依次压测每个节点的每个并发级别。单个节点无法连接时记录在报告中，不影响其他节点
+func Run(ctx context.Context, urls []string, opts Options) (*Report, error) {
+	opts.defaults()
+	schedule, err := ParseMix(opts.Mix)
+	if err != nil {
+		return nil, err
+	}
+	report := &Report{Started: time.Now().UTC(), Mix: opts.Mix, Requests: opts.Requests}
+
+	clients := make([]*rpc.Client, len(urls))
+	for i, u := range urls {
+		ep := &Endpoint{URL: Redact(u)}
+		report.Endpoints = append(report.Endpoints, ep)
+		c, err := rpc.DialContext(ctx, u)
+		if err != nil {
+			ep.Error = err.Error()
+			continue
+		}
+		defer c.Close()
+		clients[i] = c
+	}
+
+	sampleHeads(ctx, clients, report.Endpoints, opts.Timeout)
+
+	for i, c := range clients {
+		ep := report.Endpoints[i]
+		if c == nil || ep.Error != "" {
+			continue
+		}
+		b := &bench{client: c, opts: &opts, head: ep.Head}
+		for _, n := range opts.Concurrency {
+			level, err := b.run(ctx, n, schedule)
+			if err != nil {
+				return nil, err
+			}
+			ep.Levels = append(ep.Levels, level)
+		}
+	}
+	return report, nil
+}
+
+// sampleHeads 同时查询所有节点的最新区块，计算相对最高区块的落后量
+func sampleHeads(ctx context.Context, clients []*rpc.Client, eps []*Endpoint, timeout time.Duration) {
+	var wg sync.WaitGroup
+	for i, c := range clients {
+		if c == nil {
+			continue
+		}
+		wg.Add(1)
+		go func(c *rpc.Client, ep *Endpoint) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			var head hexutil.Uint64
+			if err := c.CallContext(ctx, &head, "eth_blockNumber"); err != nil {
+				ep.Error = fmt.Sprintf("获取最新区块失败: %v", err)
+				return
+			}
+			ep.Head = uint64(head)
+		}(c, eps[i])
+	}
+	wg.Wait()
+
+	var highest uint64
+	for _, ep := range eps {
+		if ep.Error == "" && ep.Head > highest {
+			highest = ep.Head
+		}
+	}
+	for _, ep := range eps {
+		if ep.Error == "" {
+			ep.HeadLag = highest - ep.Head
+		}
+	}
+}
+
+type sample struct {
+	method  string
+	latency time.Duration
+	err     error
+}
+
+type bench struct {
+	client *rpc.Client
+	opts   *Options
+	head   uint64
+}
+
+// run 用 n 个并发协程发送 Requests 个请求，方法按调度序列轮转
+func (b *bench) run(ctx context.Context, n int, schedule []string) (*Level, error) {
+	if n <= 0 {
+		return nil, fmt.Errorf("无效的并发数 %d", n)
+	}
+	jobs := make(chan string)
+	samples := make(chan sample, b.opts.Requests)
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for method := range jobs {
+				reqCtx, cancel := context.WithTimeout(ctx, b.opts.Timeout)
+				t := time.Now()
+				err := b.do(reqCtx, method)
+				samples <- sample{method: method, latency: time.Since(t), err: err}
+				cancel()
+			}
+		}()
+	}
+	for i := 0; i < b.opts.Requests; i++ {
+		select {
+		case jobs <- schedule[i%len(schedule)]:
+		case <-ctx.Done():
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(samples)
+	elapsed := time.Since(start)
+
+	byMethod := make(map[string]*collector)
+	total := &collector{}
+	for s := range samples {
+		c, ok := byMethod[s.method]
+		if !ok {
+			c = &collector{}
+			byMethod[s.method] = c
+		}
+		c.add(s)
+		total.add(s)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	level := &Level{Concurrency: n, Duration: Millis(elapsed), Total: total.stats("total")}
+	if elapsed > 0 {
+		level.Throughput = float64(total.count) / elapsed.Seconds()
+	}
+	for _, m := range Methods {
+		if c, ok := byMethod[m]; ok {
+			level.Methods = append(level.Methods, c.stats(m))
+		}
+	}
+	return level, nil
+}
+
+// do 发送一个指定方法的请求
+func (b *bench) do(ctx context.Context, method string) error {
+	var raw interface{}
+	switch method {
+	case "blockNumber":
+		return b.client.CallContext(ctx, &raw, "eth_blockNumber")
+	case "chainId":
+		return b.client.CallContext(ctx, &raw, "eth_chainId")
+	case "gasPrice":
+		return b.client.CallContext(ctx, &raw, "eth_gasPrice")
+	case "getBalance":
+		return b.client.CallContext(ctx, &raw, "eth_getBalance", b.opts.Address, "latest")
+	case "getBlock":
+		return b.client.CallContext(ctx, &raw, "eth_getBlockByNumber", "latest", false)
+	case "getLogs":
+		from := uint64(0)
+		if b.head > b.opts.LogRange {
+			from = b.head - b.opts.LogRange
+		}
+		filter := map[string]interface{}{
+			"fromBlock": hexutil.EncodeUint64(from),
+			"toBlock":   hexutil.EncodeUint64(b.head),
+			"address":   b.opts.CallTo,
+		}
+		return b.client.CallContext(ctx, &raw, "eth_getLogs", filter)
+	case "call":
+		call := map[string]interface{}{"to": b.opts.CallTo, "data": hexutil.Bytes(b.opts.CallData)}
+		return b.client.CallContext(ctx, &raw, "eth_call", call, "latest")
+	case "batch":
+		batch := make([]rpc.BatchElem, b.opts.BatchSize)
+		results := make([]interface{}, len(batch))
+		for i := range batch {
+			if i%2 == 0 {
+				batch[i] = rpc.BatchElem{Method: "eth_getBalance", Args: []interface{}{b.opts.Address, hexutil.EncodeBig(new(big.Int).SetUint64(b.head))}, Result: &results[i]}
+			} else {
+				batch[i] = rpc.BatchElem{Method: "eth_blockNumber", Result: &results[i]}
+			}
+		}
+		if err := b.client.BatchCallContext(ctx, batch); err != nil {
+			return err
+		}
+		for _, el := range batch {
+			if el.Error != nil {
+				return el.Error
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("不支持的方法 %q", method)
+}
+
+// Redact 隐藏 URL 中的 API Key（较长的路径段和查询参数），便于分享报告
+func Redact(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	segments := strings.Split(u.Path, "/")
+	for i, s := range segments {
+		if len(s) >= 16 {
+			segments[i] = s[:4] + "***"
+		}
+	}
+	u.Path = strings.Join(segments, "/")
+	if u.RawQuery != "" {
+		u.RawQuery = "***"
+	}
+	u.User = nil
+	return u.String()
+}
@@ -0,0 +1,87 @@
+package rpcbench
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+)
+
+// 测试多节点、多并发级别压测：延迟统计、区块高度差和限流识别
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	ahead := simchain.New(t)
+	for i := 0; i < 3; i++ {
+		ahead.Commit()
+	}
+	behind := simchain.New(t)
+	opts := Options{
+		Mix:         []string{"blockNumber:2", "getBalance", "getLogs", "call", "batch"},
+		Concurrency: []int{1, 4},
+		Requests:    30,
+		Address:     ahead.Accounts[0].Address,
+		BatchSize:   4,
+	}
+	report, err := Run(ctx, []string{ahead.HTTPURL(), behind.HTTPURL()}, opts)
+	if err != nil {
+		t.Fatalf("❌ 压测失败: %v", err)
+	}
+	if len(report.Endpoints) != 2 {
+		t.Fatalf("❌ 节点数不符: %d", len(report.Endpoints))
+	}
+	a, b := report.Endpoints[0], report.Endpoints[1]
+	if a.HeadLag != 0 || b.HeadLag != 3 {
+		t.Fatalf("❌ 区块高度差不符: %d %d", a.HeadLag, b.HeadLag)
+	}
+	for _, level := range a.Levels {
+		if level.Total.Count != 30 || level.Total.Errors != 0 {
+			t.Fatalf("❌ 并发 %d 统计不符: %+v", level.Concurrency, level.Total)
+		}
+		if len(level.Methods) != 5 || level.Total.P50 > level.Total.P99 || level.Total.P99 > level.Total.Max {
+			t.Fatalf("❌ 方法统计不符: %+v", level.Methods)
+		}
+	}
+	if _, err := json.Marshal(report); err != nil {
+		t.Fatalf("❌ 编码报告失败: %v", err)
+	}
+	t.Logf("✅ 并发 4 吞吐 %.0f req/s，p95 %s", a.Levels[1].Throughput, a.Levels[1].Total.P95)
+}
+
+// 测试限流响应的统计：eth_blockNumber 正常返回，其他请求返回 HTTP 429
+func TestRateLimited(t *testing.T) {
+	limited := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		if json.NewDecoder(r.Body).Decode(&req) == nil && req.Method == "eth_blockNumber" {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"jsonrpc":"2.0","id":` + string(req.ID) + `,"result":"0x10"}`))
+			return
+		}
+		http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+	}))
+	defer limited.Close()
+
+	report, err := Run(context.Background(), []string{limited.URL + "/v2/0123456789abcdef0123"}, Options{
+		Mix:      []string{"blockNumber", "getBalance"},
+		Requests: 10,
+		Timeout:  time.Second,
+	})
+	if err != nil {
+		t.Fatalf("❌ 压测失败: %v", err)
+	}
+	ep := report.Endpoints[0]
+	if strings.Contains(ep.URL, "0123456789abcdef0123") {
+		t.Fatalf("❌ 报告中的 URL 应隐藏 API Key: %s", ep.URL)
+	}
+	total := ep.Levels[0].Total
+	if ep.Head != 16 || total.Errors != 5 || total.RateLimited != 5 || total.ErrorRate != 0.5 {
+		t.Fatalf("❌ 限流统计不符: head=%d %+v", ep.Head, total)
+	}
+}
@@ -0,0 +1,112 @@
+package rpcbench
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+)
+
+// Millis 是以毫秒输出到 JSON 的时长
+type Millis time.Duration
+
+// MarshalJSON 输出保留三位小数的毫秒数
+func (m Millis) MarshalJSON() ([]byte, error) {
+	return json.Marshal(math.Round(float64(m)/float64(time.Millisecond)*1000) / 1000)
+}
+
+// UnmarshalJSON 读取毫秒数，便于加载历史报告做对比
+func (m *Millis) UnmarshalJSON(data []byte) error {
+	var ms float64
+	if err := json.Unmarshal(data, &ms); err != nil {
+		return err
+	}
+	*m = Millis(ms * float64(time.Millisecond))
+	return nil
+}
+
+func (m Millis) String() string { return time.Duration(m).Round(time.Microsecond).String() }
+
+// Stats 是一组请求的统计
+type Stats struct {
+	Method      string  `json:"method"`
+	Count       int     `json:"count"`
+	Errors      int     `json:"errors"` // 包含限流和超时
+	RateLimited int     `json:"rateLimited"`
+	Timeouts    int     `json:"timeouts"`
+	ErrorRate   float64 `json:"errorRate"`
+	P50         Millis  `json:"p50Ms"`
+	P95         Millis  `json:"p95Ms"`
+	P99         Millis  `json:"p99Ms"`
+	Mean        Millis  `json:"meanMs"`
+	Max         Millis  `json:"maxMs"`
+	LastError   string  `json:"lastError,omitempty"`
+}
+
+type collector struct {
+	count, errors, rateLimited, timeouts int
+	latencies                            []time.Duration // 只统计成功请求
+	lastError                            string
+}
+
+func (c *collector) add(s sample) {
+	c.count++
+	if s.err == nil {
+		c.latencies = append(c.latencies, s.latency)
+		return
+	}
+	c.errors++
+	c.lastError = s.err.Error()
+	switch {
+	case IsRateLimited(s.err):
+		c.rateLimited++
+	case errors.Is(s.err, context.DeadlineExceeded):
+		c.timeouts++
+	}
+}
+
+func (c *collector) stats(method string) *Stats {
+	s := &Stats{
+		Method:      method,
+		Count:       c.count,
+		Errors:      c.errors,
+		RateLimited: c.rateLimited,
+		Timeouts:    c.timeouts,
+		LastError:   c.lastError,
+	}
+	if c.count > 0 {
+		s.ErrorRate = float64(c.errors) / float64(c.count)
+	}
+	if len(c.latencies) == 0 {
+		return s
+	}
+	sort.Slice(c.latencies, func(i, j int) bool { return c.latencies[i] < c.latencies[j] })
+	var sum time.Duration
+	for _, l := range c.latencies {
+		sum += l
+	}
+	s.P50 = Millis(percentile(c.latencies, 50))
+	s.P95 = Millis(percentile(c.latencies, 95))
+	s.P99 = Millis(percentile(c.latencies, 99))
+	s.Mean = Millis(sum / time.Duration(len(c.latencies)))
+	s.Max = Millis(c.latencies[len(c.latencies)-1])
+	return s
+}
+
+// percentile 使用最近秩法计算百分位，sorted 必须已排序且非空
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// IsRateLimited 判断错误是否为节点限流：HTTP 429，或 JSON-RPC 错误码/消息表明请求过多
+func IsRateLimited(err error) bool {
+	return chainerr.Is(err, chainerr.RateLimited)
+}
@@ -0,0 +1,306 @@
+package rpcx
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// DefaultBatchSize 是单个批量请求的默认最大条数（多数服务商限制在 100 ~ 1000 之间）
+const DefaultBatchSize = 100
+
+// Batcher 把大量读请求合并为 JSON-RPC 批量请求：按 MaxSize 分块，
+// 遇到服务商的批量大小或响应大小限制时自动二分重试，并记住更小的分块大小
+type Batcher struct {
+	client *rpc.Client
+
+	mu   sync.Mutex
+	size int
+}
+
+// NewBatcher 创建批量请求器，maxSize <= 0 时使用 DefaultBatchSize
+func NewBatcher(client *rpc.Client, maxSize int) *Batcher {
+	if maxSize <= 0 {
+		maxSize = DefaultBatchSize
+	}
+	return &Batcher{client: client, size: maxSize}
+}
+
+// MaxSize 返回当前分块大小（触发服务商限制后会变小）
+func (b *Batcher) MaxSize() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.size
+}
+
+func (b *Batcher) shrink(size int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if size < b.size {
+		b.size = size
+	}
+}
+
+// Call 发送任意数量的请求。返回的错误只表示传输失败，单条请求的错误写在对应元素的 Error 中
+func (b *Batcher) Call(ctx context.Context, elems []rpc.BatchElem) error {
+	for start := 0; start < len(elems); {
+		end := start + b.MaxSize()
+		if end > len(elems) {
+			end = len(elems)
+		}
+		if err := b.send(ctx, elems[start:end]); err != nil {
+			return err
+		}
+		start = end
+	}
+	return nil
+}
+
+// send 发送一个分块，被服务商限制时对半拆分后重试
+func (b *Batcher) send(ctx context.Context, chunk []rpc.BatchElem) error {
+	for i := range chunk {
+		chunk[i].Error = nil
+	}
+	err := b.client.BatchCallContext(ctx, chunk)
+	if err == nil && !limited(chunk) {
+		return nil
+	}
+	if err != nil && !isLimitError(err) {
+		return fmt.Errorf("批量请求失败: %w", err)
+	}
+	if len(chunk) == 1 {
+		if err != nil {
+			chunk[0].Error = err
+		}
+		return nil
+	}
+	half := len(chunk) / 2
+	b.shrink(half)
+	if err := b.send(ctx, chunk[:half]); err != nil {
+		return err
+	}
+	return b.send(ctx, chunk[half:])
+}
+
+// limited 判断批量响应是否因服务商限制而不完整
+func limited(chunk []rpc.BatchElem) bool {
+	for _, el := range chunk {
+		if el.Error != nil && (errors.Is(el.Error, rpc.ErrMissingBatchResponse) || isLimitError(el.Error)) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLimitError 识别批量大小/响应大小超限：HTTP 413，或 geth 等节点返回的 batch too large / response too large
+func isLimitError(err error) bool {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusRequestEntityTooLarge {
+		return true
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range []string{"batch too large", "batch size", "batch limit", "response too large", "too many batch"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// BatchError 汇总批量请求中失败的条目，Errs 与输入一一对应，成功的条目为 nil
+type BatchError struct {
+	Errs []error
+}
+
+func (e *BatchError) Error() string {
+	var failed []string
+	for i, err := range e.Errs {
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("#%d: %v", i, err))
+			if len(failed) == 3 {
+				break
+			}
+		}
+	}
+	return fmt.Sprintf("%d/%d 条请求失败: %s", e.Failed(), len(e.Errs), strings.Join(failed, "; "))
+}
+
+// Failed 返回失败条数
+func (e *BatchError) Failed() int {
+	n := 0
+	for _, err := range e.Errs {
+		if err != nil {
+			n++
+		}
+	}
+	return n
+}
+
+// Err 返回第 i 条请求的错误
+func (e *BatchError) Err(i int) error {
+	return e.Errs[i]
+}
+
+// collect 把元素错误汇总为 *BatchError，全部成功时返回 nil
+func collect(elems []rpc.BatchElem) error {
+	errs := make([]error, len(elems))
+	failed := false
+	for i, el := range elems {
+		if el.Error != nil {
+			errs[i], failed = el.Error, true
+		}
+	}
+	if !failed {
+		return nil
+	}
+	return &BatchError{Errs: errs}
+}
+
+func blockArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	if number.Sign() < 0 {
+		return rpc.BlockNumber(number.Int64()).String()
+	}
+	return hexutil.EncodeBig(number)
+}
+
+// BalancesAt 批量查询余额，block 为 nil 表示最新区块。
+// 部分失败时返回 *BatchError，失败条目的余额为 nil
+func (b *Batcher) BalancesAt(ctx context.Context, addrs []common.Address, block *big.Int) ([]*big.Int, error) {
+	results := make([]*hexutil.Big, len(addrs))
+	elems := make([]rpc.BatchElem, len(addrs))
+	for i, addr := range addrs {
+		elems[i] = rpc.BatchElem{Method: "eth_getBalance", Args: []interface{}{addr, blockArg(block)}, Result: &results[i]}
+	}
+	if err := b.Call(ctx, elems); err != nil {
+		return nil, err
+	}
+	balances := make([]*big.Int, len(addrs))
+	for i, r := range results {
+		if elems[i].Error == nil && r != nil {
+			balances[i] = r.ToInt()
+		}
+	}
+	return balances, collect(elems)
+}
+
+// CallContracts 批量执行 eth_call。部分失败（包括 revert）时返回 *BatchError，失败条目的结果为 nil
+func (b *Batcher) CallContracts(ctx context.Context, msgs []ethereum.CallMsg, block *big.Int) ([][]byte, error) {
+	results := make([]hexutil.Bytes, len(msgs))
+	elems := make([]rpc.BatchElem, len(msgs))
+	for i, msg := range msgs {
+		elems[i] = rpc.BatchElem{Method: "eth_call", Args: []interface{}{toCallArg(msg), blockArg(block)}, Result: &results[i]}
+	}
+	if err := b.Call(ctx, elems); err != nil {
+		return nil, err
+	}
+	out := make([][]byte, len(msgs))
+	for i, r := range results {
+		if elems[i].Error == nil {
+			out[i] = r
+		}
+	}
+	return out, collect(elems)
+}
+
+// Receipts 批量查询交易收据。不存在的交易对应 ethereum.NotFound
+func (b *Batcher) Receipts(ctx context.Context, hashes []common.Hash) ([]*types.Receipt, error) {
+	receipts := make([]*types.Receipt, len(hashes))
+	elems := make([]rpc.BatchElem, len(hashes))
+	for i, h := range hashes {
+		elems[i] = rpc.BatchElem{Method: "eth_getTransactionReceipt", Args: []interface{}{h}, Result: &receipts[i]}
+	}
+	if err := b.Call(ctx, elems); err != nil {
+		return nil, err
+	}
+	for i := range elems {
+		if elems[i].Error == nil && receipts[i] == nil {
+			elems[i].Error = ethereum.NotFound
+		}
+	}
+	return receipts, collect(elems)
+}
+
+// rpcBlock 是 eth_getBlockByNumber(number, true) 返回的区块体
+type rpcBlock struct {
+	Transactions []*types.Transaction `json:"transactions"`
+	Withdrawals  []*types.Withdrawal  `json:"withdrawals,omitempty"`
+}
+
+// BlocksByNumber 批量获取包含完整交易的区块。不存在的区块对应 ethereum.NotFound。
+// 叔块只有哈希而不含区块头，合并后的以太坊上没有叔块，这里不再逐个查询
+func (b *Batcher) BlocksByNumber(ctx context.Context, numbers []uint64) ([]*types.Block, error) {
+	raws := make([]json.RawMessage, len(numbers))
+	elems := make([]rpc.BatchElem, len(numbers))
+	for i, n := range numbers {
+		elems[i] = rpc.BatchElem{Method: "eth_getBlockByNumber", Args: []interface{}{hexutil.EncodeUint64(n), true}, Result: &raws[i]}
+	}
+	if err := b.Call(ctx, elems); err != nil {
+		return nil, err
+	}
+	blocks := make([]*types.Block, len(numbers))
+	for i, raw := range raws {
+		if elems[i].Error != nil {
+			continue
+		}
+		if len(raw) == 0 || string(raw) == "null" {
+			elems[i].Error = ethereum.NotFound
+			continue
+		}
+		var head types.Header
+		var body rpcBlock
+		if err := json.Unmarshal(raw, &head); err != nil {
+			elems[i].Error = fmt.Errorf("解析区块头失败: %w", err)
+			continue
+		}
+		if err := json.Unmarshal(raw, &body); err != nil {
+			elems[i].Error = fmt.Errorf("解析区块交易失败: %w", err)
+			continue
+		}
+		blocks[i] = types.NewBlockWithHeader(&head).WithBody(types.Body{Transactions: body.Transactions, Withdrawals: body.Withdrawals})
+	}
+	return blocks, collect(elems)
+}
+
+// toCallArg 把 CallMsg 转为 eth_call 的参数（与 ethclient 的编码一致）
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.GasPrice != nil {
+		arg["gasPrice"] = (*hexutil.Big)(msg.GasPrice)
+	}
+	if msg.GasFeeCap != nil {
+		arg["maxFeePerGas"] = (*hexutil.Big)(msg.GasFeeCap)
+	}
+	if msg.GasTipCap != nil {
+		arg["maxPriorityFeePerGas"] = (*hexutil.Big)(msg.GasTipCap)
+	}
+	if msg.AccessList != nil {
+		arg["accessList"] = msg.AccessList
+	}
+	return arg
+}
@@ -0,0 +1,130 @@
+package rpcx
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 测试类型化批量查询：分块、逐条错误映射以及与单个查询结果一致
+func TestBatcher(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t)
+	tokenAddr, token := chain.DeployToken("IWS Token", "IWS", big.NewInt(1_000_000))
+
+	var hashes []common.Hash
+	for i := 1; i < len(chain.Accounts); i++ {
+		tx, err := token.Transfer(chain.Auth(0), chain.Accounts[i].Address, big.NewInt(int64(i)))
+		if err != nil {
+			t.Fatalf("❌ 转账失败: %v", err)
+		}
+		hashes = append(hashes, tx.Hash())
+	}
+	chain.Commit()
+	head, _ := chain.Client.BlockNumber(ctx)
+
+	b := NewBatcher(chain.RPC(), 2)
+
+	// 余额：4 个账户 + 1 个空地址，按 2 条分块
+	addrs := []common.Address{common.HexToAddress("0x0000000000000000000000000000000000000abc")}
+	for _, a := range chain.Accounts {
+		addrs = append(addrs, a.Address)
+	}
+	balances, err := b.BalancesAt(ctx, addrs, nil)
+	if err != nil {
+		t.Fatalf("❌ 批量查询余额失败: %v", err)
+	}
+	for i, addr := range addrs {
+		want, _ := chain.Client.BalanceAt(ctx, addr, nil)
+		if balances[i].Cmp(want) != 0 {
+			t.Fatalf("❌ %s 余额不符: %s != %s", addr.Hex(), balances[i], want)
+		}
+	}
+
+	// 收据：不存在的交易映射为 ethereum.NotFound，其余正常返回
+	missing := common.HexToHash("0x01")
+	receipts, err := b.Receipts(ctx, append(hashes, missing))
+	var batchErr *BatchError
+	if !errors.As(err, &batchErr) || batchErr.Failed() != 1 || !errors.Is(batchErr.Err(len(hashes)), ethereum.NotFound) {
+		t.Fatalf("❌ 不存在的收据应逐条报告 NotFound: %v", err)
+	}
+	for i, r := range receipts[:len(hashes)] {
+		if r == nil || r.TxHash != hashes[i] || r.Status != types.ReceiptStatusSuccessful {
+			t.Fatalf("❌ 第 %d 个收据不符: %+v", i, r)
+		}
+	}
+
+	// eth_call：balanceOf 正常返回，调用不存在的方法 revert 为单条错误
+	parsed, _ := abi.JSON(strings.NewReader(`[{"name":"balanceOf","type":"function","inputs":[{"type":"address"}],"outputs":[{"type":"uint256"}]}]`))
+	data, _ := parsed.Pack("balanceOf", chain.Accounts[1].Address)
+	outs, err := b.CallContracts(ctx, []ethereum.CallMsg{
+		{To: &tokenAddr, Data: data},
+		{To: &tokenAddr, Data: []byte{0xde, 0xad, 0xbe, 0xef}},
+	}, new(big.Int).SetUint64(head))
+	if !errors.As(err, &batchErr) || batchErr.Err(0) != nil || batchErr.Err(1) == nil {
+		t.Fatalf("❌ revert 应只影响对应条目: %v", err)
+	}
+	if new(big.Int).SetBytes(outs[0]).Int64() != 1 {
+		t.Fatalf("❌ balanceOf 结果不符: %x", outs[0])
+	}
+
+	// 区块：与 ethclient 查询的区块哈希和交易一致
+	blocks, err := b.BlocksByNumber(ctx, []uint64{0, 1, head, head + 100})
+	if !errors.As(err, &batchErr) || !errors.Is(batchErr.Err(3), ethereum.NotFound) {
+		t.Fatalf("❌ 不存在的区块应报告 NotFound: %v", err)
+	}
+	for _, blk := range blocks[:3] {
+		want, _ := chain.Client.BlockByNumber(ctx, blk.Number())
+		if blk.Hash() != want.Hash() || len(blk.Transactions()) != len(want.Transactions()) {
+			t.Fatalf("❌ 区块 %d 不符", blk.NumberU64())
+		}
+	}
+	t.Logf("✅ 区块 %d 含 %d 笔交易", head, len(blocks[2].Transactions()))
+}
+
+type balanceAPI struct{}
+
+func (balanceAPI) GetBalance(addr common.Address, block string) *hexutil.Big {
+	return (*hexutil.Big)(new(big.Int).SetBytes(addr.Bytes()))
+}
+
+// 测试服务商批量大小限制：超过限制时自动拆分，并记住更小的分块大小
+func TestBatcherSplitsOnLimit(t *testing.T) {
+	server := rpc.NewServer()
+	server.SetBatchLimits(3, 0)
+	if err := server.RegisterName("eth", balanceAPI{}); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	defer server.Stop()
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	b := NewBatcher(client, 10)
+	addrs := make([]common.Address, 25)
+	for i := range addrs {
+		addrs[i] = common.BigToAddress(big.NewInt(int64(i + 1)))
+	}
+	balances, err := b.BalancesAt(context.Background(), addrs, nil)
+	if err != nil {
+		t.Fatalf("❌ 批量查询失败: %v", err)
+	}
+	for i, bal := range balances {
+		if bal.Int64() != int64(i+1) {
+			t.Fatalf("❌ 第 %d 个余额不符: %s", i, bal)
+		}
+	}
+	if size := b.MaxSize(); size > 3 {
+		t.Fatalf("❌ 分块大小应缩小到不超过限制，实际 %d", size)
+	}
+	t.Logf("✅ 分块大小调整为 %d", b.MaxSize())
+}
@@ -0,0 +1,494 @@
+package rpcx
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// 缓存默认值
+const (
+	DefaultCacheSize  = 10000           // 内存 LRU 条目数
+	DefaultCacheDepth = 64              // 低于链头多少个区块视为不可变（以太坊约两个 epoch 后最终确定）
+	DefaultHeadTTL    = 2 * time.Second // 链头高度的缓存时间
+)
+
+// immutableMethods 按哈希查询、结果永不改变的方法（区块哈希唯一确定内容）
+var immutableMethods = map[string]bool{
+	"eth_getBlockByHash":                       true,
+	"eth_getBlockTransactionCountByHash":       true,
+	"eth_getTransactionByBlockHashAndIndex":    true,
+	"eth_getUncleCountByBlockHash":             true,
+	"eth_getUncleByBlockHashAndIndex":          true,
+	"eth_getBlockReceipts":                     true, // 参数为区块哈希时；区块号参数见 blockParam
+	"eth_getRawTransactionByBlockHashAndIndex": true,
+}
+
+// txMethods 按交易哈希查询的方法：交易所在区块足够深之后才缓存（重组可能改变所在区块）
+var txMethods = map[string]bool{
+	"eth_getTransactionByHash":  true,
+	"eth_getTransactionReceipt": true,
+}
+
+// blockParam 是带区块参数的方法及其区块参数位置：区块号足够深时才缓存
+var blockParam = map[string]int{
+	"eth_getBlockByNumber":                    0,
+	"eth_getBlockReceipts":                    0,
+	"eth_getBlockTransactionCountByNumber":    0,
+	"eth_getTransactionByBlockNumberAndIndex": 0,
+	"eth_getUncleCountByBlockNumber":          0,
+	"eth_getBalance":                          1,
+	"eth_getCode":                             1,
+	"eth_getTransactionCount":                 1,
+	"eth_call":                                1,
+	"eth_getStorageAt":                        2,
+	"eth_getProof":                            2,
+}
+
+type bypassKey struct{}
+
+// WithoutCache 返回跳过缓存的 context：请求直接发往节点，响应也不写入缓存
+func WithoutCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, bypassKey{}, true)
+}
+
+func bypassed(ctx context.Context) bool {
+	v, _ := ctx.Value(bypassKey{}).(bool)
+	return v
+}
+
+// CacheStats 是缓存命中统计
+type CacheStats struct {
+	Hits     uint64 // 命中（含磁盘命中）
+	DiskHits uint64 // 内存未命中、从磁盘读取的次数
+	Misses   uint64 // 可缓存但未命中的请求
+	Stores   uint64 // 写入缓存的响应
+	Bypassed uint64 // 通过 WithoutCache 跳过缓存的 HTTP 请求
+}
+
+// Cache 是只缓存不可变链上数据的 http.RoundTripper 中间件：按哈希查询的区块、
+// 足够深的交易/收据，以及区块号低于 链头-Depth 的查询。缓存键包含链 ID，
+// 不同链共用同一个磁盘目录也不会混淆。空结果和错误不缓存
+type Cache struct {
+	Next    http.RoundTripper // 为 nil 时使用 http.DefaultTransport
+	Dir     string            // 磁盘缓存目录，为空时只使用内存
+	Depth   uint64            // 最终确定深度
+	HeadTTL time.Duration     // 链头高度的缓存时间
+
+	mu      sync.Mutex
+	size    int
+	lru     *list.List
+	entries map[string]*list.Element
+	chainID string
+	head    uint64
+	headAt  time.Time
+
+	hits, diskHits, misses, stores, bypassed atomic.Uint64
+}
+
+type cacheEntry struct {
+	key    string
+	result json.RawMessage
+}
+
+// NewCache 创建缓存，size <= 0 时使用 DefaultCacheSize
+func NewCache(next http.RoundTripper, size int) *Cache {
+	if size <= 0 {
+		size = DefaultCacheSize
+	}
+	return &Cache{
+		Next:    next,
+		Depth:   DefaultCacheDepth,
+		HeadTTL: DefaultHeadTTL,
+		size:    size,
+		lru:     list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// Stats 返回命中统计
+func (c *Cache) Stats() CacheStats {
+	return CacheStats{
+		Hits:     c.hits.Load(),
+		DiskHits: c.diskHits.Load(),
+		Misses:   c.misses.Load(),
+		Stores:   c.stores.Load(),
+		Bypassed: c.bypassed.Load(),
+	}
+}
+
+// Len 返回内存中的条目数
+func (c *Cache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+func (c *Cache) next() http.RoundTripper {
+	if c.Next == nil {
+		return http.DefaultTransport
+	}
+	return c.Next
+}
+
+// RoundTrip 实现 http.RoundTripper：命中的请求直接应答，其余请求（批量请求中未命中的部分）转发给节点
+func (c *Cache) RoundTrip(req *http.Request) (*http.Response, error) {
+	if bypassed(req.Context()) {
+		c.bypassed.Add(1)
+		return c.next().RoundTrip(req)
+	}
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	reqs, batch, err := decodeMessages(body)
+	if err != nil || !anyCacheable(reqs) {
+		return c.next().RoundTrip(req)
+	}
+	chainID, err := c.chain(req)
+	if err != nil {
+		// 拿不到链 ID 时无法安全地区分缓存，直接转发
+		return c.next().RoundTrip(req)
+	}
+
+	resps := make([]*message, len(reqs))
+	var pending []*message
+	for i, m := range reqs {
+		if cacheable(m) {
+			if result, ok := c.get(cacheKey(chainID, m)); ok {
+				c.hits.Add(1)
+				resps[i] = &message{Version: "2.0", ID: m.ID, Result: result}
+				continue
+			}
+			c.misses.Add(1)
+		}
+		pending = append(pending, m)
+	}
+	if len(pending) == 0 {
+		return jsonResponse(req, resps, batch)
+	}
+
+	// 只转发未命中的请求
+	fwd := req
+	if len(pending) != len(reqs) {
+		out, err := encodeMessages(pending, true)
+		if err != nil {
+			return nil, err
+		}
+		fwd = cloneRequest(req, out)
+	}
+	resp, err := c.next().RoundTrip(fwd)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	got, _, err := decodeMessages(respBody)
+	if err != nil {
+		if len(pending) == len(reqs) {
+			return resp, nil
+		}
+		return nil, fmt.Errorf("解析 JSON-RPC 响应失败: %w", err)
+	}
+	byID := make(map[string]*message, len(got))
+	for _, m := range got {
+		byID[string(m.ID)] = m
+	}
+	for _, m := range pending {
+		r, ok := byID[string(m.ID)]
+		if ok && cacheable(m) {
+			c.store(req, chainID, m, r)
+		}
+	}
+	if len(pending) == len(reqs) {
+		return resp, nil
+	}
+
+	for i, m := range reqs {
+		if resps[i] != nil {
+			continue
+		}
+		r, ok := byID[string(m.ID)]
+		if !ok {
+			return nil, fmt.Errorf("节点未返回请求 %s 的响应", m.Method)
+		}
+		resps[i] = r
+	}
+	return jsonResponse(req, resps, batch)
+}
+
+// store 在响应满足最终确定条件时写入缓存
+func (c *Cache) store(req *http.Request, chainID string, m, r *message) {
+	if len(r.Error) > 0 || len(r.Result) == 0 || string(r.Result) == "null" {
+		return
+	}
+	switch {
+	case txMethods[m.Method]:
+		var tx struct {
+			BlockNumber *hexutil.Uint64 `json:"blockNumber"`
+		}
+		if json.Unmarshal(r.Result, &tx) != nil || tx.BlockNumber == nil || !c.final(req, uint64(*tx.BlockNumber)) {
+			return
+		}
+	case immutableMethods[m.Method] && blockHashParam(m):
+	default:
+		n, ok := blockNumberParam(m)
+		if !ok || !c.final(req, n) {
+			return
+		}
+	}
+	c.put(cacheKey(chainID, m), r.Result)
+	c.stores.Add(1)
+}
+
+// final 判断区块是否已低于 链头-Depth
+func (c *Cache) final(req *http.Request, number uint64) bool {
+	head, err := c.latest(req)
+	if err != nil || head < c.Depth {
+		return false
+	}
+	return number <= head-c.Depth
+}
+
+// latest 返回链头高度，HeadTTL 内复用上次结果
+func (c *Cache) latest(req *http.Request) (uint64, error) {
+	c.mu.Lock()
+	if !c.headAt.IsZero() && time.Since(c.headAt) < c.HeadTTL {
+		head := c.head
+		c.mu.Unlock()
+		return head, nil
+	}
+	c.mu.Unlock()
+
+	raw, err := c.call(req, "eth_blockNumber")
+	if err != nil {
+		return 0, err
+	}
+	var head hexutil.Uint64
+	if err := json.Unmarshal(raw, &head); err != nil {
+		return 0, fmt.Errorf("解析区块高度失败: %w", err)
+	}
+	c.mu.Lock()
+	c.head, c.headAt = uint64(head), time.Now()
+	c.mu.Unlock()
+	return uint64(head), nil
+}
+
+// chain 返回节点的链 ID（只查询一次）
+func (c *Cache) chain(req *http.Request) (string, error) {
+	c.mu.Lock()
+	id := c.chainID
+	c.mu.Unlock()
+	if id != "" {
+		return id, nil
+	}
+	raw, err := c.call(req, "eth_chainId")
+	if err != nil {
+		return "", err
+	}
+	var chainID hexutil.Big
+	if err := json.Unmarshal(raw, &chainID); err != nil {
+		return "", fmt.Errorf("解析链 ID 失败: %w", err)
+	}
+	c.mu.Lock()
+	c.chainID = chainID.ToInt().String()
+	c.mu.Unlock()
+	return chainID.ToInt().String(), nil
+}
+
+// call 向同一节点发送一个无参数请求
+func (c *Cache) call(req *http.Request, method string) (json.RawMessage, error) {
+	body, _ := json.Marshal(&message{Version: "2.0", ID: json.RawMessage("1"), Method: method, Params: json.RawMessage("[]")})
+	resp, err := c.next().RoundTrip(cloneRequest(req, body))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s 返回 HTTP %d", method, resp.StatusCode)
+	}
+	var m message
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, fmt.Errorf("解析 %s 响应失败: %w", method, err)
+	}
+	if len(m.Error) > 0 {
+		return nil, fmt.Errorf("%s 失败: %s", method, m.Error)
+	}
+	return m.Result, nil
+}
+
+// get 依次查找内存和磁盘
+func (c *Cache) get(key string) (json.RawMessage, bool) {
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.lru.MoveToFront(el)
+		result := el.Value.(*cacheEntry).result
+		c.mu.Unlock()
+		return result, true
+	}
+	c.mu.Unlock()
+
+	if c.Dir == "" {
+		return nil, false
+	}
+	data, err := os.ReadFile(c.path(key))
+	if err != nil || !json.Valid(data) {
+		return nil, false
+	}
+	c.diskHits.Add(1)
+	c.remember(key, data)
+	return data, true
+}
+
+// put 写入内存，配置了 Dir 时同时写入磁盘（写入失败只影响持久化）
+func (c *Cache) put(key string, result json.RawMessage) {
+	c.remember(key, result)
+	if c.Dir == "" {
+		return
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, result, 0o644); err != nil {
+		return
+	}
+	os.Rename(tmp, path)
+}
+
+// remember 写入内存 LRU，超出容量时淘汰最久未使用的条目
+func (c *Cache) remember(key string, result json.RawMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value.(*cacheEntry).result = result
+		c.lru.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.lru.PushFront(&cacheEntry{key: key, result: result})
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*cacheEntry).key)
+	}
+}
+
+// path 返回磁盘缓存文件路径：<Dir>/<链 ID>/<方法>/<键的 sha256>.json
+func (c *Cache) path(key string) string {
+	chainID, rest, _ := strings.Cut(key, " ")
+	method, _, _ := strings.Cut(rest, " ")
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.Dir, chainID, method, hex.EncodeToString(sum[:])+".json")
+}
+
+func cacheKey(chainID string, m *message) string {
+	return chainID + " " + m.Method + " " + canonical(m.Params)
+}
+
+func anyCacheable(reqs []*message) bool {
+	for _, m := range reqs {
+		if cacheable(m) {
+			return true
+		}
+	}
+	return false
+}
+
+// cacheable 判断请求是否可能命中缓存（是否真正写入还取决于响应和区块深度）
+func cacheable(m *message) bool {
+	if txMethods[m.Method] || (immutableMethods[m.Method] && blockHashParam(m)) {
+		return true
+	}
+	_, ok := blockNumberParam(m)
+	return ok
+}
+
+// params 解析请求参数数组
+func params(m *message) []json.RawMessage {
+	var ps []json.RawMessage
+	if json.Unmarshal(m.Params, &ps) != nil {
+		return nil
+	}
+	return ps
+}
+
+// blockHashParam 判断第一个参数是否为 32 字节哈希
+func blockHashParam(m *message) bool {
+	ps := params(m)
+	if len(ps) == 0 {
+		return false
+	}
+	var s string
+	return json.Unmarshal(ps[0], &s) == nil && len(s) == 66 && strings.HasPrefix(s, "0x")
+}
+
+// blockNumberParam 返回明确的区块号参数；latest/pending/safe/finalized 等标签不缓存
+func blockNumberParam(m *message) (uint64, bool) {
+	i, ok := blockParam[m.Method]
+	if !ok {
+		return 0, false
+	}
+	ps := params(m)
+	if i >= len(ps) {
+		return 0, false
+	}
+	var s string
+	if json.Unmarshal(ps[i], &s) != nil || len(s) == 66 {
+		return 0, false
+	}
+	n, err := hexutil.DecodeUint64(s)
+	if err != nil {
+		return 0, false
+	}
+	return n, true
+}
+
+// cloneRequest 复制请求并替换请求体
+func cloneRequest(req *http.Request, body []byte) *http.Request {
+	r := req.Clone(req.Context())
+	r.Body = io.NopCloser(bytes.NewReader(body))
+	r.ContentLength = int64(len(body))
+	r.GetBody = func() (io.ReadCloser, error) { return io.NopCloser(bytes.NewReader(body)), nil }
+	return r
+}
+
+// jsonResponse 构造 200 响应
+func jsonResponse(req *http.Request, msgs []*message, batch bool) (*http.Response, error) {
+	out, err := encodeMessages(msgs, batch)
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{
+		Status:        "200 OK",
+		StatusCode:    http.StatusOK,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewReader(out)),
+		ContentLength: int64(len(out)),
+		Request:       req,
+	}, nil
+}
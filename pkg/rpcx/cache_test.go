@@ -0,0 +1,192 @@
+package rpcx
+
+import (
+	"context"
+	"math/big"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// countingTransport 统计发往节点的每个 JSON-RPC 方法
+type countingTransport struct {
+	mu      sync.Mutex
+	methods map[string]int
+}
+
+func (t *countingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if msgs, _, err := decodeMessages(body); err == nil {
+		t.mu.Lock()
+		for _, m := range msgs {
+			t.methods[m.Method]++
+		}
+		t.mu.Unlock()
+	}
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func (t *countingTransport) count(method string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.methods[method]
+}
+
+// 测试只缓存最终确定的数据：深区块、按哈希查询的区块、足够深的收据命中缓存，
+// 链头附近的区块和 latest 查询每次都访问节点；WithoutCache 跳过缓存；磁盘缓存跨实例复用
+func TestCache(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t)
+	_, token := chain.DeployToken("IWS Token", "IWS", big.NewInt(1_000_000))
+	tx, err := token.Transfer(chain.Auth(0), chain.Accounts[1].Address, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("❌ 转账失败: %v", err)
+	}
+	chain.WaitMined(tx)
+	for i := 0; i < 5; i++ {
+		chain.Commit()
+	}
+	head, _ := chain.Client.BlockNumber(ctx)
+	url := chain.HTTPURL()
+
+	upstream := &countingTransport{methods: make(map[string]int)}
+	cache := NewCache(upstream, 0)
+	cache.Depth = 3
+	cache.Dir = t.TempDir()
+	client, err := Dial(ctx, url, cache)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+
+	// 深区块：第二次命中缓存
+	deep := big.NewInt(1)
+	for i := 0; i < 3; i++ {
+		if _, err := client.BlockByNumber(ctx, deep); err != nil {
+			t.Fatalf("❌ 查询区块失败: %v", err)
+		}
+	}
+	if n := upstream.count("eth_getBlockByNumber"); n != 1 {
+		t.Fatalf("❌ 深区块应只请求节点 1 次，实际 %d", n)
+	}
+
+	// 链头附近的区块和 latest 不缓存
+	for i := 0; i < 2; i++ {
+		client.HeaderByNumber(ctx, new(big.Int).SetUint64(head))
+		client.BalanceAt(ctx, chain.Accounts[0].Address, nil)
+	}
+	if n := upstream.count("eth_getBlockByNumber"); n != 3 {
+		t.Fatalf("❌ 链头区块不应缓存，实际请求 %d 次", n)
+	}
+	if n := upstream.count("eth_getBalance"); n != 2 {
+		t.Fatalf("❌ latest 查询不应缓存，实际请求 %d 次", n)
+	}
+
+	// 按哈希查询的区块和足够深的收据
+	receipt, _ := chain.Client.TransactionReceipt(ctx, tx.Hash())
+	for i := 0; i < 2; i++ {
+		if _, err := client.BlockByHash(ctx, receipt.BlockHash); err != nil {
+			t.Fatalf("❌ 按哈希查询区块失败: %v", err)
+		}
+		if _, err := client.TransactionReceipt(ctx, tx.Hash()); err != nil {
+			t.Fatalf("❌ 查询收据失败: %v", err)
+		}
+	}
+	if upstream.count("eth_getBlockByHash") != 1 || upstream.count("eth_getTransactionReceipt") != 1 {
+		t.Fatalf("❌ 按哈希查询应命中缓存: %v", upstream.methods)
+	}
+
+	// 不存在的交易（null）不缓存
+	for i := 0; i < 2; i++ {
+		client.TransactionReceipt(ctx, common.Hash{})
+	}
+	if n := upstream.count("eth_getTransactionReceipt"); n != 3 {
+		t.Fatalf("❌ 空结果不应缓存，实际请求 %d 次", n)
+	}
+
+	// 批量请求：命中的部分本地应答，只转发未命中的部分
+	var b1, b2 string
+	batch := []rpc.BatchElem{
+		{Method: "eth_getBlockByNumber", Args: []interface{}{"0x1", false}, Result: new(map[string]interface{})},
+		{Method: "eth_getBalance", Args: []interface{}{chain.Accounts[0].Address, "0x1"}, Result: &b1},
+		{Method: "eth_getBalance", Args: []interface{}{chain.Accounts[0].Address, "latest"}, Result: &b2},
+	}
+	if err := client.Client().BatchCallContext(ctx, batch); err != nil {
+		t.Fatalf("❌ 批量请求失败: %v", err)
+	}
+	for _, el := range batch {
+		if el.Error != nil {
+			t.Fatalf("❌ %s 失败: %v", el.Method, el.Error)
+		}
+	}
+	if n := upstream.count("eth_getBlockByNumber"); n != 4 || b1 == "" || b2 == "" {
+		t.Fatalf("❌ 批量请求应只转发未命中部分: %v", upstream.methods)
+	}
+
+	// 单次跳过缓存
+	if _, err := client.BlockByNumber(WithoutCache(ctx), deep); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if n := upstream.count("eth_getBlockByNumber"); n != 5 {
+		t.Fatalf("❌ WithoutCache 应直接请求节点")
+	}
+
+	stats := cache.Stats()
+	if stats.Hits == 0 || stats.Misses == 0 || stats.Stores == 0 || stats.Bypassed != 1 {
+		t.Fatalf("❌ 统计不符: %+v", stats)
+	}
+
+	// 新实例从磁盘读取，不访问节点
+	fresh := &countingTransport{methods: make(map[string]int)}
+	disk := NewCache(fresh, 0)
+	disk.Dir = cache.Dir
+	client2, _ := Dial(ctx, url, disk)
+	if _, err := client2.BlockByNumber(ctx, deep); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if fresh.count("eth_getBlockByNumber") != 0 || disk.Stats().DiskHits != 1 {
+		t.Fatalf("❌ 应从磁盘缓存读取: %v %+v", fresh.methods, disk.Stats())
+	}
+	t.Logf("✅ 缓存统计: %+v", stats)
+}
+
+// 测试缓存键包含链 ID：链 ID 不同的节点不会共享缓存
+func TestCacheKeyedByChainID(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	dial := func(chainID int64) (*ethclient.Client, *Cache) {
+		chain := simchain.New(t)
+		for i := 0; i < 4; i++ {
+			chain.Commit()
+		}
+		cache := NewCache(nil, 0)
+		cache.Depth = 1
+		cache.Dir = dir
+		cache.chainID = big.NewInt(chainID).String() // 模拟连接到不同的链
+		client, err := Dial(ctx, chain.HTTPURL(), cache)
+		if err != nil {
+			t.Fatalf("❌ %v", err)
+		}
+		return client, cache
+	}
+
+	a, cacheA := dial(1337)
+	b, cacheB := dial(1338)
+	if _, err := a.BlockByNumber(ctx, big.NewInt(1)); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if _, err := b.BlockByNumber(ctx, big.NewInt(1)); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if cacheA.Stats().Stores != 1 || cacheB.Stats().Hits != 0 {
+		t.Fatalf("❌ 不同链不应共享缓存: %+v %+v", cacheA.Stats(), cacheB.Stats())
+	}
+	t.Logf("✅ 链 ID 隔离")
+}
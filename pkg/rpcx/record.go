@@ -0,0 +1,237 @@
+package rpcx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Exchange 是一次录制的请求/响应对，匹配时忽略请求 id
+type Exchange struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  json.RawMessage `json:"error,omitempty"`
+}
+
+func (e *Exchange) key() string {
+	return e.Method + " " + canonical(e.Params)
+}
+
+// Fixture 是录制文件的内容，按首次出现的顺序保存请求/响应对
+type Fixture struct {
+	Exchanges []Exchange `json:"exchanges"`
+}
+
+// LoadFixture 读取录制文件
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取录制文件失败: %w", err)
+	}
+	var f Fixture
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("解析录制文件 %s 失败: %w", path, err)
+	}
+	return &f, nil
+}
+
+// Save 把录制内容写入文件（自动创建目录）
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("编码录制文件失败: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("创建录制目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("写入录制文件失败: %w", err)
+	}
+	return nil
+}
+
+// Recorder 把请求转发给真实节点，并录制每一对请求/响应（批量请求逐条录制）
+type Recorder struct {
+	Next http.RoundTripper // 为 nil 时使用 http.DefaultTransport
+
+	mu      sync.Mutex
+	fixture Fixture
+	index   map[string]int
+}
+
+// NewRecorder 创建录制器
+func NewRecorder(next http.RoundTripper) *Recorder {
+	return &Recorder{Next: next, index: make(map[string]int)}
+}
+
+// RoundTrip 实现 http.RoundTripper
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	resp, err := next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取响应失败: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	if resp.StatusCode == http.StatusOK {
+		r.record(body, respBody)
+	}
+	return resp, nil
+}
+
+// record 按 id 把请求与响应配对后保存，同一请求重复出现时保留最新响应
+func (r *Recorder) record(reqBody, respBody []byte) {
+	reqs, _, err := decodeMessages(reqBody)
+	if err != nil {
+		return
+	}
+	resps, _, err := decodeMessages(respBody)
+	if err != nil {
+		return
+	}
+	byID := make(map[string]*message, len(resps))
+	for _, m := range resps {
+		byID[string(m.ID)] = m
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, req := range reqs {
+		resp, ok := byID[string(req.ID)]
+		if !ok {
+			continue
+		}
+		ex := Exchange{Method: req.Method, Params: req.Params, Result: resp.Result, Error: resp.Error}
+		if len(ex.Params) == 0 {
+			ex.Params = json.RawMessage("[]")
+		}
+		if i, ok := r.index[ex.key()]; ok {
+			r.fixture.Exchanges[i] = ex
+			continue
+		}
+		r.index[ex.key()] = len(r.fixture.Exchanges)
+		r.fixture.Exchanges = append(r.fixture.Exchanges, ex)
+	}
+}
+
+// Fixture 返回当前已录制内容的副本
+func (r *Recorder) Fixture() *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &Fixture{Exchanges: append([]Exchange(nil), r.fixture.Exchanges...)}
+}
+
+// Save 把已录制的内容写入文件
+func (r *Recorder) Save(path string) error {
+	return r.Fixture().Save(path)
+}
+
+// Replayer 根据录制内容离线应答请求，不访问网络
+type Replayer struct {
+	name      string
+	exchanges map[string]*Exchange
+	byMethod  map[string][]*Exchange
+}
+
+// NewReplayer 创建回放器，name 用于错误信息（通常为录制文件路径）
+func NewReplayer(name string, f *Fixture) *Replayer {
+	r := &Replayer{name: name, exchanges: make(map[string]*Exchange), byMethod: make(map[string][]*Exchange)}
+	for i := range f.Exchanges {
+		ex := &f.Exchanges[i]
+		r.exchanges[ex.key()] = ex
+		r.byMethod[ex.Method] = append(r.byMethod[ex.Method], ex)
+	}
+	return r
+}
+
+// OpenReplayer 读取录制文件并创建回放器
+func OpenReplayer(path string) (*Replayer, error) {
+	f, err := LoadFixture(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(path, f), nil
+}
+
+// RoundTrip 实现 http.RoundTripper；遇到未录制的请求时返回错误，说明缺失的请求以及同方法下已录制的参数
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	reqs, batch, err := decodeMessages(body)
+	if err != nil {
+		return nil, fmt.Errorf("解析 JSON-RPC 请求失败: %w", err)
+	}
+
+	resps := make([]*message, len(reqs))
+	for i, m := range reqs {
+		ex := Exchange{Method: m.Method, Params: m.Params}
+		recorded, ok := r.exchanges[ex.key()]
+		if !ok {
+			return nil, r.mismatch(&ex)
+		}
+		resps[i] = &message{Version: "2.0", ID: m.ID, Result: recorded.Result, Error: recorded.Error}
+		if recorded.Result == nil && recorded.Error == nil {
+			resps[i].Result = json.RawMessage("null")
+		}
+	}
+
+	return jsonResponse(req, resps, batch)
+}
+
+// mismatch 生成未命中录制时的诊断信息
+func (r *Replayer) mismatch(ex *Exchange) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "录制文件 %s 中没有请求 %s %s", r.name, ex.Method, canonical(ex.Params))
+	if same := r.byMethod[ex.Method]; len(same) > 0 {
+		params := make([]string, len(same))
+		for i, s := range same {
+			params[i] = canonical(s.Params)
+		}
+		sort.Strings(params)
+		fmt.Fprintf(&b, "；同方法已录制的参数: %s", strings.Join(params, ", "))
+	} else {
+		methods := make([]string, 0, len(r.byMethod))
+		for m := range r.byMethod {
+			methods = append(methods, m)
+		}
+		sort.Strings(methods)
+		fmt.Fprintf(&b, "；已录制的方法: %s", strings.Join(methods, ", "))
+	}
+	return errors.New(b.String())
+}
+
+// readBody 读取请求体并恢复，以便继续转发
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil {
+		return nil, nil
+	}
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("读取请求失败: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	return body, nil
+}
@@ -0,0 +1,81 @@
+package rpcx
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 测试录制后离线回放：单个请求、批量请求结果一致，未录制的请求返回诊断信息
+func TestRecordReplay(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t)
+	tokenAddr, _ := chain.DeployToken("IWS Token", "IWS", big.NewInt(1e18))
+	owner := chain.Accounts[0].Address
+
+	rec := NewRecorder(nil)
+	live, err := Dial(ctx, chain.HTTPURL(), rec)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	block, err := live.BlockByNumber(ctx, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("❌ 录制区块失败: %v", err)
+	}
+	var code, balance string
+	batch := []rpc.BatchElem{
+		{Method: "eth_getCode", Args: []interface{}{tokenAddr, "0x1"}, Result: &code},
+		{Method: "eth_getBalance", Args: []interface{}{owner, "0x1"}, Result: &balance},
+	}
+	if err := live.Client().BatchCallContext(ctx, batch); err != nil {
+		t.Fatalf("❌ 录制批量请求失败: %v", err)
+	}
+	if _, err := live.TransactionReceipt(ctx, common.Hash{}); err == nil {
+		t.Fatal("❌ 不存在的交易应返回错误")
+	}
+
+	path := filepath.Join(t.TempDir(), "testdata", "fixture.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if n := len(rec.Fixture().Exchanges); n != 4 {
+		t.Fatalf("❌ 录制条数应为 4，实际 %d", n)
+	}
+
+	// 回放：节点地址不可达，所有应答都来自录制文件
+	replayer, err := OpenReplayer(path)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	offline, err := Dial(ctx, "http://replay.invalid", replayer)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	replayed, err := offline.BlockByNumber(ctx, big.NewInt(1))
+	if err != nil || replayed.Hash() != block.Hash() {
+		t.Fatalf("❌ 回放区块不一致: %v", err)
+	}
+	var code2, balance2 string
+	batch = []rpc.BatchElem{
+		{Method: "eth_getBalance", Args: []interface{}{owner, "0x1"}, Result: &balance2},
+		{Method: "eth_getCode", Args: []interface{}{tokenAddr, "0x1"}, Result: &code2},
+	}
+	if err := offline.Client().BatchCallContext(ctx, batch); err != nil || code2 != code || balance2 != balance {
+		t.Fatalf("❌ 回放批量请求不一致: %v", err)
+	}
+	if _, err := offline.TransactionReceipt(ctx, common.Hash{}); err == nil {
+		t.Fatal("❌ 回放应保留录制时的 not found 结果")
+	}
+
+	_, err = offline.BlockByNumber(ctx, big.NewInt(2))
+	if err == nil || !strings.Contains(err.Error(), "eth_getBlockByNumber") || !strings.Contains(err.Error(), `"0x1"`) {
+		t.Fatalf("❌ 未录制的请求应返回诊断信息: %v", err)
+	}
+	t.Logf("✅ 未命中诊断: %v", err)
+}
@@ -0,0 +1,278 @@
+package rpcx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+)
+
+// RateLimiter 是按节点地址限速的令牌桶中间件。批量请求按条数消耗令牌（服务商按条计费）
+type RateLimiter struct {
+	Next  http.RoundTripper // 为 nil 时使用 http.DefaultTransport
+	Rate  float64           // 每秒补充的令牌数
+	Burst int               // 桶容量
+
+	mu      sync.Mutex
+	buckets map[string]*bucket
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// NewRateLimiter 创建限速器：每秒 rate 个请求，允许突发 burst 个（burst <= 0 时取 rate 向上取整）
+func NewRateLimiter(next http.RoundTripper, rate float64, burst int) *RateLimiter {
+	if burst <= 0 {
+		burst = int(rate + 0.999)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &RateLimiter{Next: next, Rate: rate, Burst: burst, buckets: make(map[string]*bucket)}
+}
+
+// RoundTrip 实现 http.RoundTripper：令牌不足时等待，context 取消时返回
+func (l *RateLimiter) RoundTrip(req *http.Request) (*http.Response, error) {
+	cost := 1
+	if body, err := readBody(req); err == nil {
+		if msgs, _, err := decodeMessages(body); err == nil && len(msgs) > 0 {
+			cost = len(msgs)
+		}
+	}
+	if err := l.wait(req.Context(), req.URL.Host+req.URL.Path, cost); err != nil {
+		return nil, err
+	}
+	next := l.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return next.RoundTrip(req)
+}
+
+// wait 预留 cost 个令牌，不足时等待补充（预留后令牌可以为负，后续请求依次排队）
+func (l *RateLimiter) wait(ctx context.Context, endpoint string, cost int) error {
+	if l.Rate <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	b, ok := l.buckets[endpoint]
+	if !ok {
+		b = &bucket{tokens: float64(l.Burst), last: now}
+		l.buckets[endpoint] = b
+	}
+	b.tokens += now.Sub(b.last).Seconds() * l.Rate
+	if b.tokens > float64(l.Burst) {
+		b.tokens = float64(l.Burst)
+	}
+	b.last = now
+	b.tokens -= float64(cost)
+	delay := time.Duration(0)
+	if b.tokens < 0 {
+		delay = time.Duration(-b.tokens / l.Rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay == 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// RetryPolicy 是指数退避重试策略
+type RetryPolicy struct {
+	MaxAttempts int           // 总尝试次数（含第一次）
+	BaseDelay   time.Duration // 第一次重试的退避上限，之后每次翻倍
+	MaxDelay    time.Duration // 单次退避上限
+}
+
+// DefaultRetryPolicy 是默认重试策略：最多 5 次，退避 200ms 起、不超过 5s
+var DefaultRetryPolicy = RetryPolicy{MaxAttempts: 5, BaseDelay: 200 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+// Backoff 返回第 attempt 次重试（从 1 开始）的等待时间：在 [0, min(MaxDelay, BaseDelay*2^(attempt-1))] 内均匀随机（full jitter）
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	ceiling := p.BaseDelay
+	for i := 1; i < attempt && ceiling < p.MaxDelay; i++ {
+		ceiling *= 2
+	}
+	if p.MaxDelay > 0 && ceiling > p.MaxDelay {
+		ceiling = p.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int64N(int64(ceiling) + 1))
+}
+
+// Retry 是重试中间件：只重试限流、超时和节点暂时不可用（见 chainerr.Class.Retryable），
+// revert、nonce、not found 等错误立即返回。限流响应带 Retry-After 时按其等待，
+// 超过 Policy.MaxDelay 则不再等待，直接返回限流响应。
+// 广播交易的请求（见 sendMethods）超时或连接中断时可能已被节点接收，重发会得到
+// already known / nonce too low，因此只在确定被限流拒绝时重试
+type Retry struct {
+	Next    http.RoundTripper // 为 nil 时使用 http.DefaultTransport
+	Policy  RetryPolicy
+	OnRetry func(attempt int, delay time.Duration, err error) // 每次重试前调用，可用于日志
+
+	retries atomic.Uint64
+}
+
+// NewRetry 创建重试中间件
+func NewRetry(next http.RoundTripper, policy RetryPolicy) *Retry {
+	return &Retry{Next: next, Policy: policy}
+}
+
+// Retries 返回累计重试次数
+func (r *Retry) Retries() uint64 {
+	return r.retries.Load()
+}
+
+// RoundTrip 实现 http.RoundTripper。用尽重试次数后返回最后一次的响应；
+// 传输错误归类为 *chainerr.Error，调用方可用 errors.Is(err, chainerr.ErrTimeout) 等判断
+func (r *Retry) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+	next := r.Next
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	attempts := r.Policy.MaxAttempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	resend := !sendsTransaction(body)
+
+	for attempt := 1; ; attempt++ {
+		resp, err := next.RoundTrip(cloneRequest(req, body))
+		wait, cause := r.check(resp, err)
+		if cause == nil {
+			return resp, err
+		}
+		giveUp := attempt >= attempts || req.Context().Err() != nil ||
+			(!resend && chainerr.Classify(cause) != chainerr.RateLimited) ||
+			(r.Policy.MaxDelay > 0 && wait > r.Policy.MaxDelay)
+		if giveUp {
+			if err != nil {
+				return nil, chainerr.Wrap(err)
+			}
+			return resp, nil
+		}
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		delay := r.Policy.Backoff(attempt)
+		if wait > delay {
+			delay = wait
+		}
+		if r.OnRetry != nil {
+			r.OnRetry(attempt, delay, cause)
+		}
+		r.retries.Add(1)
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+}
+
+// sendMethods 是会广播交易、不能安全重发的方法
+var sendMethods = map[string]bool{
+	"eth_sendRawTransaction":     true,
+	"eth_sendRawTransactionSync": true,
+	"eth_sendTransaction":        true,
+}
+
+// sendsTransaction 判断请求（单个或批量）中是否包含广播交易的方法，无法解析时按不包含处理
+func sendsTransaction(body []byte) bool {
+	msgs, _, err := decodeMessages(body)
+	if err != nil {
+		return false
+	}
+	for _, m := range msgs {
+		if sendMethods[m.Method] {
+			return true
+		}
+	}
+	return false
+}
+
+// check 判断一次尝试是否需要重试，返回服务端要求的等待时间以及重试原因（为 nil 表示不重试）
+func (r *Retry) check(resp *http.Response, err error) (time.Duration, error) {
+	if err != nil {
+		if chainerr.Classify(err).Retryable() {
+			return 0, err
+		}
+		return 0, nil
+	}
+	if class := chainerr.ClassifyStatus(resp.StatusCode); class.Retryable() {
+		return retryAfter(resp), &chainerr.Error{Class: class, Err: fmt.Errorf("HTTP %s", resp.Status)}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, nil
+	}
+
+	// JSON-RPC 层的限流：HTTP 200 但响应中带错误
+	data, readErr := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	if readErr != nil {
+		return 0, &chainerr.Error{Class: chainerr.Unavailable, Err: readErr}
+	}
+	msgs, _, decodeErr := decodeMessages(data)
+	if decodeErr != nil {
+		return 0, nil
+	}
+	for _, m := range msgs {
+		if len(m.Error) == 0 {
+			continue
+		}
+		var e jsonError
+		if json.Unmarshal(m.Error, &e) == nil && chainerr.Classify(&e) == chainerr.RateLimited {
+			return retryAfter(resp), &chainerr.Error{Class: chainerr.RateLimited, Err: &e}
+		}
+	}
+	return 0, nil
+}
+
+// retryAfter 解析 Retry-After 响应头（秒数）
+func retryAfter(resp *http.Response) time.Duration {
+	secs, err := strconv.Atoi(resp.Header.Get("Retry-After"))
+	if err != nil || secs < 0 {
+		return 0
+	}
+	return time.Duration(secs) * time.Second
+}
+
+// jsonError 是响应中的 JSON-RPC 错误对象，实现 rpc.Error
+type jsonError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *jsonError) Error() string  { return e.Message }
+func (e *jsonError) ErrorCode() int { return e.Code }
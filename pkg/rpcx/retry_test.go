@@ -0,0 +1,172 @@
+package rpcx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+)
+
+// flakyNode 前 failures 次请求按 mode 失败，之后正常应答 eth_blockNumber / eth_call(revert)
+func flakyNode(t *testing.T, failures int32, mode string) (*httptest.Server, *atomic.Int32) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		n := calls.Add(1)
+		msgs, _, _ := decodeMessages(body)
+		id := string(msgs[0].ID)
+		if n <= failures {
+			switch mode {
+			case "429":
+				w.Header().Set("Retry-After", "0")
+				http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			case "429-long":
+				w.Header().Set("Retry-After", "60")
+				http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+			case "503":
+				http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			case "32005":
+				fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32005,"message":"limit exceeded"}}`, id)
+			}
+			return
+		}
+		if msgs[0].Method == "eth_call" {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":3,"message":"execution reverted","data":"0x"}}`, id)
+			return
+		}
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x10"}`, id)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &calls
+}
+
+var fastPolicy = RetryPolicy{MaxAttempts: 4, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+// 测试只重试可重试的错误：HTTP 429 和 JSON-RPC 限流错误重试后成功，revert 不重试，重试用尽后返回限流错误，
+// Retry-After 过长时不等待，广播交易只在被限流时重发
+func TestRetry(t *testing.T) {
+	ctx := context.Background()
+
+	for _, mode := range []string{"429", "32005"} {
+		srv, calls := flakyNode(t, 2, mode)
+		retry := NewRetry(nil, fastPolicy)
+		client, _ := Dial(ctx, srv.URL, retry)
+		head, err := client.BlockNumber(ctx)
+		if err != nil || head != 16 {
+			t.Fatalf("❌ [%s] 重试后应成功: %d %v", mode, head, err)
+		}
+		if calls.Load() != 3 || retry.Retries() != 2 {
+			t.Fatalf("❌ [%s] 应请求 3 次、重试 2 次，实际 %d / %d", mode, calls.Load(), retry.Retries())
+		}
+	}
+
+	// revert 立即返回
+	srv, calls := flakyNode(t, 0, "")
+	client, _ := Dial(ctx, srv.URL, NewRetry(nil, fastPolicy))
+	var out string
+	err := client.Client().CallContext(ctx, &out, "eth_call", map[string]string{}, "latest")
+	if !errors.Is(chainerr.Wrap(err), chainerr.ErrReverted) || calls.Load() != 1 {
+		t.Fatalf("❌ revert 不应重试: %v（请求 %d 次）", err, calls.Load())
+	}
+
+	// 重试用尽
+	srv, calls = flakyNode(t, 100, "429")
+	client, _ = Dial(ctx, srv.URL, NewRetry(nil, fastPolicy))
+	_, err = client.BlockNumber(ctx)
+	if !errors.Is(chainerr.Wrap(err), chainerr.ErrRateLimited) || calls.Load() != 4 {
+		t.Fatalf("❌ 重试用尽后应返回限流错误: %v（请求 %d 次）", err, calls.Load())
+	}
+
+	// Retry-After 超过单次退避上限：不等待，直接返回限流错误
+	srv, calls = flakyNode(t, 100, "429-long")
+	client, _ = Dial(ctx, srv.URL, NewRetry(nil, fastPolicy))
+	start := time.Now()
+	_, err = client.BlockNumber(ctx)
+	if !errors.Is(chainerr.Wrap(err), chainerr.ErrRateLimited) || calls.Load() != 1 || time.Since(start) > time.Second {
+		t.Fatalf("❌ Retry-After 超过 MaxDelay 时应立即返回: %v（请求 %d 次，耗时 %v）", err, calls.Load(), time.Since(start))
+	}
+
+	// 广播交易：节点不可用时可能已被接收，不重发；被限流拒绝时仍然重试
+	srv, calls = flakyNode(t, 1, "503")
+	client, _ = Dial(ctx, srv.URL, NewRetry(nil, fastPolicy))
+	err = client.Client().CallContext(ctx, &out, "eth_sendRawTransaction", "0x00")
+	if !errors.Is(chainerr.Wrap(err), chainerr.ErrUnavailable) || calls.Load() != 1 {
+		t.Fatalf("❌ 广播交易不应在节点不可用时重发: %v（请求 %d 次）", err, calls.Load())
+	}
+	srv, calls = flakyNode(t, 1, "503")
+	client, _ = Dial(ctx, srv.URL, NewRetry(nil, fastPolicy))
+	if _, err := client.BlockNumber(ctx); err != nil || calls.Load() != 2 {
+		t.Fatalf("❌ 只读请求应在节点不可用时重试: %v（请求 %d 次）", err, calls.Load())
+	}
+	srv, calls = flakyNode(t, 2, "32005")
+	client, _ = Dial(ctx, srv.URL, NewRetry(nil, fastPolicy))
+	if err := client.Client().CallContext(ctx, &out, "eth_sendRawTransaction", "0x00"); err != nil || calls.Load() != 3 {
+		t.Fatalf("❌ 广播交易被限流时应重试: %v（请求 %d 次）", err, calls.Load())
+	}
+
+	// 连接失败归类为节点不可用，重试后返回 *chainerr.Error
+	client, _ = Dial(ctx, "http://127.0.0.1:1", NewRetry(nil, fastPolicy))
+	_, err = client.BlockNumber(ctx)
+	if !errors.Is(err, chainerr.ErrUnavailable) {
+		t.Fatalf("❌ 连接失败应归类为不可用: %v", err)
+	}
+	t.Logf("✅ %v", err)
+}
+
+// 测试退避时间在 [0, min(MaxDelay, BaseDelay*2^(n-1))] 内
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	for attempt, ceiling := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 4: 800 * time.Millisecond, 10: time.Second} {
+		for i := 0; i < 100; i++ {
+			if d := p.Backoff(attempt); d < 0 || d > ceiling {
+				t.Fatalf("❌ 第 %d 次退避 %v 超出 [0, %v]", attempt, d, ceiling)
+			}
+		}
+	}
+}
+
+// 测试令牌桶：突发之后按速率放行，批量请求按条数计费，不同节点互不影响
+func TestRateLimiter(t *testing.T) {
+	ctx := context.Background()
+	srv, _ := flakyNode(t, 0, "")
+	limiter := NewRateLimiter(nil, 50, 2)
+	client, _ := Dial(ctx, srv.URL, limiter)
+
+	start := time.Now()
+	for i := 0; i < 7; i++ { // 2 个突发 + 5 个按 50/s => 约 100ms
+		if _, err := client.BlockNumber(ctx); err != nil {
+			t.Fatalf("❌ %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond {
+		t.Fatalf("❌ 限速未生效: %v", elapsed)
+	}
+
+	// 另一个节点有独立的令牌桶
+	other, _ := flakyNode(t, 0, "")
+	client2, _ := Dial(ctx, other.URL, limiter)
+	start = time.Now()
+	client2.BlockNumber(ctx)
+	if elapsed := time.Since(start); elapsed > 15*time.Millisecond {
+		t.Fatalf("❌ 不同节点不应共享令牌: %v", elapsed)
+	}
+
+	// context 取消时立即返回
+	slow := NewRateLimiter(nil, 0.1, 1)
+	client3, _ := Dial(ctx, srv.URL, slow)
+	client3.BlockNumber(ctx)
+	cctx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if _, err := client3.BlockNumber(cctx); err == nil || !strings.Contains(err.Error(), "deadline") {
+		t.Fatalf("❌ 等待令牌时应响应 context 取消: %v", err)
+	}
+	t.Logf("✅ 限速生效")
+}
@@ -0,0 +1,67 @@
+// Package rpcx 提供 JSON-RPC 传输层扩展：请求录制与离线回放、批量请求、不可变数据缓存以及限速与重试
+package rpcx
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Dial 使用自定义 http.RoundTripper 连接 HTTP JSON-RPC 节点
+func Dial(ctx context.Context, url string, transport http.RoundTripper) (*ethclient.Client, error) {
+	c, err := rpc.DialOptions(ctx, url, rpc.WithHTTPClient(&http.Client{Transport: transport}))
+	if err != nil {
+		return nil, fmt.Errorf("连接节点失败: %w", err)
+	}
+	return ethclient.NewClient(c), nil
+}
+
+// message 是一条 JSON-RPC 请求或响应
+type message struct {
+	Version string          `json:"jsonrpc,omitempty"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   json.RawMessage `json:"error,omitempty"`
+}
+
+// decodeMessages 解析单个或批量 JSON-RPC 消息，batch 表示是否为数组形式
+func decodeMessages(body []byte) (msgs []*message, batch bool, err error) {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		err = json.Unmarshal(body, &msgs)
+		return msgs, true, err
+	}
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, false, err
+	}
+	return []*message{&msg}, false, nil
+}
+
+// encodeMessages 按请求的形式（单个或批量）编码响应
+func encodeMessages(msgs []*message, batch bool) ([]byte, error) {
+	if batch {
+		return json.Marshal(msgs)
+	}
+	return json.Marshal(msgs[0])
+}
+
+// canonical 把 JSON 重新编码为规范形式（对象键排序、去除空白），用于请求匹配
+func canonical(raw json.RawMessage) string {
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return "[]"
+	}
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return string(raw)
+	}
+	out, _ := json.Marshal(v)
+	return string(out)
+}
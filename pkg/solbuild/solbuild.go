@@ -0,0 +1,297 @@
+// Package solbuild 编译合约目录下的 Solidity 源码，生成 ABI、字节码和 abigen 绑定，
+// 并检查提交的构建产物是否与源码一致
+package solbuild
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/abigen"
+)
+
+// ConfigFile 是合约目录下的构建配置文件名
+const ConfigFile = "contracts.json"
+
+// ErrDrift 表示提交的构建产物与源码编译结果不一致
+var ErrDrift = errors.New("构建产物与源码不一致")
+
+// Config 是构建配置
+type Config struct {
+	Solc      string          `json:"solc"`               // 要求的 solc 版本，例如 0.8.30
+	Settings  json.RawMessage `json:"settings,omitempty"` // standard-json 的 settings（outputSelection 由构建生成）
+	Contracts []Target        `json:"contracts"`
+}
+
+// Target 是一个合约的构建产物，路径都相对于合约目录
+type Target struct {
+	Source   string    `json:"source"`   // 源文件，例如 store/Store.sol
+	Contract string    `json:"contract"` // 合约名
+	ABI      string    `json:"abi,omitempty"`
+	Bin      string    `json:"bin,omitempty"`
+	Bindings []Binding `json:"bindings,omitempty"`
+}
+
+// Binding 是一个 abigen 生成的 Go 绑定
+type Binding struct {
+	Out     string `json:"out"`
+	Package string `json:"package"`
+	Type    string `json:"type"`
+	Deploy  bool   `json:"deploy,omitempty"` // 包含字节码和 Deploy 函数
+}
+
+// LoadConfig 读取合约目录下的 contracts.json
+func LoadConfig(dir string) (*Config, error) {
+	data, err := os.ReadFile(filepath.Join(dir, ConfigFile))
+	if err != nil {
+		return nil, fmt.Errorf("读取构建配置失败: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("解析构建配置失败: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Input 生成 solc standard-json 输入：目录下所有 .sol 文件都作为源码（以相对路径为键，import 按此解析），
+// 每个 .sol 文件都必须在配置中出现
+func (c *Config) Input(dir string) ([]byte, error) {
+	configured := make(map[string]bool)
+	for _, t := range c.Contracts {
+		configured[t.Source] = true
+	}
+	type source struct {
+		Content string `json:"content"`
+	}
+	sources := make(map[string]source)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".sol" {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !configured[rel] {
+			return fmt.Errorf("%s 没有在 %s 中配置", rel, ConfigFile)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sources[rel] = source{Content: string(content)}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for src := range configured {
+		if _, ok := sources[src]; !ok {
+			return nil, fmt.Errorf("配置中的源文件 %s 不存在", src)
+		}
+	}
+
+	settings := make(map[string]interface{})
+	if len(c.Settings) > 0 {
+		if err := json.Unmarshal(c.Settings, &settings); err != nil {
+			return nil, fmt.Errorf("解析 settings 失败: %w", err)
+		}
+	}
+	settings["outputSelection"] = map[string]map[string][]string{"*": {"*": {"abi", "evm.bytecode.object"}}}
+	return json.MarshalIndent(map[string]interface{}{
+		"language": "Solidity",
+		"sources":  sources,
+		"settings": settings,
+	}, "", "  ")
+}
+
+// Compiler 把 standard-json 输入编译为 standard-json 输出
+type Compiler interface {
+	Compile(ctx context.Context, input []byte) ([]byte, error)
+}
+
+// Solc 调用本地 solc 可执行文件
+type Solc struct {
+	Path    string // 默认为 PATH 中的 solc
+	Version string // 要求的版本，为空时不检查
+}
+
+// Compile 实现 Compiler
+func (s *Solc) Compile(ctx context.Context, input []byte) ([]byte, error) {
+	path := s.Path
+	if path == "" {
+		path = "solc"
+	}
+	if s.Version != "" {
+		out, err := exec.CommandContext(ctx, path, "--version").Output()
+		if err != nil {
+			return nil, fmt.Errorf("运行 %s 失败: %w", path, err)
+		}
+		if !strings.Contains(string(out), "Version: "+s.Version+"+") {
+			return nil, fmt.Errorf("需要 solc %s，%s 的版本为: %s", s.Version, path, strings.TrimSpace(string(out)))
+		}
+	}
+	cmd := exec.CommandContext(ctx, path, "--standard-json")
+	cmd.Stdin = bytes.NewReader(input)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("运行 %s 失败: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// Output 是预先编译好的 standard-json 输出（例如在装有 solc 的机器上生成），忽略输入
+type Output []byte
+
+// Compile 实现 Compiler
+func (o Output) Compile(context.Context, []byte) ([]byte, error) {
+	return o, nil
+}
+
+type solcOutput struct {
+	Errors []struct {
+		Severity         string `json:"severity"`
+		FormattedMessage string `json:"formattedMessage"`
+		Message          string `json:"message"`
+	} `json:"errors"`
+	Contracts map[string]map[string]struct {
+		ABI json.RawMessage `json:"abi"`
+		EVM struct {
+			Bytecode struct {
+				Object string `json:"object"`
+			} `json:"bytecode"`
+		} `json:"evm"`
+	} `json:"contracts"`
+}
+
+// Generate 编译合约目录并返回所有构建产物（相对路径 -> 内容）
+func Generate(ctx context.Context, dir string, compiler Compiler) (map[string][]byte, error) {
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		return nil, err
+	}
+	input, err := cfg.Input(dir)
+	if err != nil {
+		return nil, err
+	}
+	if s, ok := compiler.(*Solc); ok && s.Version == "" {
+		compiler = &Solc{Path: s.Path, Version: cfg.Solc}
+	}
+	raw, err := compiler.Compile(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	var out solcOutput
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("解析 solc 输出失败: %w", err)
+	}
+	var msgs []string
+	for _, e := range out.Errors {
+		if e.Severity == "error" {
+			msg := e.FormattedMessage
+			if msg == "" {
+				msg = e.Message
+			}
+			msgs = append(msgs, strings.TrimSpace(msg))
+		}
+	}
+	if len(msgs) > 0 {
+		return nil, fmt.Errorf("编译失败:\n%s", strings.Join(msgs, "\n"))
+	}
+
+	files := make(map[string][]byte)
+	for _, t := range cfg.Contracts {
+		c, ok := out.Contracts[t.Source][t.Contract]
+		if !ok {
+			return nil, fmt.Errorf("solc 输出中没有 %s:%s", t.Source, t.Contract)
+		}
+		var abiJSON bytes.Buffer
+		if err := json.Compact(&abiJSON, c.ABI); err != nil {
+			return nil, fmt.Errorf("%s 的 ABI 无效: %w", t.Contract, err)
+		}
+		bin := c.EVM.Bytecode.Object
+		if t.ABI != "" {
+			files[t.ABI] = abiJSON.Bytes()
+		}
+		if t.Bin != "" {
+			if bin == "" {
+				return nil, fmt.Errorf("%s 没有字节码（抽象合约或接口）", t.Contract)
+			}
+			files[t.Bin] = []byte(bin)
+		}
+		for _, b := range t.Bindings {
+			code := ""
+			if b.Deploy {
+				if bin == "" {
+					return nil, fmt.Errorf("%s 没有字节码，不能生成带 Deploy 的绑定", t.Contract)
+				}
+				code = bin
+			}
+			src, err := abigen.Bind([]string{b.Type}, []string{abiJSON.String()}, []string{code}, nil, b.Package, nil, nil)
+			if err != nil {
+				return nil, fmt.Errorf("生成 %s 失败: %w", b.Out, err)
+			}
+			files[b.Out] = []byte(src)
+		}
+	}
+	return files, nil
+}
+
+// metadataHash 匹配字节码末尾 CBOR 元数据中的 IPFS 哈希。它由源码路径、注释等决定，
+// 不影响合约行为，比较时忽略
+var metadataHash = regexp.MustCompile(`a264697066735822[0-9a-fA-F]{68}`)
+
+func normalize(b []byte) []byte {
+	return metadataHash.ReplaceAll(b, []byte("a264697066735822"+strings.Repeat("0", 68)))
+}
+
+// Check 比较构建产物与目录中的文件，返回不一致或缺失的文件（已排序），只差元数据哈希的视为一致
+func Check(dir string, files map[string][]byte) ([]string, error) {
+	var drifted []string
+	for path, want := range files {
+		got, err := os.ReadFile(filepath.Join(dir, path))
+		if errors.Is(err, fs.ErrNotExist) {
+			drifted = append(drifted, path)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !bytes.Equal(normalize(got), normalize(want)) {
+			drifted = append(drifted, path)
+		}
+	}
+	sort.Strings(drifted)
+	return drifted, nil
+}
+
+// Write 写入与目录中不一致的构建产物，返回写入的文件。只差元数据哈希的文件保持不变，
+// 避免换一台机器编译就产生无意义的改动
+func Write(dir string, files map[string][]byte) ([]string, error) {
+	drifted, err := Check(dir, files)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range drifted {
+		full := filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(full), 0o755); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(full, files[path], 0o644); err != nil {
+			return nil, fmt.Errorf("写入 %s 失败: %w", path, err)
+		}
+	}
+	return drifted, nil
+}
@@ -0,0 +1,184 @@
+package solbuild
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// contractsInput/contractsOutput 是 pkg/contracts 的 standard-json 输入和 solc 0.8.30 的编译输出，
+// 用于在没有 solc 的环境中检查漂移
+const (
+	contractsInput  = "testdata/contracts.input.json"
+	contractsOutput = "testdata/contracts.output.json"
+)
+
+// go test ./pkg/solbuild -record  用本地 solc 重新录制 pkg/contracts 的编译输入输出
+var record = flag.Bool("record", false, "用本地 solc 重新编译 pkg/contracts 并录制到 "+contractsOutput)
+
+// testdata/output.json 是 solc 0.8.30 编译 testdata/contracts 的 standard-json 输出
+func loadOutput(t *testing.T) Output {
+	t.Helper()
+	data, err := os.ReadFile("testdata/output.json")
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	return Output(data)
+}
+
+// copyDir 把 testdata/contracts 复制到临时目录
+func copyDir(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	err := filepath.Walk("testdata/contracts", func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		rel, _ := filepath.Rel("testdata/contracts", path)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Join(dir, filepath.Dir(rel)), 0o755); err != nil {
+			return err
+		}
+		return os.WriteFile(filepath.Join(dir, rel), data, 0o644)
+	})
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	return dir
+}
+
+// 测试生成构建产物、检查漂移，以及只差元数据哈希时视为一致
+func TestGenerate(t *testing.T) {
+	ctx := context.Background()
+	dir := copyDir(t)
+
+	files, err := Generate(ctx, dir, loadOutput(t))
+	if err != nil {
+		t.Fatalf("❌ 生成失败: %v", err)
+	}
+	for _, path := range []string{"counter/Counter.abi", "counter/Counter.bin", "counter/counter.go", "lib/Owned.abi"} {
+		if len(files[path]) == 0 {
+			t.Fatalf("❌ 缺少构建产物 %s", path)
+		}
+	}
+	if src := string(files["counter/counter.go"]); !strings.Contains(src, "package counter") || !strings.Contains(src, "func DeployCounter(") {
+		t.Fatal("❌ 绑定缺少 Deploy 函数")
+	}
+
+	drifted, err := Check(dir, files)
+	if err != nil || len(drifted) != len(files) {
+		t.Fatalf("❌ 产物尚未生成时应全部报告: %v %v", drifted, err)
+	}
+	written, err := Write(dir, files)
+	if err != nil || len(written) != len(files) {
+		t.Fatalf("❌ 写入失败: %v %v", written, err)
+	}
+	if drifted, _ := Check(dir, files); len(drifted) != 0 {
+		t.Fatalf("❌ 写入后不应有漂移: %v", drifted)
+	}
+
+	// 只改元数据哈希（例如在别的机器上编译）不算漂移，也不会重写
+	binPath := filepath.Join(dir, "counter/Counter.bin")
+	bin := string(files["counter/Counter.bin"])
+	loc := metadataHash.FindStringIndex(bin)
+	if loc == nil {
+		t.Fatal("❌ 字节码中没有元数据哈希")
+	}
+	other := bin[:loc[1]-4] + "ffff" + bin[loc[1]:]
+	if err := os.WriteFile(binPath, []byte(other), 0o644); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if written, _ := Write(dir, files); len(written) != 0 {
+		t.Fatalf("❌ 只差元数据哈希时不应重写: %v", written)
+	}
+
+	// 修改 ABI 和绑定后报告漂移
+	if err := os.WriteFile(filepath.Join(dir, "lib/Owned.abi"), []byte("[]"), 0o644); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "counter/counter.go"), []byte("package counter\n"), 0o644); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	drifted, _ = Check(dir, files)
+	if strings.Join(drifted, ",") != "counter/counter.go,lib/Owned.abi" {
+		t.Fatalf("❌ 漂移检查结果不符: %v", drifted)
+	}
+
+	// 未配置的源文件
+	if err := os.WriteFile(filepath.Join(dir, "lib/Extra.sol"), []byte("pragma solidity ^0.8.0;\n"), 0o644); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if _, err := Generate(ctx, dir, loadOutput(t)); err == nil || !strings.Contains(err.Error(), "lib/Extra.sol") {
+		t.Fatalf("❌ 未配置的源文件应报错: %v", err)
+	}
+	t.Logf("✅ 生成 %d 个构建产物", len(files))
+}
+
+// 测试编译错误的报告
+func TestCompileErrors(t *testing.T) {
+	out := Output(`{"errors":[{"severity":"warning","message":"unused"},{"severity":"error","formattedMessage":"ParserError: Expected ';'"}]}`)
+	if _, err := Generate(context.Background(), "testdata/contracts", out); err == nil || !strings.Contains(err.Error(), "ParserError") || strings.Contains(err.Error(), "unused") {
+		t.Fatalf("❌ 应只报告编译错误: %v", err)
+	}
+	t.Log("✅ 编译错误已报告")
+}
+
+// 用录制的编译输出检查 pkg/contracts 中提交的 ABI、字节码和绑定与源码一致；
+// 录制时的输入与当前源码不同时说明录制已过期，需要用 -record 重新录制
+func TestContractsUpToDate(t *testing.T) {
+	dir := filepath.Join("..", "contracts")
+	cfg, err := LoadConfig(dir)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	input, err := cfg.Input(dir)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+
+	if *record {
+		out, err := (&Solc{Version: cfg.Solc}).Compile(context.Background(), input)
+		if err != nil {
+			t.Fatalf("❌ 编译失败: %v", err)
+		}
+		if err := os.WriteFile(contractsInput, input, 0o644); err != nil {
+			t.Fatalf("❌ %v", err)
+		}
+		if err := os.WriteFile(contractsOutput, out, 0o644); err != nil {
+			t.Fatalf("❌ %v", err)
+		}
+		t.Logf("💾 已录制到 %s", contractsOutput)
+	}
+
+	recorded, err := os.ReadFile(contractsInput)
+	if err != nil {
+		t.Fatalf("❌ %v（运行 go test ./pkg/solbuild -record 录制）", err)
+	}
+	if !bytes.Equal(recorded, input) {
+		t.Fatalf("❌ pkg/contracts 的源码或配置已修改，录制的编译输出已过期（运行 go test ./pkg/solbuild -record 重新录制）")
+	}
+	out, err := os.ReadFile(contractsOutput)
+	if err != nil {
+		t.Fatalf("❌ %v（运行 go test ./pkg/solbuild -record 录制）", err)
+	}
+
+	files, err := Generate(context.Background(), dir, Output(out))
+	if err != nil {
+		t.Fatalf("❌ 编译失败: %v", err)
+	}
+	drifted, err := Check(dir, files)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if len(drifted) > 0 {
+		t.Fatalf("❌ %v: %s（运行 go run . contracts build 重新生成）", ErrDrift, strings.Join(drifted, ", "))
+	}
+	t.Logf("✅ %d 个构建产物与源码一致", len(files))
+}
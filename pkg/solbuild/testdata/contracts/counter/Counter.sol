@@ -0,0 +1,15 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.26;
+
+import "../lib/Owned.sol";
+
+contract Counter is Owned {
+    event Incremented(uint256 count);
+
+    uint256 public count;
+
+    function increment() external {
+        count += 1;
+        emit Incremented(count);
+    }
+}
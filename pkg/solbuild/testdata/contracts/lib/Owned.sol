@@ -0,0 +1,10 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.26;
+
+abstract contract Owned {
+    address public owner;
+
+    constructor() {
+        owner = msg.sender;
+    }
+}
@@ -0,0 +1,117 @@
+package storekv
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrTooLong 表示键或值超过 32 字节
+var ErrTooLong = errors.New("超过 32 字节")
+
+// Format 是字符串编码为 bytes32 的方式
+type Format int
+
+const (
+	// Text 按 UTF-8 文本编码，右侧补 0（与 copy(key[:], "mykey") 一致）。文本不能包含 NUL 字符，
+	// 否则无法与补齐的 0 区分
+	Text Format = iota
+	// Hex 按 0x 十六进制编码，不足 32 字节时右侧补 0
+	Hex
+	// Hash 取文本的 keccak256，任意长度都可以，但无法从链上还原原文
+	Hash
+)
+
+func (f Format) String() string {
+	switch f {
+	case Text:
+		return "text"
+	case Hex:
+		return "hex"
+	case Hash:
+		return "hash"
+	}
+	return fmt.Sprintf("Format(%d)", int(f))
+}
+
+// ParseFormat 解析 text、hex、hash
+func ParseFormat(s string) (Format, error) {
+	for _, f := range []Format{Text, Hex, Hash} {
+		if f.String() == s {
+			return f, nil
+		}
+	}
+	return 0, fmt.Errorf("未知的编码 %q（可选 text、hex、hash）", s)
+}
+
+// Codec 是键或值的编码规则
+type Codec struct {
+	Format Format
+	// HashLong 为 true 时超过 32 字节的文本改为取 keccak256，否则返回 ErrTooLong
+	HashLong bool
+}
+
+// Encode 把字符串编码为 bytes32
+func (c Codec) Encode(s string) ([32]byte, error) {
+	var out [32]byte
+	switch c.Format {
+	case Text:
+		if strings.ContainsRune(s, 0) {
+			return out, fmt.Errorf("文本 %q 包含 NUL 字符", s)
+		}
+		if len(s) > 32 {
+			if c.HashLong {
+				return crypto.Keccak256Hash([]byte(s)), nil
+			}
+			return out, fmt.Errorf("%w: %q 为 %d 字节", ErrTooLong, s, len(s))
+		}
+		copy(out[:], s)
+	case Hex:
+		if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+			return out, fmt.Errorf("十六进制 %q 需要 0x 前缀", s)
+		}
+		raw, err := hex.DecodeString(s[2:])
+		if err != nil {
+			return out, fmt.Errorf("无效的十六进制 %q: %w", s, err)
+		}
+		if len(raw) > 32 {
+			return out, fmt.Errorf("%w: %s 为 %d 字节", ErrTooLong, s, len(raw))
+		}
+		copy(out[:], raw)
+	case Hash:
+		return crypto.Keccak256Hash([]byte(s)), nil
+	default:
+		return out, fmt.Errorf("未知的编码 %v", c.Format)
+	}
+	return out, nil
+}
+
+// Decode 把 bytes32 还原为字符串。Text 编码下去掉右侧的 0 后是可打印的 UTF-8 文本时返回文本，
+// 否则（包括超长文本取了哈希的情况）与 Hex、Hash 编码一样返回 0x 十六进制
+func (c Codec) Decode(b [32]byte) string {
+	if c.Format == Text {
+		if s, ok := text(b); ok {
+			return s
+		}
+	}
+	return "0x" + hex.EncodeToString(b[:])
+}
+
+// text 判断 b 是否是右侧补 0 的可打印文本
+func text(b [32]byte) (string, bool) {
+	s := strings.TrimRight(string(b[:]), "\x00")
+	if !utf8.ValidString(s) || strings.ContainsRune(s, 0) {
+		return "", false
+	}
+	for _, r := range s {
+		if !unicode.IsPrint(r) && !unicode.IsSpace(r) {
+			return "", false
+		}
+	}
+	return s, true
+}
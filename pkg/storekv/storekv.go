@@ -0,0 +1,302 @@
+// Package storekv 是 Store 合约之上的键值服务：按明确的规则把字符串键值编码为 bytes32，
+// 批量写入（连续 nonce 一次发出再统一等待确认），通过 Multicall3 批量读取，
+// 并从 ItemSet 事件重建每个键的写入历史
+package storekv
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/multicall"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/txutil"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// storeABI 是 Store 的 ABI，用于 Multicall3 批量读取
+var storeABI = func() *abi.ABI {
+	parsed, err := store.StoreMetaData.GetAbi()
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}()
+
+// Backend 是键值服务需要的节点接口，*ethclient.Client 满足
+type Backend interface {
+	bind.ContractBackend
+	ethereum.TransactionReader
+}
+
+// Item 是一个键值对（编码前的字符串）
+type Item struct {
+	Key   string
+	Value string
+}
+
+// Store 是 Store 合约的键值服务
+type Store struct {
+	Address common.Address
+	Keys    Codec // 键的编码，默认为文本
+	Values  Codec // 值的编码，默认为文本
+
+	// SkipUnchanged 为 true 时批量写入前先读取链上的值，跳过没有变化的键
+	SkipUnchanged bool
+	PollInterval  time.Duration // 等待交易确认的轮询间隔，默认 1 秒
+
+	backend  Backend
+	contract *store.Store
+	calls    *multicall.Caller
+}
+
+// New 创建键值服务
+func New(addr common.Address, backend Backend) (*Store, error) {
+	contract, err := store.NewStore(addr, backend)
+	if err != nil {
+		return nil, fmt.Errorf("绑定 Store 合约失败: %w", err)
+	}
+	return &Store{
+		Address:      addr,
+		PollInterval: time.Second,
+		backend:      backend,
+		contract:     contract,
+		calls:        multicall.New(backend),
+	}, nil
+}
+
+// Multicall 返回批量读取使用的聚合调用器，可调整分块参数或 Multicall3 地址
+func (s *Store) Multicall() *multicall.Caller {
+	return s.calls
+}
+
+func (s *Store) encode(it Item) ([32]byte, [32]byte, error) {
+	key, err := s.Keys.Encode(it.Key)
+	if err != nil {
+		return key, [32]byte{}, fmt.Errorf("键 %q: %w", it.Key, err)
+	}
+	value, err := s.Values.Encode(it.Value)
+	if err != nil {
+		return key, value, fmt.Errorf("键 %q 的值: %w", it.Key, err)
+	}
+	return key, value, nil
+}
+
+// Set 写入一个键值并等待确认
+func (s *Store) Set(ctx context.Context, opts *bind.TransactOpts, key, value string) (*types.Receipt, error) {
+	receipts, err := s.SetBatch(ctx, opts, []Item{{Key: key, Value: value}})
+	if err != nil {
+		return nil, err
+	}
+	if len(receipts) == 0 {
+		return nil, nil
+	}
+	return receipts[0], nil
+}
+
+// SetBatch 批量写入：先编码全部键值（任何一个不合法都不发送），再用连续的 nonce 依次发出交易，
+// 最后统一等待确认。同一个键出现多次时只写最后一次的值。返回的收据与实际发送的交易一一对应，
+// 开启 SkipUnchanged 时没有变化的键不发送交易。发送或确认失败时返回已得到的收据和错误
+func (s *Store) SetBatch(ctx context.Context, opts *bind.TransactOpts, items []Item) ([]*types.Receipt, error) {
+	type entry struct {
+		key, value [32]byte
+		item       Item
+	}
+	index := make(map[[32]byte]int)
+	var entries []entry
+	for _, it := range items {
+		key, value, err := s.encode(it)
+		if err != nil {
+			return nil, err
+		}
+		if i, ok := index[key]; ok {
+			entries[i].value, entries[i].item = value, it
+			continue
+		}
+		index[key] = len(entries)
+		entries = append(entries, entry{key: key, value: value, item: it})
+	}
+
+	if s.SkipUnchanged && len(entries) > 0 {
+		keys := make([][32]byte, len(entries))
+		for i, e := range entries {
+			keys[i] = e.key
+		}
+		current, err := s.getRaw(ctx, keys, nil)
+		if err != nil {
+			return nil, err
+		}
+		changed := entries[:0]
+		for i, e := range entries {
+			if current[i] != e.value {
+				changed = append(changed, e)
+			}
+		}
+		entries = changed
+	}
+	if len(entries) == 0 {
+		return nil, nil
+	}
+
+	auth := *opts
+	auth.Context = ctx
+	if auth.Nonce == nil {
+		nonce, err := s.backend.PendingNonceAt(ctx, opts.From)
+		if err != nil {
+			return nil, fmt.Errorf("获取 nonce 失败: %w", chainerr.Wrap(err))
+		}
+		auth.Nonce = new(big.Int).SetUint64(nonce)
+	}
+	txs := make([]*types.Transaction, 0, len(entries))
+	var sendErr error
+	for _, e := range entries {
+		tx, err := s.contract.SetItem(&auth, e.key, e.value)
+		if err != nil {
+			sendErr = fmt.Errorf("发送键 %q 的交易失败: %w", e.item.Key, chainerr.Wrap(err))
+			break
+		}
+		txs = append(txs, tx)
+		auth.Nonce = new(big.Int).Add(auth.Nonce, big.NewInt(1))
+	}
+
+	receipts := make([]*types.Receipt, 0, len(txs))
+	for _, tx := range txs {
+		receipt, err := txutil.WaitSuccess(ctx, s.backend, tx, s.PollInterval)
+		if err != nil {
+			return receipts, fmt.Errorf("交易 %s: %w", tx.Hash().Hex(), err)
+		}
+		receipts = append(receipts, receipt)
+	}
+	return receipts, sendErr
+}
+
+// Get 读取一个键的值，block 为 nil 表示最新区块。未写入的键返回空字符串（Text）或全 0 的十六进制
+func (s *Store) Get(ctx context.Context, key string, block *big.Int) (string, error) {
+	values, err := s.GetMany(ctx, []string{key}, block)
+	if err != nil {
+		return "", err
+	}
+	return values[0], nil
+}
+
+// GetMany 通过 Multicall3 批量读取，结果与 keys 一一对应（链上没有 Multicall3 时逐个读取）
+func (s *Store) GetMany(ctx context.Context, keys []string, block *big.Int) ([]string, error) {
+	encoded := make([][32]byte, len(keys))
+	for i, k := range keys {
+		key, err := s.Keys.Encode(k)
+		if err != nil {
+			return nil, fmt.Errorf("键 %q: %w", k, err)
+		}
+		encoded[i] = key
+	}
+	raw, err := s.getRaw(ctx, encoded, block)
+	if err != nil {
+		return nil, err
+	}
+	values := make([]string, len(raw))
+	for i, v := range raw {
+		values[i] = s.Values.Decode(v)
+	}
+	return values, nil
+}
+
+func (s *Store) getRaw(ctx context.Context, keys [][32]byte, block *big.Int) ([][32]byte, error) {
+	calls := make([]multicall.Call, len(keys))
+	for i, key := range keys {
+		calls[i] = multicall.NewCall(s.Address, storeABI, "items", key)
+	}
+	results, err := s.calls.Aggregate(ctx, calls, block)
+	if err != nil {
+		return nil, fmt.Errorf("批量读取失败: %w", err)
+	}
+	out := make([][32]byte, len(results))
+	for i, r := range results {
+		v, err := multicall.Value[[32]byte](r, 0)
+		if err != nil {
+			return nil, fmt.Errorf("读取第 %d 个键失败: %w", i, err)
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+// Change 是一次 ItemSet 写入
+type Change struct {
+	Key      string
+	Value    string
+	RawKey   [32]byte
+	RawValue [32]byte
+	Block    uint64
+	TxHash   common.Hash
+	TxIndex  uint
+	LogIndex uint
+}
+
+// Changes 按区块顺序返回 [from, to] 范围内的全部写入，to 为 nil 表示最新区块
+func (s *Store) Changes(ctx context.Context, from uint64, to *uint64) ([]Change, error) {
+	it, err := s.contract.FilterItemSet(&bind.FilterOpts{Start: from, End: to, Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("查询 ItemSet 事件失败: %w", chainerr.Wrap(err))
+	}
+	defer it.Close()
+	var changes []Change
+	for it.Next() {
+		ev := it.Event
+		changes = append(changes, Change{
+			Key:      s.Keys.Decode(ev.Key),
+			Value:    s.Values.Decode(ev.Value),
+			RawKey:   ev.Key,
+			RawValue: ev.Value,
+			Block:    ev.Raw.BlockNumber,
+			TxHash:   ev.Raw.TxHash,
+			TxIndex:  ev.Raw.TxIndex,
+			LogIndex: ev.Raw.Index,
+		})
+	}
+	if err := it.Error(); err != nil {
+		return nil, fmt.Errorf("读取 ItemSet 事件失败: %w", chainerr.Wrap(err))
+	}
+	sort.SliceStable(changes, func(i, j int) bool {
+		a, b := changes[i], changes[j]
+		if a.Block != b.Block {
+			return a.Block < b.Block
+		}
+		return a.LogIndex < b.LogIndex
+	})
+	return changes, nil
+}
+
+// History 返回一个键从 from 区块起的全部写入（ItemSet 的参数没有 indexed，只能在本地过滤）
+func (s *Store) History(ctx context.Context, key string, from uint64) ([]Change, error) {
+	encoded, err := s.Keys.Encode(key)
+	if err != nil {
+		return nil, fmt.Errorf("键 %q: %w", key, err)
+	}
+	changes, err := s.Changes(ctx, from, nil)
+	if err != nil {
+		return nil, err
+	}
+	var out []Change
+	for _, c := range changes {
+		if c.RawKey == encoded {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// Snapshot 按写入历史重建每个键的最新值（键为解码后的字符串），changes 须按区块顺序排列
+func Snapshot(changes []Change) map[string]Change {
+	latest := make(map[string]Change)
+	for _, c := range changes {
+		latest[c.Key] = c
+	}
+	return latest
+}
@@ -0,0 +1,159 @@
+package storekv
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/multicall"
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// 测试键值编码规则
+func TestCodec(t *testing.T) {
+	long := strings.Repeat("k", 33)
+	for _, tc := range []struct {
+		codec Codec
+		in    string
+		want  string // 解码结果，为空时只检查报错
+		err   string
+	}{
+		{Codec{Format: Text}, "mykey", "mykey", ""},
+		{Codec{Format: Text}, "名字", "名字", ""},
+		{Codec{Format: Text}, strings.Repeat("v", 32), strings.Repeat("v", 32), ""},
+		{Codec{Format: Text}, long, "", "超过 32 字节"},
+		{Codec{Format: Text}, "a\x00b", "", "NUL"},
+		{Codec{Format: Text, HashLong: true}, long, crypto.Keccak256Hash([]byte(long)).Hex(), ""},
+		{Codec{Format: Hex}, "0x01ff", "0x01ff" + strings.Repeat("0", 60), ""},
+		{Codec{Format: Hex}, "01ff", "", "0x 前缀"},
+		{Codec{Format: Hex}, "0x" + strings.Repeat("00", 33), "", "超过 32 字节"},
+		{Codec{Format: Hash}, "anything", crypto.Keccak256Hash([]byte("anything")).Hex(), ""},
+	} {
+		b, err := tc.codec.Encode(tc.in)
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Fatalf("❌ %v 编码 %q 应报错 %q: %v", tc.codec.Format, tc.in, tc.err, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("❌ %v 编码 %q 失败: %v", tc.codec.Format, tc.in, err)
+		}
+		if got := tc.codec.Decode(b); got != tc.want {
+			t.Fatalf("❌ %v 编码 %q 后解码为 %q，期望 %q", tc.codec.Format, tc.in, got, tc.want)
+		}
+	}
+	if _, err := (Codec{Format: Text}).Encode(strings.Repeat("k", 40)); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("❌ 应返回 ErrTooLong: %v", err)
+	}
+	if f, err := ParseFormat("hash"); err != nil || f != Hash {
+		t.Fatalf("❌ 解析编码失败: %v %v", f, err)
+	}
+	t.Log("✅ 编码规则正确")
+}
+
+func newStore(t *testing.T, chain *simchain.Chain) *Store {
+	t.Helper()
+	addr, _ := chain.DeployStore("v1.0.0")
+	s, err := New(addr, chain.Client)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	s.PollInterval = 5 * time.Millisecond
+	return s
+}
+
+// 测试批量写入、Multicall3 批量读取和历史重建
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t, simchain.WithAlloc(multicall.Address, types.Account{Code: multicall.RuntimeCode, Balance: big.NewInt(0)}))
+	chain.AutoMine(10 * time.Millisecond)
+	s := newStore(t, chain)
+	auth := chain.Auth(0)
+
+	// 任何一个键值不合法时不发送交易
+	if _, err := s.SetBatch(ctx, auth, []Item{{"a", "1"}, {strings.Repeat("k", 33), "2"}}); !errors.Is(err, ErrTooLong) {
+		t.Fatalf("❌ 超长键应报错: %v", err)
+	}
+
+	receipts, err := s.SetBatch(ctx, auth, []Item{{"name", "alice"}, {"city", "paris"}, {"name", "bob"}})
+	if err != nil {
+		t.Fatalf("❌ 批量写入失败: %v", err)
+	}
+	if len(receipts) != 2 {
+		t.Fatalf("❌ 重复的键应只写一次，实际发送 %d 笔交易", len(receipts))
+	}
+	if ok, _ := s.Multicall().Available(ctx); !ok {
+		t.Fatal("❌ 模拟链上应有 Multicall3")
+	}
+	values, err := s.GetMany(ctx, []string{"name", "city", "missing"}, nil)
+	if err != nil || strings.Join(values, ",") != "bob,paris," {
+		t.Fatalf("❌ 批量读取结果不符: %q %v", values, err)
+	}
+
+	// 没有变化的键不发送交易
+	s.SkipUnchanged = true
+	receipts, err = s.SetBatch(ctx, auth, []Item{{"name", "bob"}, {"city", "rome"}})
+	if err != nil || len(receipts) != 1 {
+		t.Fatalf("❌ 应只写入有变化的键: %d %v", len(receipts), err)
+	}
+	if r, err := s.Set(ctx, auth, "city", "rome"); err != nil || r != nil {
+		t.Fatalf("❌ 值没有变化时不应发送交易: %v %v", r, err)
+	}
+	if v, err := s.Get(ctx, "city", nil); err != nil || v != "rome" {
+		t.Fatalf("❌ 读取失败: %q %v", v, err)
+	}
+
+	// 按历史区块读取
+	old, err := s.Get(ctx, "city", new(big.Int).Sub(receipts[0].BlockNumber, big.NewInt(1)))
+	if err != nil || old != "paris" {
+		t.Fatalf("❌ 读取历史区块失败: %q %v", old, err)
+	}
+
+	history, err := s.History(ctx, "city", 0)
+	if err != nil || len(history) != 2 || history[0].Value != "paris" || history[1].Value != "rome" {
+		t.Fatalf("❌ 键的历史不符: %+v %v", history, err)
+	}
+	changes, err := s.Changes(ctx, 0, nil)
+	if err != nil || len(changes) != 3 {
+		t.Fatalf("❌ 全部写入不符: %+v %v", changes, err)
+	}
+	snapshot := Snapshot(changes)
+	if len(snapshot) != 2 || snapshot["name"].Value != "bob" || snapshot["city"].Value != "rome" {
+		t.Fatalf("❌ 重建的状态不符: %+v", snapshot)
+	}
+	t.Logf("✅ %d 次写入，%d 个键", len(changes), len(snapshot))
+}
+
+// 测试十六进制和哈希编码，以及链上没有 Multicall3 时逐个读取
+func TestStoreEncodings(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t)
+	chain.AutoMine(10 * time.Millisecond)
+	s := newStore(t, chain)
+	s.Keys = Codec{Format: Hash}
+	s.Values = Codec{Format: Hex}
+
+	long := strings.Repeat("很长的键", 10)
+	value := "0x" + strings.Repeat("ab", 32)
+	if _, err := s.Set(ctx, chain.Auth(0), long, value); err != nil {
+		t.Fatalf("❌ 写入失败: %v", err)
+	}
+	if ok, _ := s.Multicall().Available(ctx); ok {
+		t.Fatal("❌ 模拟链上不应有 Multicall3")
+	}
+	got, err := s.Get(ctx, long, nil)
+	if err != nil || got != value {
+		t.Fatalf("❌ 读取失败: %q %v", got, err)
+	}
+	history, err := s.History(ctx, long, 0)
+	if err != nil || len(history) != 1 || history[0].Key != crypto.Keccak256Hash([]byte(long)).Hex() {
+		t.Fatalf("❌ 哈希键的历史不符: %+v %v", history, err)
+	}
+	t.Logf("✅ %s = %s", history[0].Key, got)
+}
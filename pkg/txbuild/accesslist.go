@@ -0,0 +1,161 @@
+package txbuild
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// Report 是访问列表的生成结果
+type Report struct {
+	AccessList types.AccessList
+	GasWithout uint64 // 不带访问列表时估算的 gas
+	GasWith    uint64 // 带访问列表时估算的 gas
+	// ExecError 是 eth_createAccessList 模拟执行的错误（例如 revert），此时列表可能不完整，不会附加
+	ExecError string
+}
+
+// Saves 返回附加访问列表是否能节省 gas
+func (r *Report) Saves() bool {
+	return r.ExecError == "" && len(r.AccessList) > 0 && r.GasWith < r.GasWithout
+}
+
+// Gas 返回应使用的 gas 上限：能节省时为带列表的估算值，否则为不带列表的估算值
+func (r *Report) Gas() uint64 {
+	if r.Saves() {
+		return r.GasWith
+	}
+	return r.GasWithout
+}
+
+// Savings 返回附加访问列表节省的 gas，负数表示更贵
+func (r *Report) Savings() int64 {
+	return int64(r.GasWithout) - int64(r.GasWith)
+}
+
+// Addresses 返回访问列表中的地址
+func (r *Report) Addresses() []common.Address {
+	out := make([]common.Address, len(r.AccessList))
+	for i, t := range r.AccessList {
+		out[i] = t.Address
+	}
+	return out
+}
+
+// String 输出供审计的报告：gas 对比以及交易会访问的地址和存储槽
+func (r *Report) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "访问列表: %d 个地址, %d 个存储槽\n", len(r.AccessList), r.AccessList.StorageKeys())
+	fmt.Fprintf(&b, "gas: 不带列表 %d, 带列表 %d, 节省 %d", r.GasWithout, r.GasWith, r.Savings())
+	if r.Saves() {
+		b.WriteString("（附加）\n")
+	} else {
+		b.WriteString("（不附加）\n")
+	}
+	if r.ExecError != "" {
+		fmt.Fprintf(&b, "执行错误: %s\n", r.ExecError)
+	}
+	for _, t := range r.AccessList {
+		fmt.Fprintf(&b, "  %s\n", t.Address.Hex())
+		for _, slot := range t.StorageKeys {
+			fmt.Fprintf(&b, "    %s\n", slot.Hex())
+		}
+	}
+	return b.String()
+}
+
+// CreateAccessList 对交易调用 eth_createAccessList，并分别估算带和不带访问列表的 gas。
+// 节点认为交易会访问的 from、to 和预编译合约地址本身已经是 warm 的，不会出现在列表中（它们的存储槽仍会出现）
+func (b *Builder) CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (*Report, error) {
+	var result struct {
+		AccessList *types.AccessList `json:"accessList"`
+		Error      string            `json:"error,omitempty"`
+		GasUsed    hexutil.Uint64    `json:"gasUsed"`
+	}
+	if err := b.rpc.CallContext(ctx, &result, "eth_createAccessList", toCallArg(msg), "latest"); err != nil {
+		return nil, fmt.Errorf("生成访问列表失败: %w", chainerr.Wrap(err))
+	}
+	report := &Report{ExecError: result.Error}
+	if result.AccessList != nil {
+		report.AccessList = *result.AccessList
+	}
+
+	without := msg
+	without.AccessList = nil
+	gas, err := b.client.EstimateGas(ctx, without)
+	if err != nil {
+		return nil, fmt.Errorf("估算 gas 失败: %w", chainerr.Wrap(err))
+	}
+	report.GasWithout, report.GasWith = gas, gas
+	if len(report.AccessList) > 0 && report.ExecError == "" {
+		with := msg
+		with.AccessList = report.AccessList
+		if report.GasWith, err = b.client.EstimateGas(ctx, with); err != nil {
+			return nil, fmt.Errorf("估算带访问列表的 gas 失败: %w", chainerr.Wrap(err))
+		}
+	}
+	return report, nil
+}
+
+// Attach 为 abigen 绑定或 bind.BoundContract 的交易生成访问列表：能节省 gas 时设置 opts.AccessList
+// （绑定会因此发送 DynamicFeeTx）。opts.GasPrice 已设置时绑定只能发送不支持访问列表的 LegacyTx，不附加
+func (b *Builder) Attach(ctx context.Context, opts *bind.TransactOpts, to *common.Address, data []byte) (*Report, error) {
+	report, err := b.CreateAccessList(ctx, ethereum.CallMsg{From: opts.From, To: to, Value: opts.Value, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	if report.Saves() && opts.GasPrice == nil {
+		opts.AccessList = report.AccessList
+		if opts.GasLimit == 0 {
+			opts.GasLimit = report.GasWith
+		}
+	}
+	return report, nil
+}
+
+// Apply 把访问列表附加到 AccessListTx 或 DynamicFeeTx 的副本上，gas 不低于带列表的估算值。
+// 不节省 gas 或是其他交易类型（LegacyTx 不能携带访问列表，请用 Builder 构造 AccessListTx）时原样返回 tx 和 false
+func Apply(tx types.TxData, report *Report) (types.TxData, bool) {
+	if !report.Saves() {
+		return tx, false
+	}
+	switch t := tx.(type) {
+	case *types.AccessListTx:
+		cp := *t
+		cp.AccessList, cp.Gas = report.AccessList, max(t.Gas, report.GasWith)
+		return &cp, true
+	case *types.DynamicFeeTx:
+		cp := *t
+		cp.AccessList, cp.Gas = report.AccessList, max(t.Gas, report.GasWith)
+		return &cp, true
+	}
+	return tx, false
+}
+
+// toCallArg 与 ethclient 中的转换一致
+func toCallArg(msg ethereum.CallMsg) interface{} {
+	arg := map[string]interface{}{
+		"from": msg.From,
+		"to":   msg.To,
+	}
+	if len(msg.Data) > 0 {
+		arg["input"] = hexutil.Bytes(msg.Data)
+	}
+	if msg.Value != nil {
+		arg["value"] = (*hexutil.Big)(msg.Value)
+	}
+	if msg.Gas != 0 {
+		arg["gas"] = hexutil.Uint64(msg.Gas)
+	}
+	if msg.AccessList != nil {
+		arg["accessList"] = msg.AccessList
+	}
+	return arg
+}
@@ -0,0 +1,282 @@
+package txbuild
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/holiman/uint256"
+)
+
+// 负载编码：每个 32 字节的域元素只使用低 31 字节（最高字节为 0，保证小于 BLS12-381 的模数），
+// 负载前加 4 字节大端长度，依次写入各个 blob，剩余部分填 0
+const (
+	usableBytes = params.BlobTxBytesPerFieldElement - 1
+	lengthBytes = 4
+
+	// BlobCapacity 是单个 blob 可容纳的字节数（不含长度前缀）
+	BlobCapacity = params.BlobTxFieldElementsPerBlob * usableBytes
+	// MaxBlobs 是单笔交易最多携带的 blob 数
+	MaxBlobs = params.BlobTxMaxBlobs
+	// MaxPayload 是单笔交易可携带的最大负载
+	MaxPayload = MaxBlobs*BlobCapacity - lengthBytes
+)
+
+var (
+	// ErrPayloadTooLarge 表示负载超过单笔交易的 blob 容量
+	ErrPayloadTooLarge = errors.New("负载超过 blob 容量")
+	// ErrInvalidBlob 表示 blob 不是按本包的规则编码的
+	ErrInvalidBlob = errors.New("blob 编码无效")
+)
+
+// EncodeBlobs 把任意字节编码为尽量少的 blob（空负载也占一个 blob）
+func EncodeBlobs(payload []byte) ([]kzg4844.Blob, error) {
+	if len(payload) > MaxPayload {
+		return nil, fmt.Errorf("%w: %d 字节，最多 %d 字节", ErrPayloadTooLarge, len(payload), MaxPayload)
+	}
+	data := make([]byte, lengthBytes+len(payload))
+	binary.BigEndian.PutUint32(data, uint32(len(payload)))
+	copy(data[lengthBytes:], payload)
+
+	blobs := make([]kzg4844.Blob, (len(data)+BlobCapacity-1)/BlobCapacity)
+	for i := 0; len(data) > 0; i++ {
+		blob := &blobs[i/params.BlobTxFieldElementsPerBlob]
+		offset := (i%params.BlobTxFieldElementsPerBlob)*params.BlobTxBytesPerFieldElement + 1
+		data = data[copy(blob[offset:offset+usableBytes], data):]
+	}
+	return blobs, nil
+}
+
+// DecodeBlobs 还原 EncodeBlobs 编码的负载，并检查每个域元素的最高字节和填充部分都为 0
+func DecodeBlobs(blobs []kzg4844.Blob) ([]byte, error) {
+	if len(blobs) == 0 {
+		return nil, fmt.Errorf("%w: 没有 blob", ErrInvalidBlob)
+	}
+	data := make([]byte, 0, len(blobs)*BlobCapacity)
+	for i := range blobs {
+		for j := 0; j < params.BlobTxFieldElementsPerBlob; j++ {
+			fe := blobs[i][j*params.BlobTxBytesPerFieldElement : (j+1)*params.BlobTxBytesPerFieldElement]
+			if fe[0] != 0 {
+				return nil, fmt.Errorf("%w: 第 %d 个 blob 的第 %d 个域元素最高字节不为 0", ErrInvalidBlob, i, j)
+			}
+			data = append(data, fe[1:]...)
+		}
+	}
+	size := int(binary.BigEndian.Uint32(data))
+	if size > len(data)-lengthBytes {
+		return nil, fmt.Errorf("%w: 长度前缀 %d 超过 %d 个 blob 的容量", ErrInvalidBlob, size, len(blobs))
+	}
+	payload, padding := data[lengthBytes:lengthBytes+size], data[lengthBytes+size:]
+	if len(padding) >= BlobCapacity {
+		return nil, fmt.Errorf("%w: 存在多余的 blob", ErrInvalidBlob)
+	}
+	for _, b := range padding {
+		if b != 0 {
+			return nil, fmt.Errorf("%w: 负载之后的填充不为 0", ErrInvalidBlob)
+		}
+	}
+	return payload, nil
+}
+
+// NewSidecar 为 blob 计算 KZG 承诺和证明。version 为 types.BlobSidecarVersion0 时每个 blob 一个证明（Osaka 之前），
+// 为 types.BlobSidecarVersion1 时每个 blob 128 个 cell 证明（EIP-7594，Osaka 之后节点只接受这种格式）
+func NewSidecar(blobs []kzg4844.Blob, version byte) (*types.BlobTxSidecar, error) {
+	if len(blobs) == 0 || len(blobs) > MaxBlobs {
+		return nil, fmt.Errorf("blob 数量 %d 不在 1 到 %d 之间", len(blobs), MaxBlobs)
+	}
+	commitments := make([]kzg4844.Commitment, len(blobs))
+	var proofs []kzg4844.Proof
+	for i := range blobs {
+		commitment, err := kzg4844.BlobToCommitment(&blobs[i])
+		if err != nil {
+			return nil, fmt.Errorf("计算第 %d 个 blob 的承诺失败: %w", i, err)
+		}
+		commitments[i] = commitment
+		switch version {
+		case types.BlobSidecarVersion0:
+			proof, err := kzg4844.ComputeBlobProof(&blobs[i], commitment)
+			if err != nil {
+				return nil, fmt.Errorf("计算第 %d 个 blob 的证明失败: %w", i, err)
+			}
+			proofs = append(proofs, proof)
+		case types.BlobSidecarVersion1:
+			cells, err := kzg4844.ComputeCellProofs(&blobs[i])
+			if err != nil {
+				return nil, fmt.Errorf("计算第 %d 个 blob 的 cell 证明失败: %w", i, err)
+			}
+			proofs = append(proofs, cells...)
+		default:
+			return nil, fmt.Errorf("不支持的 sidecar 版本 %d", version)
+		}
+	}
+	return types.NewBlobTxSidecar(version, blobs, commitments, proofs), nil
+}
+
+// VerifySidecar 校验 sidecar 的承诺和证明，hashes 不为空时同时校验交易中的 blob 版本化哈希
+func VerifySidecar(sc *types.BlobTxSidecar, hashes []common.Hash) error {
+	if len(sc.Commitments) != len(sc.Blobs) {
+		return fmt.Errorf("承诺数量 %d 与 blob 数量 %d 不一致", len(sc.Commitments), len(sc.Blobs))
+	}
+	if hashes != nil {
+		if err := sc.ValidateBlobCommitmentHashes(hashes); err != nil {
+			return fmt.Errorf("blob 哈希不符: %w", err)
+		}
+	}
+	switch sc.Version {
+	case types.BlobSidecarVersion0:
+		if len(sc.Proofs) != len(sc.Blobs) {
+			return fmt.Errorf("证明数量 %d 与 blob 数量 %d 不一致", len(sc.Proofs), len(sc.Blobs))
+		}
+		for i := range sc.Blobs {
+			if err := kzg4844.VerifyBlobProof(&sc.Blobs[i], sc.Commitments[i], sc.Proofs[i]); err != nil {
+				return fmt.Errorf("第 %d 个 blob 的证明无效: %w", i, err)
+			}
+		}
+	case types.BlobSidecarVersion1:
+		if len(sc.Proofs) != len(sc.Blobs)*kzg4844.CellProofsPerBlob {
+			return fmt.Errorf("cell 证明数量 %d 应为 %d", len(sc.Proofs), len(sc.Blobs)*kzg4844.CellProofsPerBlob)
+		}
+		if err := kzg4844.VerifyCellProofs(sc.Blobs, sc.Commitments, sc.Proofs); err != nil {
+			return fmt.Errorf("cell 证明无效: %w", err)
+		}
+	default:
+		return fmt.Errorf("不支持的 sidecar 版本 %d", sc.Version)
+	}
+	return nil
+}
+
+// BlobBaseFee 按 EIP-4844 的公式 fake_exponential(1, excessBlobGas, updateFraction) 计算每单位 blob gas 的价格。
+// updateFraction 随分叉变化，可从 params.ChainConfig.BlobScheduleConfig 中取得
+func BlobBaseFee(excessBlobGas, updateFraction uint64) *big.Int {
+	var (
+		numerator   = new(big.Int).SetUint64(excessBlobGas)
+		denominator = new(big.Int).SetUint64(updateFraction)
+		output      = new(big.Int)
+		accum       = new(big.Int).Mul(big.NewInt(params.BlobTxMinBlobGasprice), denominator)
+	)
+	for i := 1; accum.Sign() > 0; i++ {
+		output.Add(output, accum)
+		accum.Mul(accum, numerator)
+		accum.Div(accum, denominator)
+		accum.Div(accum, big.NewInt(int64(i)))
+	}
+	return output.Div(output, denominator)
+}
+
+// NextBlobBaseFee 由 parent 的 excessBlobGas 和 blobGasUsed 推算时间为 time 的下一个区块的 blob 价格
+func NextBlobBaseFee(config *params.ChainConfig, parent *types.Header, time uint64) (*big.Int, error) {
+	if !config.IsCancun(parent.Number, parent.Time) || parent.ExcessBlobGas == nil {
+		return nil, errors.New("链尚未启用 EIP-4844")
+	}
+	excess := eip4844.CalcExcessBlobGas(config, parent, time)
+	next := &types.Header{Number: new(big.Int).Add(parent.Number, common.Big1), Time: time, ExcessBlobGas: &excess}
+	return eip4844.CalcBlobFee(config, next), nil
+}
+
+// BlobFee 返回下一个区块的 blob 价格：设置了 ChainConfig 时由最新区块的 excessBlobGas 在本地计算，否则调用 eth_blobBaseFee
+func (b *Builder) BlobFee(ctx context.Context) (*big.Int, error) {
+	if b.ChainConfig == nil {
+		fee, err := b.client.BlobBaseFee(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("获取 blob 价格失败: %w", chainerr.Wrap(err))
+		}
+		return fee, nil
+	}
+	head, err := b.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("获取最新区块失败: %w", chainerr.Wrap(err))
+	}
+	return NextBlobBaseFee(b.ChainConfig, head, head.Time+12)
+}
+
+// BlobRequest 是待构造的 blob 交易（类型 3）
+type BlobRequest struct {
+	From    common.Address
+	To      common.Address // blob 交易不能创建合约
+	Value   *big.Int
+	Data    []byte
+	Payload []byte // 编码进 blob 的负载
+
+	Nonce      *uint64  // 为 nil 时使用 pending nonce
+	Gas        uint64   // 为 0 时估算
+	GasTipCap  *big.Int // 为 nil 时使用节点建议值
+	GasFeeCap  *big.Int // 为 nil 时为 tip + baseFee*FeeMultiplier
+	BlobFeeCap *big.Int // maxFeePerBlobGas，为 nil 时为 BlobFee*FeeMultiplier
+
+	// LegacyProofs 为 true 时每个 blob 只附一个证明（types.BlobSidecarVersion0），用于尚未进入 Osaka 的链；
+	// 默认附 cell 证明（types.BlobSidecarVersion1）
+	LegacyProofs bool
+}
+
+// BuildBlob 把负载编码为 blob，计算承诺和证明，构造带 sidecar 的未签名交易，可直接交给 Send
+func (b *Builder) BuildBlob(ctx context.Context, req BlobRequest) (*types.BlobTx, error) {
+	blobs, err := EncodeBlobs(req.Payload)
+	if err != nil {
+		return nil, err
+	}
+	version := types.BlobSidecarVersion1
+	if req.LegacyProofs {
+		version = types.BlobSidecarVersion0
+	}
+	sidecar, err := NewSidecar(blobs, version)
+	if err != nil {
+		return nil, err
+	}
+	hashes := sidecar.BlobHashes()
+
+	chainID, err := b.client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", chainerr.Wrap(err))
+	}
+	var nonce uint64
+	if req.Nonce != nil {
+		nonce = *req.Nonce
+	} else if nonce, err = b.client.PendingNonceAt(ctx, req.From); err != nil {
+		return nil, fmt.Errorf("获取 nonce 失败: %w", chainerr.Wrap(err))
+	}
+	value := req.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+	tip, feeCap, err := b.fees(ctx, req.GasTipCap, req.GasFeeCap)
+	if err != nil {
+		return nil, err
+	}
+	blobFeeCap := req.BlobFeeCap
+	if blobFeeCap == nil {
+		fee, err := b.BlobFee(ctx)
+		if err != nil {
+			return nil, err
+		}
+		blobFeeCap = new(big.Int).Mul(fee, big.NewInt(b.multiplier()))
+	}
+	gas := req.Gas
+	if gas == 0 {
+		msg := ethereum.CallMsg{From: req.From, To: &req.To, Value: value, Data: req.Data, BlobHashes: hashes, BlobGasFeeCap: blobFeeCap}
+		if gas, err = b.client.EstimateGas(ctx, msg); err != nil {
+			return nil, fmt.Errorf("估算 gas 失败: %w", chainerr.Wrap(err))
+		}
+	}
+	return &types.BlobTx{
+		ChainID:    uint256.MustFromBig(chainID),
+		Nonce:      nonce,
+		GasTipCap:  uint256.MustFromBig(tip),
+		GasFeeCap:  uint256.MustFromBig(feeCap),
+		Gas:        gas,
+		To:         req.To,
+		Value:      uint256.MustFromBig(value),
+		Data:       req.Data,
+		BlobFeeCap: uint256.MustFromBig(blobFeeCap),
+		BlobHashes: hashes,
+		Sidecar:    sidecar,
+	}, nil
+}
@@ -0,0 +1,158 @@
+package txbuild
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/consensus/misc/eip4844"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto/kzg4844"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// 测试负载与 blob 之间的编码和解码
+func TestBlobEncoding(t *testing.T) {
+	for _, tc := range []struct {
+		size  int
+		blobs int
+	}{
+		{0, 1}, {1, 1}, {31, 1}, {BlobCapacity - 4, 1}, {BlobCapacity - 3, 2}, {MaxPayload, MaxBlobs},
+	} {
+		payload := make([]byte, tc.size)
+		for i := range payload {
+			payload[i] = byte(i*7 + 1)
+		}
+		blobs, err := EncodeBlobs(payload)
+		if err != nil || len(blobs) != tc.blobs {
+			t.Fatalf("❌ %d 字节应编码为 %d 个 blob: %d %v", tc.size, tc.blobs, len(blobs), err)
+		}
+		got, err := DecodeBlobs(blobs)
+		if err != nil || !bytes.Equal(got, payload) {
+			t.Fatalf("❌ %d 字节解码结果不符: %v", tc.size, err)
+		}
+	}
+	if _, err := EncodeBlobs(make([]byte, MaxPayload+1)); !errors.Is(err, ErrPayloadTooLarge) {
+		t.Fatalf("❌ 超出容量应报错: %v", err)
+	}
+
+	blobs, _ := EncodeBlobs([]byte("hello"))
+	for name, corrupt := range map[string]func([]kzg4844.Blob) []kzg4844.Blob{
+		"域元素最高字节":  func(b []kzg4844.Blob) []kzg4844.Blob { b[0][32] = 1; return b },
+		"填充":       func(b []kzg4844.Blob) []kzg4844.Blob { b[0][100] = 1; return b },
+		"长度前缀":     func(b []kzg4844.Blob) []kzg4844.Blob { b[0][1] = 0xff; return b },
+		"多余的 blob": func(b []kzg4844.Blob) []kzg4844.Blob { return append(b, kzg4844.Blob{}) },
+	} {
+		if _, err := DecodeBlobs(corrupt(append([]kzg4844.Blob(nil), blobs...))); !errors.Is(err, ErrInvalidBlob) {
+			t.Fatalf("❌ 篡改%s后应报错: %v", name, err)
+		}
+	}
+	t.Logf("✅ 单个 blob 容纳 %d 字节，单笔交易最多 %d 字节", BlobCapacity, MaxPayload)
+}
+
+// 测试 blob 价格公式与 go-ethereum 的实现一致
+func TestBlobFee(t *testing.T) {
+	config := params.MainnetChainConfig
+	fraction := params.DefaultPragueBlobConfig.UpdateFraction
+	for _, excess := range []uint64{0, 1 << 17, 10_000_000, 50_000_000, 100_000_000} {
+		header := &types.Header{Number: big.NewInt(1), Time: *config.PragueTime, ExcessBlobGas: &excess}
+		if got, want := BlobBaseFee(excess, fraction), eip4844.CalcBlobFee(config, header); got.Cmp(want) != 0 {
+			t.Fatalf("❌ excessBlobGas=%d 时价格为 %v，期望 %v", excess, got, want)
+		}
+	}
+	if fee := BlobBaseFee(0, fraction); fee.Cmp(common.Big1) != 0 {
+		t.Fatalf("❌ 没有超额时应为最低价 1 wei: %v", fee)
+	}
+
+	// blob 使用量高于目标时下一个区块涨价
+	excess, used := uint64(0), uint64(params.DefaultPragueBlobConfig.Max)*params.BlobTxBlobGasPerBlob
+	parent := &types.Header{Number: big.NewInt(22_000_000), Time: *config.PragueTime, ExcessBlobGas: &excess, BlobGasUsed: &used, BaseFee: big.NewInt(1)}
+	var last *big.Int
+	for i := 0; i < 50; i++ {
+		next, err := NextBlobBaseFee(config, parent, parent.Time+12)
+		if err != nil {
+			t.Fatalf("❌ %v", err)
+		}
+		last = next
+		e := eip4844.CalcExcessBlobGas(config, parent, parent.Time+12)
+		parent = &types.Header{Number: new(big.Int).Add(parent.Number, common.Big1), Time: parent.Time + 12, ExcessBlobGas: &e, BlobGasUsed: &used, BaseFee: big.NewInt(1)}
+	}
+	if last.Cmp(common.Big1) <= 0 {
+		t.Fatalf("❌ 持续满载后价格应上涨: %v", last)
+	}
+	if _, err := NextBlobBaseFee(config, &types.Header{Number: big.NewInt(1)}, 12); err == nil {
+		t.Fatal("❌ 未启用 EIP-4844 的区块应报错")
+	}
+	t.Logf("✅ 连续 50 个满载区块后 blob 价格为 %v wei", last)
+}
+
+// 测试构造、发送 blob 交易并从 sidecar 还原负载
+func TestBlobTx(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t)
+	chain.AutoMine(10 * time.Millisecond)
+	b := New(chain.RPC())
+	b.ChainConfig = params.AllDevChainProtocolChanges
+
+	local, err := b.BlobFee(ctx)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	b.ChainConfig = nil
+	remote, err := b.BlobFee(ctx)
+	if err != nil || local.Cmp(remote) != 0 {
+		t.Fatalf("❌ 本地计算的 blob 价格 %v 与 eth_blobBaseFee %v 不一致: %v", local, remote, err)
+	}
+
+	payload := bytes.Repeat([]byte("iws blob "), BlobCapacity/9+10)
+	to := chain.Accounts[1].Address
+	tx, err := b.BuildBlob(ctx, BlobRequest{From: chain.Accounts[0].Address, To: to, Payload: payload})
+	if err != nil {
+		t.Fatalf("❌ 构造 blob 交易失败: %v", err)
+	}
+	if len(tx.BlobHashes) != 2 || tx.Sidecar.Version != types.BlobSidecarVersion1 || tx.Gas != params.TxGas {
+		t.Fatalf("❌ blob 交易不符: %d 个 blob, 版本 %d, gas %d", len(tx.BlobHashes), tx.Sidecar.Version, tx.Gas)
+	}
+	if err := VerifySidecar(tx.Sidecar, tx.BlobHashes); err != nil {
+		t.Fatalf("❌ sidecar 校验失败: %v", err)
+	}
+	got, err := DecodeBlobs(tx.Sidecar.Blobs)
+	if err != nil || !bytes.Equal(got, payload) {
+		t.Fatalf("❌ 从 sidecar 还原负载失败: %v", err)
+	}
+
+	signed, err := b.Send(ctx, chain.Accounts[0].Key, tx)
+	if err != nil {
+		t.Fatalf("❌ 发送 blob 交易失败: %v", err)
+	}
+	receipt := chain.WaitMined(signed)
+	if receipt.Type != types.BlobTxType || receipt.BlobGasUsed != 2*params.BlobTxBlobGasPerBlob || receipt.BlobGasPrice == nil {
+		t.Fatalf("❌ 收据不符: 类型 %d, blob gas %d", receipt.Type, receipt.BlobGasUsed)
+	}
+	mined, _, err := chain.Client.TransactionByHash(ctx, signed.Hash())
+	if err != nil || len(mined.BlobHashes()) != 2 || mined.BlobHashes()[0] != tx.BlobHashes[0] {
+		t.Fatalf("❌ 链上交易的 blob 哈希不符: %v", err)
+	}
+
+	// Osaka 之前的证明格式，以及篡改后的 sidecar
+	legacy, err := NewSidecar([]kzg4844.Blob{tx.Sidecar.Blobs[0]}, types.BlobSidecarVersion0)
+	if err != nil || len(legacy.Proofs) != 1 {
+		t.Fatalf("❌ 计算单个证明失败: %v", err)
+	}
+	if err := VerifySidecar(legacy, tx.BlobHashes[:1]); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	legacy.Blobs[0][1] ^= 1
+	if err := VerifySidecar(legacy, nil); err == nil {
+		t.Fatal("❌ 篡改 blob 后证明应无效")
+	}
+	if err := VerifySidecar(tx.Sidecar, tx.BlobHashes[1:]); err == nil {
+		t.Fatal("❌ blob 哈希不符时应报错")
+	}
+	t.Logf("✅ %d 字节负载写入 %d 个 blob，blob 价格 %v wei", len(payload), len(tx.BlobHashes), receipt.BlobGasPrice)
+}
@@ -0,0 +1,179 @@
+// Package txbuild 构造待签名的交易：补全 nonce、gas 和费用，可选通过 eth_createAccessList
+// 生成 EIP-2930 访问列表，在能节省 gas 时附加到 AccessListTx 或 DynamicFeeTx；
+// 也可以把任意负载编码为 blob，构造带 KZG 承诺和证明的 EIP-4844 blob 交易
+package txbuild
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Builder 构造交易
+type Builder struct {
+	rpc    *rpc.Client
+	client *ethclient.Client
+
+	// FeeMultiplier 是 maxFeePerGas 相对当前 baseFee（以及 maxFeePerBlobGas 相对 blob 价格）的倍数，默认 2（与 abigen 一致）
+	FeeMultiplier int64
+	// ChainConfig 不为 nil 时按其 blob 参数在本地计算 blob 价格，否则调用 eth_blobBaseFee
+	ChainConfig *params.ChainConfig
+}
+
+// New 创建交易构造器。访问列表需要直接调用 eth_createAccessList，因此接收 *rpc.Client，
+// 已有 *ethclient.Client 时可传入 client.Client()
+func New(client *rpc.Client) *Builder {
+	return &Builder{rpc: client, client: ethclient.NewClient(client), FeeMultiplier: 2}
+}
+
+// Request 是待构造的交易
+type Request struct {
+	From  common.Address
+	To    *common.Address // nil 表示创建合约
+	Value *big.Int
+	Data  []byte
+
+	Nonce     *uint64  // 为 nil 时使用 pending nonce
+	Gas       uint64   // 为 0 时估算
+	GasPrice  *big.Int // AccessListTx 的 gasPrice，为 nil 时使用节点建议值
+	GasTipCap *big.Int // DynamicFeeTx 的 maxPriorityFeePerGas，为 nil 时使用节点建议值
+	GasFeeCap *big.Int // DynamicFeeTx 的 maxFeePerGas，为 nil 时为 tip + baseFee*FeeMultiplier
+
+	// Type 是交易类型：types.DynamicFeeTxType（默认）或 types.AccessListTxType（EIP-2930）
+	Type uint8
+	// AccessList 为 true 时生成访问列表，使用后 gas 更低才附加
+	AccessList bool
+}
+
+// Result 是构造结果
+type Result struct {
+	Tx     types.TxData
+	Report *Report // 只有请求了访问列表时才有
+}
+
+func (r Request) msg() ethereum.CallMsg {
+	return ethereum.CallMsg{From: r.From, To: r.To, Value: r.Value, Data: r.Data}
+}
+
+// Build 构造未签名的交易
+func (b *Builder) Build(ctx context.Context, req Request) (*Result, error) {
+	chainID, err := b.client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", chainerr.Wrap(err))
+	}
+	var nonce uint64
+	if req.Nonce != nil {
+		nonce = *req.Nonce
+	} else if nonce, err = b.client.PendingNonceAt(ctx, req.From); err != nil {
+		return nil, fmt.Errorf("获取 nonce 失败: %w", chainerr.Wrap(err))
+	}
+	value := req.Value
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	result := new(Result)
+	var list types.AccessList
+	gas := req.Gas
+	if req.AccessList {
+		report, err := b.CreateAccessList(ctx, req.msg())
+		if err != nil {
+			return nil, err
+		}
+		result.Report = report
+		if report.Saves() {
+			list = report.AccessList
+		}
+		if gas == 0 {
+			gas = report.Gas()
+		}
+	}
+	if gas == 0 {
+		if gas, err = b.client.EstimateGas(ctx, req.msg()); err != nil {
+			return nil, fmt.Errorf("估算 gas 失败: %w", chainerr.Wrap(err))
+		}
+	}
+
+	switch req.Type {
+	case types.AccessListTxType:
+		gasPrice := req.GasPrice
+		if gasPrice == nil {
+			if gasPrice, err = b.client.SuggestGasPrice(ctx); err != nil {
+				return nil, fmt.Errorf("获取 gas 价格失败: %w", chainerr.Wrap(err))
+			}
+		}
+		result.Tx = &types.AccessListTx{
+			ChainID: chainID, Nonce: nonce, GasPrice: gasPrice, Gas: gas,
+			To: req.To, Value: value, Data: req.Data, AccessList: list,
+		}
+	case types.DynamicFeeTxType, types.LegacyTxType:
+		tip, feeCap, err := b.fees(ctx, req.GasTipCap, req.GasFeeCap)
+		if err != nil {
+			return nil, err
+		}
+		result.Tx = &types.DynamicFeeTx{
+			ChainID: chainID, Nonce: nonce, GasTipCap: tip, GasFeeCap: feeCap, Gas: gas,
+			To: req.To, Value: value, Data: req.Data, AccessList: list,
+		}
+	default:
+		return nil, fmt.Errorf("不支持的交易类型 %d", req.Type)
+	}
+	return result, nil
+}
+
+// fees 补全 DynamicFeeTx 的 tip 和 feeCap
+func (b *Builder) fees(ctx context.Context, tip, feeCap *big.Int) (*big.Int, *big.Int, error) {
+	var err error
+	if tip == nil {
+		if tip, err = b.client.SuggestGasTipCap(ctx); err != nil {
+			return nil, nil, fmt.Errorf("获取小费建议失败: %w", chainerr.Wrap(err))
+		}
+	}
+	if feeCap == nil {
+		head, err := b.client.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("获取最新区块失败: %w", chainerr.Wrap(err))
+		}
+		if head.BaseFee == nil {
+			return nil, nil, errors.New("链尚未启用 EIP-1559，请使用 AccessListTx")
+		}
+		feeCap = new(big.Int).Add(tip, new(big.Int).Mul(head.BaseFee, big.NewInt(b.multiplier())))
+	}
+	if feeCap.Cmp(tip) < 0 {
+		return nil, nil, fmt.Errorf("maxFeePerGas (%v) 小于 maxPriorityFeePerGas (%v)", feeCap, tip)
+	}
+	return tip, feeCap, nil
+}
+
+func (b *Builder) multiplier() int64 {
+	if b.FeeMultiplier <= 0 {
+		return 2
+	}
+	return b.FeeMultiplier
+}
+
+// Send 用私钥签名并发送交易
+func (b *Builder) Send(ctx context.Context, key *ecdsa.PrivateKey, data types.TxData) (*types.Transaction, error) {
+	chainID, err := b.client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取链 ID 失败: %w", chainerr.Wrap(err))
+	}
+	tx, err := types.SignNewTx(key, types.LatestSignerForChainID(chainID), data)
+	if err != nil {
+		return nil, fmt.Errorf("签名交易失败: %w", err)
+	}
+	if err := b.client.SendTransaction(ctx, tx); err != nil {
+		return nil, fmt.Errorf("发送交易失败: %w", chainerr.Wrap(err))
+	}
+	return tx, nil
+}
@@ -0,0 +1,131 @@
+package txbuild
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/multicall"
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 测试访问列表：跨合约读取存储时能节省 gas 并附加，只访问 to 自身存储时不附加
+func TestAccessList(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t, simchain.WithAlloc(multicall.Address, types.Account{Code: multicall.RuntimeCode, Balance: big.NewInt(0)}))
+	storeAddr, instance := chain.DeployStore("v1.0.0")
+	b := New(chain.RPC())
+	from := chain.Accounts[0].Address
+
+	// 通过 Multicall3 读取 Store 的 items 和 version：Store 地址和两个存储槽都是冷访问
+	storeABI, _ := store.StoreMetaData.GetAbi()
+	items, _ := storeABI.Pack("items", [32]byte{1})
+	version, _ := storeABI.Pack("version")
+	type call3 struct {
+		Target       common.Address
+		AllowFailure bool
+		CallData     []byte
+	}
+	data, err := multicall.ABI.Pack("aggregate3", []call3{{storeAddr, false, items}, {storeAddr, false, version}})
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+
+	res, err := b.Build(ctx, Request{From: from, To: &multicall.Address, Data: data, AccessList: true})
+	if err != nil {
+		t.Fatalf("❌ 构造交易失败: %v", err)
+	}
+	report := res.Report
+	if !report.Saves() || len(report.AccessList) != 1 || report.AccessList[0].Address != storeAddr || len(report.AccessList[0].StorageKeys) < 2 {
+		t.Fatalf("❌ 访问列表不符:\n%s", report)
+	}
+	tx, ok := res.Tx.(*types.DynamicFeeTx)
+	if !ok || len(tx.AccessList) != 1 || tx.Gas != report.GasWith {
+		t.Fatalf("❌ 应构造带访问列表的 DynamicFeeTx: %+v", res.Tx)
+	}
+	withList := send(t, chain, b, res.Tx)
+
+	// 不带访问列表发送同样的交易，比较实际消耗
+	plain, err := b.Build(ctx, Request{From: from, To: &multicall.Address, Data: data})
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	withoutList := send(t, chain, b, plain.Tx)
+	// 估算值是能成功执行的最小 gas 上限，与实际消耗可能有几个 gas 的差别
+	if withList.GasUsed >= withoutList.GasUsed {
+		t.Fatalf("❌ 附加访问列表后实际消耗应更低: %d -> %d, 报告节省 %d", withoutList.GasUsed, withList.GasUsed, report.Savings())
+	}
+
+	// EIP-2930 交易
+	res, err = b.Build(ctx, Request{From: from, To: &multicall.Address, Data: data, AccessList: true, Type: types.AccessListTxType})
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if alt, ok := res.Tx.(*types.AccessListTx); !ok || len(alt.AccessList) != 1 {
+		t.Fatalf("❌ 应构造带访问列表的 AccessListTx: %+v", res.Tx)
+	}
+	if r := send(t, chain, b, res.Tx); r.Type != types.AccessListTxType {
+		t.Fatalf("❌ 交易类型不符: %d", r.Type)
+	}
+
+	// 只写 to 自身的存储：to 本来就是 warm 的，附加列表反而更贵
+	setItem, _ := storeABI.Pack("setItem", [32]byte{2}, [32]byte{3})
+	res, err = b.Build(ctx, Request{From: from, To: &storeAddr, Data: setItem, AccessList: true})
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if res.Report.Saves() || len(res.Tx.(*types.DynamicFeeTx).AccessList) != 0 || res.Tx.(*types.DynamicFeeTx).Gas != res.Report.GasWithout {
+		t.Fatalf("❌ 不省 gas 时不应附加:\n%s", res.Report)
+	}
+	if _, ok := Apply(res.Tx, res.Report); ok {
+		t.Fatal("❌ 不省 gas 时 Apply 不应附加")
+	}
+
+	// abigen 绑定：Attach 不省 gas 时不修改 opts
+	auth := chain.Auth(0)
+	if r, err := b.Attach(ctx, auth, &storeAddr, setItem); err != nil || r.Saves() || auth.AccessList != nil {
+		t.Fatalf("❌ Attach 结果不符: %v %v", r, err)
+	}
+	setTx, err := instance.SetItem(auth, [32]byte{2}, [32]byte{4})
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	chain.WaitMined(setTx)
+	t.Logf("✅ 实际节省 %d gas\n%s", withoutList.GasUsed-withList.GasUsed, report)
+}
+
+// 测试把访问列表附加到已有交易
+func TestApply(t *testing.T) {
+	report := &Report{
+		AccessList: types.AccessList{{Address: common.HexToAddress("0x01"), StorageKeys: []common.Hash{{1}}}},
+		GasWithout: 30000, GasWith: 29800,
+	}
+	dyn := &types.DynamicFeeTx{Gas: 21000}
+	out, ok := Apply(dyn, report)
+	if !ok || out.(*types.DynamicFeeTx).Gas != 29800 || len(out.(*types.DynamicFeeTx).AccessList) != 1 || dyn.AccessList != nil {
+		t.Fatalf("❌ DynamicFeeTx 附加结果不符: %+v", out)
+	}
+	if out, ok := Apply(&types.AccessListTx{Gas: 50000}, report); !ok || out.(*types.AccessListTx).Gas != 50000 {
+		t.Fatalf("❌ AccessListTx 附加结果不符: %+v", out)
+	}
+	if _, ok := Apply(&types.LegacyTx{}, report); ok {
+		t.Fatal("❌ LegacyTx 不能附加访问列表")
+	}
+	report.ExecError = "execution reverted"
+	if _, ok := Apply(dyn, report); ok {
+		t.Fatal("❌ 模拟执行失败时不应附加")
+	}
+	t.Log("✅ 附加规则正确")
+}
+
+func send(t *testing.T, chain *simchain.Chain, b *Builder, data types.TxData) *types.Receipt {
+	t.Helper()
+	tx, err := b.Send(context.Background(), chain.Accounts[0].Key, data)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	return chain.WaitMined(tx)
+}
@@ -0,0 +1,111 @@
+// Package txutil 提供发送交易前后的常用步骤：核对链 ID、等待收据，
+// 以及在交易失败时重放调用取回 revert 原因。错误统一使用 chainerr 的类型
+package txutil
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// DefaultPollInterval 是查询收据的默认间隔
+const DefaultPollInterval = time.Second
+
+// EnsureChain 确认节点的链 ID 与预期一致，不一致时返回 *chainerr.ChainMismatchError
+func EnsureChain(ctx context.Context, client ethereum.ChainIDReader, want *big.Int) error {
+	got, err := client.ChainID(ctx)
+	if err != nil {
+		return fmt.Errorf("获取链 ID 失败: %w", chainerr.Wrap(err))
+	}
+	return chainerr.CheckChainID(want, got)
+}
+
+// WaitForReceipt 每隔 interval 查询一次收据，直到交易上链或 ctx 结束。
+// 收据不存在时继续等待，限流/超时等可重试错误也继续等待，其他错误立即返回；
+// ctx 超时返回 chainerr.ErrTimeout
+func WaitForReceipt(ctx context.Context, client ethereum.TransactionReader, hash common.Hash, interval time.Duration) (*types.Receipt, error) {
+	if interval <= 0 {
+		interval = DefaultPollInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		receipt, err := client.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return receipt, nil
+		}
+		if ctx.Err() != nil {
+			return nil, waitErr(ctx, hash)
+		}
+		if class := chainerr.Classify(err); class != chainerr.NotFound && !class.Retryable() {
+			return nil, fmt.Errorf("查询交易收据失败: %w", chainerr.Wrap(err))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil, waitErr(ctx, hash)
+		}
+	}
+}
+
+func waitErr(ctx context.Context, hash common.Hash) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return &chainerr.Error{Class: chainerr.Timeout, Err: fmt.Errorf("等待交易 %s 确认超时", hash.Hex())}
+	}
+	return ctx.Err()
+}
+
+// Backend 是 WaitSuccess 需要的节点接口，*ethclient.Client 满足
+type Backend interface {
+	ethereum.TransactionReader
+	ethereum.ContractCaller
+}
+
+// WaitSuccess 等待交易上链并检查状态。交易失败时在所在区块重放调用，
+// 返回带 revert 数据和原因的 *chainerr.RevertError（同时返回收据）
+func WaitSuccess(ctx context.Context, client Backend, tx *types.Transaction, interval time.Duration) (*types.Receipt, error) {
+	receipt, err := WaitForReceipt(ctx, client, tx.Hash(), interval)
+	if err != nil {
+		return nil, err
+	}
+	if receipt.Status == types.ReceiptStatusSuccessful {
+		return receipt, nil
+	}
+	return receipt, Replay(ctx, client, tx, receipt.BlockNumber)
+}
+
+// Replay 在指定区块上以 eth_call 重放交易，返回 revert 错误；重放成功（例如状态已变化）时
+// 仍返回不带数据的 *chainerr.RevertError，表示交易在链上失败
+func Replay(ctx context.Context, client ethereum.ContractCaller, tx *types.Transaction, block *big.Int) error {
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return fmt.Errorf("恢复交易发送方失败: %w", chainerr.Wrap(err))
+	}
+	msg := ethereum.CallMsg{
+		From:       from,
+		To:         tx.To(),
+		Gas:        tx.Gas(),
+		Value:      tx.Value(),
+		Data:       tx.Data(),
+		AccessList: tx.AccessList(),
+	}
+	// 在交易所在区块的父状态上执行；区块内排在前面的交易不会被重放，原因可能与链上略有差异
+	parent := block
+	if block != nil && block.Sign() > 0 {
+		parent = new(big.Int).Sub(block, big.NewInt(1))
+	}
+	if _, err := client.CallContract(ctx, msg, parent); err != nil {
+		if wrapped := chainerr.Wrap(err); errors.Is(wrapped, chainerr.ErrReverted) {
+			return wrapped
+		}
+		return chainerr.NewRevertError(nil, err)
+	}
+	return chainerr.NewRevertError(nil, fmt.Errorf("交易 %s 执行失败", tx.Hash().Hex()))
+}
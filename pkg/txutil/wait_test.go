@@ -0,0 +1,102 @@
+package txutil
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// 测试等待收据、失败交易重放取回 revert 原因、估算 gas 时的 revert、超时和链 ID 核对
+func TestWait(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t)
+	chain.AutoMine(10 * time.Millisecond)
+	_, token := chain.DeployToken("IWS Token", "IWS", big.NewInt(1000))
+
+	// 成功的转账
+	tx, err := token.Transfer(chain.Auth(0), chain.Accounts[1].Address, big.NewInt(1))
+	if err != nil {
+		t.Fatalf("❌ 转账失败: %v", err)
+	}
+	receipt, err := WaitSuccess(ctx, chain.Client, tx, 5*time.Millisecond)
+	if err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+		t.Fatalf("❌ 等待交易失败: %v", err)
+	}
+
+	// 估算 gas 时 revert：bind 返回的错误可以归类并解码原因
+	_, err = token.Transfer(chain.Auth(1), chain.Accounts[2].Address, big.NewInt(1_000_000))
+	var revert *chainerr.RevertError
+	if !errors.As(chainerr.Wrap(err), &revert) || revert.Reason != "ERC20: transfer amount exceeds balance" {
+		t.Fatalf("❌ 估算 gas 时的 revert 应解码原因: %v", err)
+	}
+
+	// 跳过估算直接上链的失败交易：等待后重放得到 revert 原因
+	auth := chain.Auth(1)
+	auth.GasLimit = 100_000
+	tx, err = token.Transfer(auth, chain.Accounts[2].Address, big.NewInt(1_000_000))
+	if err != nil {
+		t.Fatalf("❌ 发送交易失败: %v", err)
+	}
+	receipt, err = WaitSuccess(ctx, chain.Client, tx, 5*time.Millisecond)
+	if receipt == nil || receipt.Status != types.ReceiptStatusFailed {
+		t.Fatalf("❌ 应返回失败交易的收据: %v", err)
+	}
+	if !errors.As(err, &revert) || revert.Reason != "ERC20: transfer amount exceeds balance" {
+		t.Fatalf("❌ 重放应取回 revert 原因: %v", err)
+	}
+
+	// 永远不会上链的交易：超时
+	tctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := WaitForReceipt(tctx, chain.Client, common.HexToHash("0x01"), 5*time.Millisecond); !errors.Is(err, chainerr.ErrTimeout) {
+		t.Fatalf("❌ 应返回超时错误: %v", err)
+	}
+
+	// 链 ID 核对
+	if err := EnsureChain(ctx, chain.Client, chain.ChainID); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if err := EnsureChain(ctx, chain.Client, big.NewInt(97)); !errors.Is(err, chainerr.ErrChainMismatch) {
+		t.Fatalf("❌ 应返回链 ID 不匹配: %v", err)
+	}
+	t.Logf("✅ %v", revert)
+}
+
+// unsupportedReader 模拟不支持 eth_getTransactionReceipt 的节点
+type unsupportedReader struct {
+	ethereum.TransactionReader
+	calls int
+}
+
+type methodNotFoundError struct{}
+
+func (methodNotFoundError) Error() string  { return "Method not found" }
+func (methodNotFoundError) ErrorCode() int { return -32601 }
+
+func (r *unsupportedReader) TransactionReceipt(context.Context, common.Hash) (*types.Receipt, error) {
+	r.calls++
+	return nil, methodNotFoundError{}
+}
+
+// 测试节点不支持查询收据时立即返回，而不是当作“尚未上链”一直轮询到超时
+func TestWaitMethodNotFound(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	reader := &unsupportedReader{}
+	_, err := WaitForReceipt(ctx, reader, common.HexToHash("0x01"), 5*time.Millisecond)
+	if err == nil || ctx.Err() != nil || reader.calls != 1 {
+		t.Fatalf("❌ method not found 应立即返回: 查询 %d 次, %v", reader.calls, err)
+	}
+	if chainerr.Is(err, chainerr.NotFound) || chainerr.Is(err, chainerr.Timeout) {
+		t.Fatalf("❌ method not found 不应归类为 not found/超时: %v", err)
+	}
+	t.Logf("✅ %v", err)
+}
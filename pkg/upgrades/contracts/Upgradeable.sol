@@ -0,0 +1,145 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.26;
+
+// 可升级合约的最小实现，接口和存储槽与 OpenZeppelin 一致（EIP-1967）
+
+library ERC1967 {
+    // bytes32(uint256(keccak256("eip1967.proxy.implementation")) - 1)
+    bytes32 internal constant IMPLEMENTATION_SLOT = 0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc;
+    // bytes32(uint256(keccak256("eip1967.proxy.admin")) - 1)
+    bytes32 internal constant ADMIN_SLOT = 0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103;
+
+    event Upgraded(address indexed implementation);
+    event AdminChanged(address previousAdmin, address newAdmin);
+
+    function implementation() internal view returns (address impl) {
+        assembly {
+            impl := sload(IMPLEMENTATION_SLOT)
+        }
+    }
+
+    function upgradeToAndCall(address newImplementation, bytes memory data) internal {
+        require(newImplementation.code.length > 0, "ERC1967: new implementation is not a contract");
+        assembly {
+            sstore(IMPLEMENTATION_SLOT, newImplementation)
+        }
+        emit Upgraded(newImplementation);
+        if (data.length > 0) {
+            (bool ok, bytes memory ret) = newImplementation.delegatecall(data);
+            if (!ok) {
+                assembly {
+                    revert(add(ret, 32), mload(ret))
+                }
+            }
+        }
+    }
+
+    function admin() internal view returns (address a) {
+        assembly {
+            a := sload(ADMIN_SLOT)
+        }
+    }
+
+    function changeAdmin(address newAdmin) internal {
+        require(newAdmin != address(0), "ERC1967: new admin is the zero address");
+        emit AdminChanged(admin(), newAdmin);
+        assembly {
+            sstore(ADMIN_SLOT, newAdmin)
+        }
+    }
+}
+
+// ERC1967Proxy 把所有调用转发给实现合约，构造时可执行初始化调用；用于 UUPS 实现合约
+contract ERC1967Proxy {
+    constructor(address implementation, bytes memory data) payable {
+        ERC1967.upgradeToAndCall(implementation, data);
+    }
+
+    fallback() external payable virtual {
+        _delegate(ERC1967.implementation());
+    }
+
+    receive() external payable virtual {
+        _delegate(ERC1967.implementation());
+    }
+
+    function _delegate(address implementation) internal {
+        assembly {
+            calldatacopy(0, 0, calldatasize())
+            let result := delegatecall(gas(), implementation, 0, calldatasize(), 0, 0)
+            returndatacopy(0, 0, returndatasize())
+            switch result
+            case 0 {
+                revert(0, returndatasize())
+            }
+            default {
+                return(0, returndatasize())
+            }
+        }
+    }
+}
+
+// TransparentUpgradeableProxy 只允许 admin 调用 upgradeToAndCall 和 changeAdmin，其他账户的调用全部转发，
+// admin 不能调用实现合约（避免函数选择器冲突）
+contract TransparentUpgradeableProxy is ERC1967Proxy {
+    constructor(address implementation, address admin_, bytes memory data) payable ERC1967Proxy(implementation, data) {
+        ERC1967.changeAdmin(admin_);
+    }
+
+    fallback() external payable override {
+        if (msg.sender != ERC1967.admin()) {
+            _delegate(ERC1967.implementation());
+        }
+        if (msg.sig == bytes4(keccak256("upgradeToAndCall(address,bytes)"))) {
+            (address newImplementation, bytes memory data) = abi.decode(msg.data[4:], (address, bytes));
+            ERC1967.upgradeToAndCall(newImplementation, data);
+        } else if (msg.sig == bytes4(keccak256("changeAdmin(address)"))) {
+            ERC1967.changeAdmin(abi.decode(msg.data[4:], (address)));
+        } else {
+            revert("TransparentUpgradeableProxy: admin cannot fallback to proxy target");
+        }
+    }
+
+    receive() external payable override {
+        require(msg.sender != ERC1967.admin(), "TransparentUpgradeableProxy: admin cannot fallback to proxy target");
+        _delegate(ERC1967.implementation());
+    }
+}
+
+// Initializable 代替构造函数：代理合约不会执行实现合约的构造函数
+abstract contract Initializable {
+    bool private _initialized;
+
+    modifier initializer() {
+        require(!_initialized, "Initializable: contract is already initialized");
+        _initialized = true;
+        _;
+    }
+
+    // 在实现合约的构造函数中调用，防止有人直接初始化实现合约
+    function _disableInitializers() internal {
+        _initialized = true;
+    }
+}
+
+// UUPSUpgradeable 由实现合约自身提供升级函数，_authorizeUpgrade 决定谁能升级
+abstract contract UUPSUpgradeable {
+    address private immutable self = address(this);
+
+    function proxiableUUID() external view returns (bytes32) {
+        require(address(this) == self, "UUPSUpgradeable: must not be called through delegatecall");
+        return ERC1967.IMPLEMENTATION_SLOT;
+    }
+
+    function upgradeToAndCall(address newImplementation, bytes memory data) external payable {
+        require(address(this) != self, "UUPSUpgradeable: must be called through delegatecall");
+        _authorizeUpgrade(newImplementation);
+        require(
+            UUPSUpgradeable(newImplementation).proxiableUUID() == ERC1967.IMPLEMENTATION_SLOT,
+            "UUPSUpgradeable: new implementation is not UUPS"
+        );
+        ERC1967.upgradeToAndCall(newImplementation, data);
+    }
+
+    function _authorizeUpgrade(address newImplementation) internal virtual;
+}
@@ -0,0 +1,255 @@
+package upgrades
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrIncompatible 表示新版本实现合约的存储布局与旧版本不兼容
+var ErrIncompatible = errors.New("存储布局不兼容")
+
+// StorageLayout 是 solc 的 storageLayout 输出
+type StorageLayout struct {
+	Storage []StorageItem           `json:"storage"`
+	Types   map[string]*StorageType `json:"types"`
+}
+
+// StorageItem 是一个状态变量（或结构体成员）的位置
+type StorageItem struct {
+	Contract string `json:"contract,omitempty"`
+	Label    string `json:"label"`
+	Offset   int    `json:"offset"`
+	Slot     string `json:"slot"` // 十进制字符串
+	Type     string `json:"type"`
+}
+
+// StorageType 是 storageLayout 中的类型描述
+type StorageType struct {
+	Encoding      string        `json:"encoding"` // inplace、mapping、dynamic_array、bytes
+	Label         string        `json:"label"`
+	NumberOfBytes string        `json:"numberOfBytes"`
+	Base          string        `json:"base,omitempty"`  // 数组元素类型
+	Key           string        `json:"key,omitempty"`   // mapping 键类型
+	Value         string        `json:"value,omitempty"` // mapping 值类型
+	Members       []StorageItem `json:"members,omitempty"`
+}
+
+// ParseLayout 解析 storageLayout JSON
+func ParseLayout(data []byte) (*StorageLayout, error) {
+	var layout StorageLayout
+	if err := json.Unmarshal(data, &layout); err != nil {
+		return nil, fmt.Errorf("解析存储布局失败: %w", err)
+	}
+	return &layout, nil
+}
+
+// Severity 是布局问题的严重程度
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // 升级后会读写错误的数据
+	SeverityWarning Severity = "warning" // 不影响数据，但可能是误改，例如变量重命名
+)
+
+// Problem 是一个布局问题
+type Problem struct {
+	Severity Severity `json:"severity"`
+	Variable string   `json:"variable"`
+	Message  string   `json:"message"`
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("[%s] %s: %s", p.Severity, p.Variable, p.Message)
+}
+
+// span 是变量占用的字节范围 [start, end)，按 slot*32+offset 计算
+type span struct {
+	item       StorageItem
+	start, end uint64
+	gap        bool
+}
+
+func (l *StorageLayout) spans() ([]span, error) {
+	out := make([]span, 0, len(l.Storage))
+	for _, item := range l.Storage {
+		slot, err := strconv.ParseUint(item.Slot, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("变量 %s 的 slot %q 无效: %w", item.Label, item.Slot, err)
+		}
+		t, ok := l.Types[item.Type]
+		if !ok {
+			return nil, fmt.Errorf("变量 %s 的类型 %s 不在 types 中", item.Label, item.Type)
+		}
+		size, err := strconv.ParseUint(t.NumberOfBytes, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("类型 %s 的大小 %q 无效: %w", item.Type, t.NumberOfBytes, err)
+		}
+		start := slot*32 + uint64(item.Offset)
+		out = append(out, span{item: item, start: start, end: start + size, gap: isGap(item.Label)})
+	}
+	return out, nil
+}
+
+// isGap 判断是否为 OpenZeppelin 风格的预留存储 __gap
+func isGap(label string) bool {
+	return strings.HasPrefix(label, "__gap")
+}
+
+// CheckLayout 检查从 old 升级到 new 是否安全：旧变量必须保持位置和类型不变，
+// 新变量只能追加在末尾或占用 __gap 预留的空间（同时缩小 __gap，保持其结束位置不变）
+func CheckLayout(old, new *StorageLayout) ([]Problem, error) {
+	oldSpans, err := old.spans()
+	if err != nil {
+		return nil, fmt.Errorf("旧版本: %w", err)
+	}
+	newSpans, err := new.spans()
+	if err != nil {
+		return nil, fmt.Errorf("新版本: %w", err)
+	}
+
+	var problems []Problem
+	add := func(sev Severity, variable, format string, args ...interface{}) {
+		problems = append(problems, Problem{Severity: sev, Variable: variable, Message: fmt.Sprintf(format, args...)})
+	}
+	matched := make(map[int]bool)
+
+	for _, o := range oldSpans {
+		if o.gap {
+			continue
+		}
+		idx := -1
+		for i, n := range newSpans {
+			if n.start == o.start && !n.gap {
+				idx = i
+				break
+			}
+		}
+		if idx < 0 {
+			moved := ""
+			for _, n := range newSpans {
+				if n.item.Label == o.item.Label {
+					moved = fmt.Sprintf("，新版本中位于 slot %s offset %d", n.item.Slot, n.item.Offset)
+				}
+			}
+			add(SeverityError, o.item.Label, "slot %s offset %d 处的变量被删除或移动%s", o.item.Slot, o.item.Offset, moved)
+			continue
+		}
+		n := newSpans[idx]
+		matched[idx] = true
+		if !sameType(old, o.item.Type, new, n.item.Type) {
+			add(SeverityError, o.item.Label, "类型从 %s 变为 %s", typeLabel(old, o.item.Type), typeLabel(new, n.item.Type))
+			continue
+		}
+		if n.item.Label != o.item.Label {
+			add(SeverityWarning, o.item.Label, "重命名为 %s", n.item.Label)
+		}
+	}
+
+	// 新增的变量不能覆盖旧变量，占用 __gap 时 __gap 的结束位置必须不变
+	for i, n := range newSpans {
+		if matched[i] {
+			continue
+		}
+		for _, o := range oldSpans {
+			if n.start >= o.end || o.start >= n.end {
+				continue
+			}
+			if !o.gap {
+				add(SeverityError, n.item.Label, "与旧变量 %s 占用相同的存储", o.item.Label)
+				break
+			}
+			if n.gap && n.end != o.end {
+				add(SeverityError, n.item.Label, "__gap 结束于第 %d 字节，旧版本为 %d（新增变量后 __gap 应缩小相同的大小）", n.end, o.end)
+				break
+			}
+			if !n.gap && n.end > o.end {
+				add(SeverityError, n.item.Label, "超出了 %s 预留的空间", o.item.Label)
+				break
+			}
+		}
+	}
+	return problems, nil
+}
+
+// Compatible 检查存储布局，有错误时返回包装 ErrIncompatible 的错误
+func Compatible(old, new *StorageLayout) error {
+	problems, err := CheckLayout(old, new)
+	if err != nil {
+		return err
+	}
+	var msgs []string
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			msgs = append(msgs, p.Variable+": "+p.Message)
+		}
+	}
+	if len(msgs) > 0 {
+		return fmt.Errorf("%w: %s", ErrIncompatible, strings.Join(msgs, "; "))
+	}
+	return nil
+}
+
+func typeLabel(l *StorageLayout, id string) string {
+	if t, ok := l.Types[id]; ok {
+		return t.Label
+	}
+	return id
+}
+
+// sameType 递归比较类型：标签、编码、大小一致，mapping 的键值、数组元素和结构体成员也一致。
+// 结构体按成员比较，不比较结构体名字
+func sameType(a *StorageLayout, aid string, b *StorageLayout, bid string) bool {
+	ta, okA := a.Types[aid]
+	tb, okB := b.Types[bid]
+	if !okA || !okB {
+		return aid == bid
+	}
+	if ta.Encoding != tb.Encoding || ta.NumberOfBytes != tb.NumberOfBytes {
+		return false
+	}
+	if len(ta.Members) > 0 || len(tb.Members) > 0 {
+		if len(ta.Members) != len(tb.Members) {
+			return false
+		}
+		for i := range ta.Members {
+			ma, mb := ta.Members[i], tb.Members[i]
+			if ma.Slot != mb.Slot || ma.Offset != mb.Offset || !sameType(a, ma.Type, b, mb.Type) {
+				return false
+			}
+		}
+		return true
+	}
+	if ta.Key != "" || tb.Key != "" {
+		return sameType(a, ta.Key, b, tb.Key) && sameType(a, ta.Value, b, tb.Value)
+	}
+	if ta.Base != "" || tb.Base != "" {
+		return sameType(a, ta.Base, b, tb.Base) && arrayLength(ta.Label) == arrayLength(tb.Label)
+	}
+	// 枚举和合约类型的标签带有所属合约名，换了合约名不影响存储
+	for _, prefix := range []string{"enum ", "contract "} {
+		if strings.HasPrefix(ta.Label, prefix) && strings.HasPrefix(tb.Label, prefix) {
+			return true
+		}
+	}
+	if isAddressLike(ta.Label) && isAddressLike(tb.Label) {
+		return true
+	}
+	return ta.Label == tb.Label
+}
+
+// isAddressLike 判断类型在存储中是否就是 20 字节地址
+func isAddressLike(label string) bool {
+	return label == "address" || label == "address payable" || strings.HasPrefix(label, "contract ")
+}
+
+// arrayLength 取数组类型标签中的长度，例如 uint256[48] 返回 "48"，动态数组返回空字符串
+func arrayLength(label string) string {
+	i := strings.LastIndex(label, "[")
+	if i < 0 {
+		return ""
+	}
+	return strings.TrimSuffix(label[i+1:], "]")
+}
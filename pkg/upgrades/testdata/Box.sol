@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.26;
+
+import "../contracts/Upgradeable.sol";
+
+// 升级测试用的实现合约：V2 使用 __gap 中的一个槽新增变量，V2Bad 调换了变量顺序
+contract BoxV1 is Initializable, UUPSUpgradeable {
+    address public owner;
+    uint256 public value;
+    uint256[48] private __gap;
+
+    constructor() {
+        _disableInitializers();
+    }
+
+    function initialize(address owner_, uint256 value_) external initializer {
+        owner = owner_;
+        value = value_;
+    }
+
+    function setValue(uint256 value_) external {
+        value = value_;
+    }
+
+    function version() external pure virtual returns (string memory) {
+        return "v1";
+    }
+
+    function _authorizeUpgrade(address) internal view override {
+        require(msg.sender == owner, "BoxV1: caller is not the owner");
+    }
+}
+
+contract BoxV2 is Initializable, UUPSUpgradeable {
+    address public owner;
+    uint256 public value;
+    uint256 public count;
+    uint256[47] private __gap;
+
+    constructor() {
+        _disableInitializers();
+    }
+
+    function increment() external {
+        count += 1;
+    }
+
+    function setCount(uint256 count_) external {
+        require(count_ != 0, "BoxV2: zero count");
+        count = count_;
+    }
+
+    function version() external pure returns (string memory) {
+        return "v2";
+    }
+
+    function _authorizeUpgrade(address) internal view override {
+        require(msg.sender == owner, "BoxV2: caller is not the owner");
+    }
+}
+
+contract BoxV2Bad is Initializable, UUPSUpgradeable {
+    uint256 public value;
+    address public owner;
+    uint256[48] private __gap;
+
+    function _authorizeUpgrade(address) internal view override {
+        require(msg.sender == owner, "BoxV2Bad: caller is not the owner");
+    }
+}
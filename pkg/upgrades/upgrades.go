@@ -0,0 +1,324 @@
+// Package upgrades 部署和升级可升级合约：部署实现合约和 ERC1967（UUPS）或透明代理并执行初始化调用，
+// 升级前按 solc 的 storageLayout 检查存储布局兼容性、核对当前实现合约，并先用 eth_call 模拟升级
+package upgrades
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/proxy"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/txutil"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/verify"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ==================== 编译时嵌入代理合约（contracts/Upgradeable.sol）====================
+
+var (
+	//go:embed contracts/Upgradeable_sol_ERC1967Proxy.abi
+	erc1967ProxyABI string
+	//go:embed contracts/Upgradeable_sol_ERC1967Proxy.bin
+	erc1967ProxyBin string
+	//go:embed contracts/Upgradeable_sol_TransparentUpgradeableProxy.abi
+	transparentProxyABI string
+	//go:embed contracts/Upgradeable_sol_TransparentUpgradeableProxy.bin
+	transparentProxyBin string
+)
+
+// upgradeABI 是代理（透明代理由 admin 调用）或 UUPS 实现合约提供的升级函数
+const upgradeABI = `[
+	{"type":"function","name":"upgradeToAndCall","stateMutability":"payable","inputs":[{"name":"newImplementation","type":"address"},{"name":"data","type":"bytes"}],"outputs":[]},
+	{"type":"function","name":"changeAdmin","stateMutability":"nonpayable","inputs":[{"name":"newAdmin","type":"address"}],"outputs":[]}
+]`
+
+var (
+	// ErrNotUpgradeable 表示地址不是可升级的 EIP-1967 代理
+	ErrNotUpgradeable = errors.New("不是可升级的 EIP-1967 代理")
+	// ErrWrongImplementation 表示代理当前的实现合约与给出的旧版本不一致
+	ErrWrongImplementation = errors.New("当前实现合约与旧版本不一致")
+)
+
+// Implementation 是实现合约的构建产物
+type Implementation struct {
+	Name     string
+	ABI      *abi.ABI
+	Bytecode []byte
+	Layout   *StorageLayout // solc 的 storageLayout 输出，为 nil 时不检查布局
+}
+
+// ParseSolcOutput 解析 solc --standard-json 的输出（outputSelection 需要 abi、evm.bytecode.object 和 storageLayout），
+// 返回按合约名索引的构建产物
+func ParseSolcOutput(data []byte) (map[string]*Implementation, error) {
+	var out struct {
+		Contracts map[string]map[string]struct {
+			ABI json.RawMessage `json:"abi"`
+			EVM struct {
+				Bytecode struct {
+					Object string `json:"object"`
+				} `json:"bytecode"`
+			} `json:"evm"`
+			StorageLayout *StorageLayout `json:"storageLayout"`
+		} `json:"contracts"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, fmt.Errorf("解析 solc 输出失败: %w", err)
+	}
+	impls := make(map[string]*Implementation)
+	for _, contracts := range out.Contracts {
+		for name, c := range contracts {
+			parsed, err := abi.JSON(strings.NewReader(string(c.ABI)))
+			if err != nil {
+				return nil, fmt.Errorf("解析 %s 的 ABI 失败: %w", name, err)
+			}
+			impls[name] = &Implementation{Name: name, ABI: &parsed, Bytecode: common.FromHex(c.EVM.Bytecode.Object), Layout: c.StorageLayout}
+		}
+	}
+	return impls, nil
+}
+
+// LoadSolcOutput 读取 solc --standard-json 的输出文件
+func LoadSolcOutput(path string) (map[string]*Implementation, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取 solc 输出失败: %w", err)
+	}
+	return ParseSolcOutput(data)
+}
+
+// Backend 是部署和升级需要的节点接口，*ethclient.Client 满足
+type Backend interface {
+	bind.ContractBackend
+	ethereum.TransactionReader
+	StorageAt(ctx context.Context, account common.Address, key common.Hash, blockNumber *big.Int) ([]byte, error)
+}
+
+// Manager 部署和升级代理合约
+type Manager struct {
+	backend Backend
+
+	PollInterval time.Duration // 查询收据的间隔，默认 txutil.DefaultPollInterval
+}
+
+// New 创建部署管理器
+func New(backend Backend) *Manager {
+	return &Manager{backend: backend}
+}
+
+// DeployOptions 是部署代理的选项
+type DeployOptions struct {
+	// Kind 是 proxy.UUPS（默认，ERC1967Proxy，升级函数由实现合约提供）或 proxy.Transparent
+	Kind proxy.Kind
+	// Admin 是透明代理的管理员，只能升级，不能调用实现合约，应与日常使用的账户不同
+	Admin common.Address
+	// Initializer 是初始化函数名，部署代理时执行；为空时不初始化
+	Initializer string
+	Args        []interface{}
+}
+
+// Deployment 是部署结果
+type Deployment struct {
+	Proxy            common.Address
+	Implementation   common.Address
+	Admin            common.Address // 仅透明代理
+	Kind             proxy.Kind
+	ImplementationTx *types.Transaction
+	ProxyTx          *types.Transaction
+}
+
+// DeployProxy 部署实现合约和代理，部署代理时执行初始化调用，部署后确认代理指向实现合约
+func (m *Manager) DeployProxy(ctx context.Context, opts *bind.TransactOpts, impl *Implementation, o DeployOptions) (*Deployment, error) {
+	if o.Kind == proxy.None {
+		o.Kind = proxy.UUPS
+	}
+	if o.Kind != proxy.UUPS && o.Kind != proxy.Transparent {
+		return nil, fmt.Errorf("不支持部署 %s 代理，可选 %s、%s", o.Kind, proxy.UUPS, proxy.Transparent)
+	}
+	if o.Kind == proxy.Transparent && o.Admin == (common.Address{}) {
+		return nil, errors.New("透明代理需要 Admin")
+	}
+	var initData []byte
+	if o.Initializer != "" {
+		var err error
+		if initData, err = impl.ABI.Pack(o.Initializer, o.Args...); err != nil {
+			return nil, fmt.Errorf("编码初始化调用失败: %w", err)
+		}
+	}
+
+	implAddr, implTx, err := m.deploy(ctx, opts, impl.ABI, impl.Bytecode)
+	if err != nil {
+		return nil, fmt.Errorf("部署实现合约 %s 失败: %w", impl.Name, err)
+	}
+	d := &Deployment{Implementation: implAddr, Kind: o.Kind, ImplementationTx: implTx}
+
+	var proxyAddr common.Address
+	var proxyTx *types.Transaction
+	if o.Kind == proxy.Transparent {
+		d.Admin = o.Admin
+		proxyAddr, proxyTx, err = m.deployArtifact(ctx, opts, transparentProxyABI, transparentProxyBin, implAddr, o.Admin, initData)
+	} else {
+		proxyAddr, proxyTx, err = m.deployArtifact(ctx, opts, erc1967ProxyABI, erc1967ProxyBin, implAddr, initData)
+	}
+	if err != nil {
+		return d, fmt.Errorf("部署代理失败: %w", err)
+	}
+	d.Proxy, d.ProxyTx = proxyAddr, proxyTx
+
+	info, err := proxy.New(m.backend).Inspect(ctx, proxyAddr)
+	if err != nil {
+		return d, err
+	}
+	if info.Implementation != implAddr {
+		return d, fmt.Errorf("代理 %s 指向 %s，期望 %s", proxyAddr.Hex(), info.Implementation.Hex(), implAddr.Hex())
+	}
+	return d, nil
+}
+
+// UpgradeOptions 是升级选项
+type UpgradeOptions struct {
+	// Call 是升级后立即执行的函数（例如 V2 的初始化函数），为空时不调用
+	Call string
+	Args []interface{}
+	// SkipLayoutCheck 跳过存储布局检查，只在确认布局问题无害时使用
+	SkipLayoutCheck bool
+}
+
+// Upgrade 是升级结果
+type Upgrade struct {
+	Proxy            common.Address
+	Previous         common.Address
+	Implementation   common.Address
+	Problems         []Problem // 存储布局检查发现的问题（只含警告，有错误时不会升级）
+	ImplementationTx *types.Transaction
+	Tx               *types.Transaction
+	Receipt          *types.Receipt
+}
+
+// Upgrade 把代理从 from 升级到 to：
+//  1. 检查存储布局（两个版本都有 Layout 时）；
+//  2. 核对代理当前的实现合约就是 from（构造函数没有参数时）；
+//  3. 部署新的实现合约；
+//  4. 用 eth_call 模拟 upgradeToAndCall，失败时不发送升级交易并返回 *chainerr.RevertError；
+//  5. 发送升级交易，确认代理指向新的实现合约。
+//
+// 透明代理由 admin 发送升级交易，UUPS 由实现合约 _authorizeUpgrade 允许的账户发送
+func (m *Manager) Upgrade(ctx context.Context, opts *bind.TransactOpts, proxyAddr common.Address, from, to *Implementation, o UpgradeOptions) (*Upgrade, error) {
+	inspector := proxy.New(m.backend)
+	info, err := inspector.Inspect(ctx, proxyAddr)
+	if err != nil {
+		return nil, err
+	}
+	switch info.Kind {
+	case proxy.UUPS, proxy.Transparent, proxy.EIP1967:
+	default:
+		return nil, fmt.Errorf("%w: %s（%v）", ErrNotUpgradeable, proxyAddr.Hex(), info)
+	}
+	u := &Upgrade{Proxy: proxyAddr, Previous: info.Implementation}
+
+	if !o.SkipLayoutCheck && from.Layout != nil && to.Layout != nil {
+		problems, err := CheckLayout(from.Layout, to.Layout)
+		if err != nil {
+			return u, err
+		}
+		for _, p := range problems {
+			if p.Severity == SeverityError {
+				return u, fmt.Errorf("%s -> %s: %w", from.Name, to.Name, Compatible(from.Layout, to.Layout))
+			}
+		}
+		u.Problems = problems
+	}
+
+	if len(from.ABI.Constructor.Inputs) == 0 && len(from.Bytecode) > 0 {
+		artifact := &verify.Artifact{Name: from.Name, ABI: from.ABI, Bytecode: from.Bytecode}
+		report, err := verify.New(m.backend).Verify(ctx, info.Implementation, artifact, verify.Options{})
+		if err != nil {
+			return u, fmt.Errorf("核对当前实现合约失败: %w", err)
+		}
+		if report.Status == verify.Mismatch {
+			return u, fmt.Errorf("%w: %s 不是 %s（%s）", ErrWrongImplementation, info.Implementation.Hex(), from.Name, report.Reason)
+		}
+	}
+
+	var callData []byte
+	if o.Call != "" {
+		if callData, err = to.ABI.Pack(o.Call, o.Args...); err != nil {
+			return u, fmt.Errorf("编码升级调用失败: %w", err)
+		}
+	}
+
+	implAddr, implTx, err := m.deploy(ctx, opts, to.ABI, to.Bytecode)
+	if err != nil {
+		return u, fmt.Errorf("部署实现合约 %s 失败: %w", to.Name, err)
+	}
+	u.Implementation, u.ImplementationTx = implAddr, implTx
+
+	parsed, _ := abi.JSON(strings.NewReader(upgradeABI))
+	data, err := parsed.Pack("upgradeToAndCall", implAddr, callData)
+	if err != nil {
+		return u, fmt.Errorf("编码 upgradeToAndCall 失败: %w", err)
+	}
+	msg := ethereum.CallMsg{From: opts.From, To: &proxyAddr, Data: data, Value: opts.Value}
+	if _, err := m.backend.CallContract(ctx, msg, nil); err != nil {
+		return u, fmt.Errorf("模拟升级失败，未发送升级交易: %w", chainerr.Wrap(err))
+	}
+
+	txOpts := *opts
+	if txOpts.Context == nil {
+		txOpts.Context = ctx
+	}
+	bound := bind.NewBoundContract(proxyAddr, parsed, m.backend, m.backend, m.backend)
+	tx, err := bound.RawTransact(&txOpts, data)
+	if err != nil {
+		return u, fmt.Errorf("发送升级交易失败: %w", chainerr.Wrap(err))
+	}
+	u.Tx = tx
+	receipt, err := txutil.WaitSuccess(ctx, m.backend, tx, m.PollInterval)
+	u.Receipt = receipt
+	if err != nil {
+		return u, fmt.Errorf("升级交易 %s 失败: %w", tx.Hash().Hex(), err)
+	}
+
+	after, err := inspector.Inspect(ctx, proxyAddr)
+	if err != nil {
+		return u, err
+	}
+	if after.Implementation != implAddr {
+		return u, fmt.Errorf("升级后代理指向 %s，期望 %s", after.Implementation.Hex(), implAddr.Hex())
+	}
+	return u, nil
+}
+
+func (m *Manager) deployArtifact(ctx context.Context, opts *bind.TransactOpts, abiJSON, bin string, args ...interface{}) (common.Address, *types.Transaction, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("解析内置 ABI 失败: %w", err)
+	}
+	return m.deploy(ctx, opts, &parsed, common.FromHex(strings.TrimSpace(bin)), args...)
+}
+
+// deploy 发送创建交易并等待成功
+func (m *Manager) deploy(ctx context.Context, opts *bind.TransactOpts, contractABI *abi.ABI, bytecode []byte, args ...interface{}) (common.Address, *types.Transaction, error) {
+	txOpts := *opts
+	if txOpts.Context == nil {
+		txOpts.Context = ctx
+	}
+	addr, tx, _, err := bind.DeployContract(&txOpts, *contractABI, bytecode, m.backend, args...)
+	if err != nil {
+		return common.Address{}, nil, fmt.Errorf("发送部署交易失败: %w", chainerr.Wrap(err))
+	}
+	if _, err := txutil.WaitSuccess(ctx, m.backend, tx, m.PollInterval); err != nil {
+		return addr, tx, fmt.Errorf("部署交易 %s 失败: %w", tx.Hash().Hex(), err)
+	}
+	return addr, tx, nil
+}
@@ -0,0 +1,192 @@
+package upgrades
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/proxy"
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// testdata/box.json 是 solc --standard-json 编译 testdata/Box.sol 的输出
+func loadBoxes(t *testing.T) map[string]*Implementation {
+	t.Helper()
+	impls, err := LoadSolcOutput("testdata/box.json")
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	return impls
+}
+
+// 测试存储布局兼容性检查
+func TestCheckLayout(t *testing.T) {
+	boxes := loadBoxes(t)
+	v1, v2, bad := boxes["BoxV1"].Layout, boxes["BoxV2"].Layout, boxes["BoxV2Bad"].Layout
+
+	// 使用 __gap 中的一个槽新增变量
+	if problems, err := CheckLayout(v1, v2); err != nil || len(problems) != 0 {
+		t.Fatalf("❌ V1 -> V2 应兼容: %v %v", problems, err)
+	}
+	if err := Compatible(v1, v2); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+
+	// 调换变量顺序
+	err := Compatible(v1, bad)
+	if !errors.Is(err, ErrIncompatible) || !strings.Contains(err.Error(), "owner") {
+		t.Fatalf("❌ 调换顺序应不兼容: %v", err)
+	}
+
+	// 重命名只是警告
+	renamed := cloneLayout(v2)
+	renamed.Storage[2].Label = "amount"
+	problems, _ := CheckLayout(v1, renamed)
+	if len(problems) != 1 || problems[0].Severity != SeverityWarning || problems[0].Variable != "value" {
+		t.Fatalf("❌ 重命名应只产生警告: %v", problems)
+	}
+
+	// 类型变化
+	retyped := cloneLayout(v2)
+	retyped.Storage[2].Type = "t_address"
+	if problems, _ := CheckLayout(v1, retyped); len(problems) == 0 || problems[0].Severity != SeverityError || !strings.Contains(problems[0].Message, "uint256") {
+		t.Fatalf("❌ 类型变化应报错: %v", problems)
+	}
+
+	// 新增变量后没有缩小 __gap
+	grown := cloneLayout(v2)
+	grown.Storage[4].Type = "t_array(t_uint256)48_storage"
+	grown.Types["t_array(t_uint256)48_storage"] = &StorageType{Encoding: "inplace", Label: "uint256[48]", NumberOfBytes: "1536", Base: "t_uint256"}
+	if problems, _ := CheckLayout(v1, grown); len(problems) != 1 || !strings.Contains(problems[0].Message, "__gap") {
+		t.Fatalf("❌ __gap 未缩小应报错: %v", problems)
+	}
+
+	// 删除末尾变量
+	removed := cloneLayout(v1)
+	removed.Storage = removed.Storage[:2]
+	if problems, _ := CheckLayout(v1, removed); len(problems) != 1 || problems[0].Variable != "value" {
+		t.Fatalf("❌ 删除变量应报错: %v", problems)
+	}
+	t.Logf("✅ %v", err)
+}
+
+func cloneLayout(l *StorageLayout) *StorageLayout {
+	out := &StorageLayout{Storage: append([]StorageItem(nil), l.Storage...), Types: make(map[string]*StorageType)}
+	for k, v := range l.Types {
+		out.Types[k] = v
+	}
+	return out
+}
+
+// 测试 UUPS 代理的部署和升级：权限不足和升级调用失败时在模拟阶段拦截
+func TestUpgradeUUPS(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t)
+	chain.AutoMine(10 * time.Millisecond)
+	boxes := loadBoxes(t)
+	m := New(chain.Client)
+	m.PollInterval = 5 * time.Millisecond
+	owner := chain.Accounts[0].Address
+
+	d, err := m.DeployProxy(ctx, chain.Auth(0), boxes["BoxV1"], DeployOptions{Initializer: "initialize", Args: []interface{}{owner, big.NewInt(7)}})
+	if err != nil {
+		t.Fatalf("❌ 部署失败: %v", err)
+	}
+	if d.Kind != proxy.UUPS {
+		t.Fatalf("❌ 默认应为 UUPS 代理: %s", d.Kind)
+	}
+	box := bind.NewBoundContract(d.Proxy, *boxes["BoxV2"].ABI, chain.Client, chain.Client, chain.Client)
+	call := func(method string) interface{} {
+		t.Helper()
+		var out []interface{}
+		if err := box.Call(&bind.CallOpts{}, &out, method); err != nil {
+			t.Fatalf("❌ 调用 %s 失败: %v", method, err)
+		}
+		return out[0]
+	}
+	if call("value").(*big.Int).Int64() != 7 || call("owner").(common.Address) != owner || call("version") != "v1" {
+		t.Fatal("❌ 初始化调用未生效")
+	}
+
+	// 非 owner 升级：模拟失败，不发送升级交易
+	u, err := m.Upgrade(ctx, chain.Auth(1), d.Proxy, boxes["BoxV1"], boxes["BoxV2"], UpgradeOptions{})
+	var revert *chainerr.RevertError
+	if !errors.As(err, &revert) || revert.Reason != "BoxV1: caller is not the owner" || u.Tx != nil {
+		t.Fatalf("❌ 非 owner 升级应在模拟阶段失败: %v", err)
+	}
+
+	// 升级调用失败
+	_, err = m.Upgrade(ctx, chain.Auth(0), d.Proxy, boxes["BoxV1"], boxes["BoxV2"], UpgradeOptions{Call: "setCount", Args: []interface{}{big.NewInt(0)}})
+	if !errors.As(err, &revert) || revert.Reason != "BoxV2: zero count" {
+		t.Fatalf("❌ 升级调用 revert 应在模拟阶段失败: %v", err)
+	}
+
+	// 布局不兼容
+	if _, err := m.Upgrade(ctx, chain.Auth(0), d.Proxy, boxes["BoxV1"], boxes["BoxV2Bad"], UpgradeOptions{}); !errors.Is(err, ErrIncompatible) {
+		t.Fatalf("❌ 应返回 ErrIncompatible: %v", err)
+	}
+
+	// 给出的旧版本不是当前实现合约
+	if _, err := m.Upgrade(ctx, chain.Auth(0), d.Proxy, boxes["BoxV2"], boxes["BoxV2"], UpgradeOptions{}); !errors.Is(err, ErrWrongImplementation) {
+		t.Fatalf("❌ 应返回 ErrWrongImplementation: %v", err)
+	}
+
+	u, err = m.Upgrade(ctx, chain.Auth(0), d.Proxy, boxes["BoxV1"], boxes["BoxV2"], UpgradeOptions{Call: "setCount", Args: []interface{}{big.NewInt(5)}})
+	if err != nil {
+		t.Fatalf("❌ 升级失败: %v", err)
+	}
+	if u.Previous != d.Implementation || u.Receipt == nil {
+		t.Fatalf("❌ 升级结果不符: %+v", u)
+	}
+	if call("value").(*big.Int).Int64() != 7 || call("count").(*big.Int).Int64() != 5 || call("version") != "v2" {
+		t.Fatal("❌ 升级后状态不符")
+	}
+	t.Logf("✅ %s: %s -> %s", d.Proxy.Hex(), u.Previous.Hex(), u.Implementation.Hex())
+}
+
+// 测试透明代理：admin 升级，其他账户正常使用
+func TestUpgradeTransparent(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t)
+	chain.AutoMine(10 * time.Millisecond)
+	boxes := loadBoxes(t)
+	m := New(chain.Client)
+	m.PollInterval = 5 * time.Millisecond
+	admin := chain.Accounts[1].Address
+
+	if _, err := m.DeployProxy(ctx, chain.Auth(0), boxes["BoxV1"], DeployOptions{Kind: proxy.Transparent}); err == nil {
+		t.Fatal("❌ 透明代理缺少 Admin 应报错")
+	}
+	d, err := m.DeployProxy(ctx, chain.Auth(0), boxes["BoxV1"], DeployOptions{
+		Kind: proxy.Transparent, Admin: admin,
+		Initializer: "initialize", Args: []interface{}{chain.Accounts[0].Address, big.NewInt(3)},
+	})
+	if err != nil {
+		t.Fatalf("❌ 部署失败: %v", err)
+	}
+	info, err := proxy.New(chain.Client).Inspect(ctx, d.Proxy)
+	if err != nil || info.Kind != proxy.Transparent || info.Admin != admin {
+		t.Fatalf("❌ 应识别为透明代理: %v %v", info, err)
+	}
+
+	u, err := m.Upgrade(ctx, chain.Auth(1), d.Proxy, boxes["BoxV1"], boxes["BoxV2"], UpgradeOptions{})
+	if err != nil {
+		t.Fatalf("❌ admin 升级失败: %v", err)
+	}
+	box := bind.NewBoundContract(d.Proxy, *boxes["BoxV2"].ABI, chain.Client, chain.Client, chain.Client)
+	var out []interface{}
+	if err := box.Call(&bind.CallOpts{From: chain.Accounts[0].Address}, &out, "value"); err != nil || out[0].(*big.Int).Int64() != 3 {
+		t.Fatalf("❌ 升级后数据应保留: %v %v", out, err)
+	}
+	// admin 不能调用实现合约
+	if err := box.Call(&bind.CallOpts{From: admin}, &out, "value"); err == nil {
+		t.Fatal("❌ admin 调用实现合约应被拒绝")
+	}
+	t.Logf("✅ %s -> %s", u.Previous.Hex(), u.Implementation.Hex())
+}
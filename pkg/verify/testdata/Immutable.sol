@@ -0,0 +1,13 @@
+// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.26;
+
+// 用于测试 immutable 变量的屏蔽：owner 和 limit 在部署时写入运行时代码
+contract Immutable {
+    address public immutable owner;
+    uint256 public immutable limit;
+
+    constructor(uint256 _limit) {
+        owner = msg.sender;
+        limit = _limit;
+    }
+}
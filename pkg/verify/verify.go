@@ -0,0 +1,308 @@
+// Package verify 核对链上合约是否就是本地构建产物：去掉 Solidity CBOR 元数据和 immutable 变量后
+// 比较运行时代码，并从创建交易的 input 中取出构造函数参数，结果分为完全一致、部分一致（只有元数据不同）和不一致
+package verify
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/create2"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ErrNoCode 表示地址上没有合约代码
+var ErrNoCode = errors.New("地址上没有合约代码")
+
+// Status 是核对结果
+type Status int
+
+const (
+	// Mismatch 表示代码不一致
+	Mismatch Status = iota
+	// Partial 表示去掉元数据后代码一致，元数据不同（源码注释、路径或编译设置不同，但行为相同）
+	Partial
+	// Exact 表示代码和元数据都一致（immutable 变量除外）
+	Exact
+)
+
+func (s Status) String() string {
+	switch s {
+	case Exact:
+		return "exact"
+	case Partial:
+		return "partial"
+	default:
+		return "mismatch"
+	}
+}
+
+// Artifact 是合约的本地构建产物
+type Artifact struct {
+	Name     string
+	ABI      *abi.ABI
+	Bytecode []byte // 创建字节码（不含构造函数参数）
+	// DeployedBytecode 是运行时字节码（solc 的 bin-runtime，immutable 位置为 0）。
+	// 为空时用创建字节码和构造函数参数模拟部署得到
+	DeployedBytecode []byte
+}
+
+// LoadArtifact 读取 solc 输出的 <prefix>.abi、<prefix>.bin 和可选的 <prefix>.bin-runtime，
+// 例如 LoadArtifact("pkg/contracts/store/Store_sol_Store")
+func LoadArtifact(prefix string) (*Artifact, error) {
+	abiJSON, err := os.ReadFile(prefix + ".abi")
+	if err != nil {
+		return nil, fmt.Errorf("读取 ABI 失败: %w", err)
+	}
+	bin, err := os.ReadFile(prefix + ".bin")
+	if err != nil {
+		return nil, fmt.Errorf("读取字节码失败: %w", err)
+	}
+	var runtime []byte
+	if data, err := os.ReadFile(prefix + ".bin-runtime"); err == nil {
+		runtime = common.FromHex(strings.TrimSpace(string(data)))
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("读取运行时字节码失败: %w", err)
+	}
+	name := prefix[strings.LastIndexAny(prefix, `/\`)+1:]
+	if i := strings.LastIndex(name, "_sol_"); i >= 0 {
+		name = name[i+len("_sol_"):]
+	}
+	return NewArtifact(name, string(abiJSON), common.FromHex(strings.TrimSpace(string(bin))), runtime)
+}
+
+// NewArtifact 由 ABI JSON 和字节码创建构建产物，runtime 可以为 nil
+func NewArtifact(name, abiJSON string, bytecode, runtime []byte) (*Artifact, error) {
+	parsed, err := abi.JSON(strings.NewReader(abiJSON))
+	if err != nil {
+		return nil, fmt.Errorf("解析 %s 的 ABI 失败: %w", name, err)
+	}
+	return &Artifact{Name: name, ABI: &parsed, Bytecode: bytecode, DeployedBytecode: runtime}, nil
+}
+
+// SplitMetadata 把字节码分成代码和末尾的 CBOR 元数据（solc 在末尾写入 CBOR 编码的映射和 2 字节长度）。
+// 没有可识别的元数据时 metadata 为 nil
+func SplitMetadata(code []byte) (body, metadata []byte) {
+	if len(code) < 2 {
+		return code, nil
+	}
+	n := int(binary.BigEndian.Uint16(code[len(code)-2:]))
+	start := len(code) - 2 - n
+	// CBOR 映射以 0xa1-0xb7 开头（1 到 23 个键），solc 写入 ipfs/bzzr0/bzzr1/solc/experimental 中的若干个
+	if n == 0 || start < 0 || code[start] < 0xa1 || code[start] > 0xb7 {
+		return code, nil
+	}
+	meta := code[start+1 : len(code)-2]
+	for _, key := range []string{"ipfs", "bzzr0", "bzzr1", "solc", "experimental"} {
+		if bytes.Contains(meta, []byte(key)) {
+			return code[:start], code[start:]
+		}
+	}
+	return code, nil
+}
+
+// Immutable 是运行时代码中一个 immutable 变量的位置和链上的值
+type Immutable struct {
+	Offset int
+	Value  common.Hash
+}
+
+// Options 是核对选项
+type Options struct {
+	// TxHash 是创建交易，设置后从交易 input 中取出并核对构造函数参数
+	TxHash *common.Hash
+	// Args 是期望的构造函数参数；没有 TxHash 时用于模拟部署得到运行时代码
+	Args []interface{}
+	// Factory 是 CREATE2 工厂地址，创建交易发给工厂时 input 为 salt ++ 初始化代码；默认 create2.FactoryAddress
+	Factory *common.Address
+}
+
+// Report 是核对报告
+type Report struct {
+	Address      common.Address
+	Artifact     string
+	Status       Status
+	CodeHash     common.Hash // 链上运行时代码的哈希
+	MetadataOK   bool        // 元数据一致
+	Immutables   []Immutable
+	Args         []interface{} // 从创建交易中解出的构造函数参数
+	ArgsVerified bool          // 创建交易中的参数与 Options.Args 一致（未设置 Args 时只要求能解码）
+	Reason       string        // 不一致的原因
+}
+
+func (r *Report) String() string {
+	s := fmt.Sprintf("%s @ %s: %s", r.Artifact, r.Address.Hex(), r.Status)
+	if r.Reason != "" {
+		s += "（" + r.Reason + "）"
+	}
+	return s
+}
+
+// Backend 是核对需要的节点接口，*ethclient.Client 满足
+type Backend interface {
+	bind.ContractCaller
+	ethereum.TransactionReader
+}
+
+// Verifier 核对链上合约
+type Verifier struct {
+	backend Backend
+}
+
+// New 创建核对器
+func New(backend Backend) *Verifier {
+	return &Verifier{backend: backend}
+}
+
+// Verify 核对 addr 上的合约与构建产物。节点错误返回 error，代码不一致只体现在 Report.Status 中
+func (v *Verifier) Verify(ctx context.Context, addr common.Address, artifact *Artifact, opts Options) (*Report, error) {
+	code, err := v.backend.CodeAt(ctx, addr, nil)
+	if err != nil {
+		return nil, fmt.Errorf("查询 %s 代码失败: %w", addr.Hex(), chainerr.Wrap(err))
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoCode, addr.Hex())
+	}
+	report := &Report{Address: addr, Artifact: artifact.Name, CodeHash: crypto.Keccak256Hash(code)}
+
+	// 构造函数参数：创建交易中的优先，其次是调用方给出的
+	var packedArgs []byte
+	if opts.Args != nil {
+		if packedArgs, err = artifact.ABI.Pack("", opts.Args...); err != nil {
+			return nil, fmt.Errorf("编码构造函数参数失败: %w", err)
+		}
+	}
+	if opts.TxHash != nil {
+		factory := create2.FactoryAddress
+		if opts.Factory != nil {
+			factory = *opts.Factory
+		}
+		txArgs, reason, err := v.creationArgs(ctx, *opts.TxHash, factory, artifact)
+		if err != nil {
+			return nil, err
+		}
+		if reason != "" {
+			report.Reason = reason
+			return report, nil
+		}
+		if report.Args, err = artifact.ABI.Constructor.Inputs.Unpack(txArgs); err != nil {
+			report.Reason = fmt.Sprintf("解码构造函数参数失败: %v", err)
+			return report, nil
+		}
+		if opts.Args != nil && !bytes.Equal(txArgs, packedArgs) {
+			report.Reason = "构造函数参数与期望不一致"
+			return report, nil
+		}
+		report.ArgsVerified = true
+		packedArgs = txArgs
+	}
+
+	// template 是 immutable 位置为 0 的运行时代码，用来区分 immutable 和真正不同的常量
+	want := artifact.DeployedBytecode
+	template := want
+	if len(want) == 0 {
+		if opts.TxHash == nil && opts.Args == nil && len(artifact.ABI.Constructor.Inputs) > 0 {
+			return nil, fmt.Errorf("%s 没有运行时字节码，需要 TxHash 或 Args 来模拟部署", artifact.Name)
+		}
+		initCode := append(append([]byte{}, artifact.Bytecode...), packedArgs...)
+		if want, err = v.backend.CallContract(ctx, ethereum.CallMsg{Data: initCode}, nil); err != nil {
+			return nil, fmt.Errorf("模拟部署 %s 失败: %w", artifact.Name, chainerr.Wrap(err))
+		}
+		template = embeddedRuntime(artifact.Bytecode, want)
+	}
+	compare(report, code, want, template)
+	return report, nil
+}
+
+// creationArgs 从创建交易的 input 中取出构造函数参数；交易与构建产物对不上时返回原因
+func (v *Verifier) creationArgs(ctx context.Context, hash common.Hash, factory common.Address, artifact *Artifact) ([]byte, string, error) {
+	tx, _, err := v.backend.TransactionByHash(ctx, hash)
+	if err != nil {
+		return nil, "", fmt.Errorf("查询创建交易 %s 失败: %w", hash.Hex(), chainerr.Wrap(err))
+	}
+	input := tx.Data()
+	switch {
+	case tx.To() == nil:
+	case *tx.To() == factory && len(input) >= 32:
+		input = input[32:]
+	default:
+		return nil, fmt.Sprintf("交易 %s 不是创建交易", hash.Hex()), nil
+	}
+	// 元数据可能不同（Partial），只要求元数据之前的代码一致、元数据长度相同
+	body, _ := SplitMetadata(artifact.Bytecode)
+	if len(input) < len(artifact.Bytecode) || !bytes.Equal(input[:len(body)], body) {
+		return nil, "创建交易中的字节码与构建产物不一致", nil
+	}
+	return input[len(artifact.Bytecode):], "", nil
+}
+
+// embeddedRuntime 从创建字节码中取出运行时代码的原样副本（immutable 位置为 0）：
+// solc 把运行时代码连同末尾的元数据整段放在创建字节码中。找不到时返回 nil
+func embeddedRuntime(creation, runtime []byte) []byte {
+	_, meta := SplitMetadata(runtime)
+	if len(meta) == 0 {
+		return nil
+	}
+	end := bytes.LastIndex(creation, meta)
+	if end < 0 {
+		return nil
+	}
+	end += len(meta)
+	if end < len(runtime) {
+		return nil
+	}
+	return creation[end-len(runtime) : end]
+}
+
+// compare 比较运行时代码：template 中操作数为 0 的 PUSH32 是 immutable 变量（solc 把 immutable 编译为 PUSH32，
+// 部署前用 0 占位），链上的值可以不同；其余字节（包括非零的 32 字节常量）必须一致。template 为 nil 时不接受任何差异
+func compare(report *Report, code, want, template []byte) {
+	gotBody, gotMeta := SplitMetadata(code)
+	wantBody, wantMeta := SplitMetadata(want)
+	if len(gotBody) != len(wantBody) {
+		report.Reason = fmt.Sprintf("代码长度 %d，期望 %d", len(gotBody), len(wantBody))
+		return
+	}
+	for pc := 0; pc < len(wantBody); {
+		op := vm.OpCode(wantBody[pc])
+		size := 1
+		if op.IsPush() {
+			size += int(op - vm.PUSH0)
+		}
+		end := min(pc+size, len(wantBody))
+		if !bytes.Equal(gotBody[pc:end], wantBody[pc:end]) {
+			if op != vm.PUSH32 || gotBody[pc] != wantBody[pc] || end-pc != 33 || !placeholder(template, pc+1) {
+				report.Reason = fmt.Sprintf("偏移 %d 处代码不同", pc)
+				report.Immutables = nil
+				return
+			}
+			report.Immutables = append(report.Immutables, Immutable{Offset: pc + 1, Value: common.BytesToHash(gotBody[pc+1 : end])})
+		}
+		pc = end
+	}
+	report.MetadataOK = bytes.Equal(gotMeta, wantMeta)
+	if report.MetadataOK {
+		report.Status = Exact
+	} else {
+		report.Status = Partial
+		report.Reason = "元数据不同"
+	}
+}
+
+// placeholder 判断 template 中 offset 处的 32 字节是否为 immutable 的 0 占位
+func placeholder(template []byte, offset int) bool {
+	if offset+32 > len(template) {
+		return false
+	}
+	return bytes.Equal(template[offset:offset+32], make([]byte, 32))
+}
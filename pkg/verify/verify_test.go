@@ -0,0 +1,155 @@
+package verify
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/token"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/create2"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/multicall"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/txutil"
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+func TestSplitMetadata(t *testing.T) {
+	code := common.FromHex(store.StoreMetaData.Bin)
+	body, meta := SplitMetadata(code)
+	if len(meta) != 0x33+2 || len(body)+len(meta) != len(code) || meta[0] != 0xa2 {
+		t.Fatalf("❌ 元数据拆分错误: %d 字节 %x", len(meta), meta)
+	}
+	if body, meta := SplitMetadata([]byte{0x60, 0x00, 0x00, 0x01}); meta != nil || len(body) != 4 {
+		t.Fatal("❌ 没有元数据时应原样返回")
+	}
+}
+
+// 测试各种部署方式下的核对结果
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	chain := simchain.New(t,
+		simchain.WithAlloc(multicall.Address, types.Account{Code: multicall.RuntimeCode, Balance: big.NewInt(0)}),
+		simchain.WithAlloc(create2.FactoryAddress, types.Account{Code: create2.FactoryCode, Balance: big.NewInt(0)}),
+	)
+	chain.AutoMine(10 * time.Millisecond)
+	v := New(chain.Client)
+
+	// 有 bin-runtime 的构建产物：预置的 Multicall3
+	mc, err := LoadArtifact("../multicall/contracts/Multicall3_sol_Multicall3")
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	if r, err := v.Verify(ctx, multicall.Address, mc, Options{}); err != nil || r.Status != Exact || mc.Name != "Multicall3" {
+		t.Fatalf("❌ Multicall3 应完全一致: %v %v", r, err)
+	}
+
+	// 非零的 32 字节常量不同（例如改了 bytes32 constant）不是 immutable，应报告不一致
+	constant := *mc
+	constant.DeployedBytecode = append([]byte{}, mc.DeployedBytecode...)
+	offset := nonZeroPush32(constant.DeployedBytecode)
+	if offset < 0 {
+		t.Fatal("❌ Multicall3 中没有非零的 PUSH32 常量")
+	}
+	constant.DeployedBytecode[offset+31] ^= 0xff
+	if r, err := v.Verify(ctx, multicall.Address, &constant, Options{}); err != nil || r.Status != Mismatch || len(r.Immutables) != 0 {
+		t.Fatalf("❌ PUSH32 常量不同应报告不一致: %v %v", r, err)
+	}
+
+	// immutable 变量：部署者地址和构造参数写入了运行时代码
+	imm, err := LoadArtifact("testdata/Immutable_sol_Immutable")
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	addr, tx, _, err := bind.DeployContract(chain.Auth(0), *imm.ABI, imm.Bytecode, chain.Client, big.NewInt(42))
+	if err != nil {
+		t.Fatalf("❌ 部署失败: %v", err)
+	}
+	if _, err := txutil.WaitSuccess(ctx, chain.Client, tx, 5*time.Millisecond); err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	hash := tx.Hash()
+	r, err := v.Verify(ctx, addr, imm, Options{TxHash: &hash, Args: []interface{}{big.NewInt(42)}})
+	if err != nil || r.Status != Exact || !r.ArgsVerified || len(r.Immutables) != 2 {
+		t.Fatalf("❌ immutable 合约应完全一致: %v %v", r, err)
+	}
+	if r.Args[0].(*big.Int).Int64() != 42 {
+		t.Fatalf("❌ 解出的构造参数错误: %v", r.Args)
+	}
+	values := map[common.Hash]bool{}
+	for _, im := range r.Immutables {
+		values[im.Value] = true
+	}
+	if !values[common.BytesToHash(chain.Accounts[0].Address.Bytes())] || !values[common.BigToHash(big.NewInt(42))] {
+		t.Fatalf("❌ immutable 的值不符: %+v", r.Immutables)
+	}
+	if r, _ := v.Verify(ctx, addr, imm, Options{TxHash: &hash, Args: []interface{}{big.NewInt(7)}}); r.Status != Mismatch || r.ArgsVerified {
+		t.Fatalf("❌ 构造参数不同应报告不一致: %v", r)
+	}
+
+	// 没有 bin-runtime 时模拟部署（部署者不同），占位位置取自创建字节码中内嵌的运行时代码
+	simulated := *imm
+	simulated.DeployedBytecode = nil
+	if r, err := v.Verify(ctx, addr, &simulated, Options{TxHash: &hash}); err != nil || r.Status != Exact || len(r.Immutables) != 1 {
+		t.Fatalf("❌ 模拟部署时 immutable 合约应完全一致: %v %v", r, err)
+	}
+
+	// 通过 CREATE2 工厂部署的 Store，没有 bin-runtime，模拟部署得到运行时代码
+	st, _ := NewArtifact("Store", store.StoreMetaData.ABI, common.FromHex(store.StoreMetaData.Bin), nil)
+	initCode, _ := create2.InitCode(st.ABI, st.Bytecode, "v1.0.0")
+	dep, err := create2.New(chain.Client).Deploy(ctx, chain.Auth(0), create2.Salt("verify"), initCode)
+	if err != nil {
+		t.Fatalf("❌ 部署 Store 失败: %v", err)
+	}
+	hash = dep.Tx.Hash()
+	r, err = v.Verify(ctx, dep.Address, st, Options{TxHash: &hash})
+	if err != nil || r.Status != Exact || !r.ArgsVerified || r.Args[0] != "v1.0.0" {
+		t.Fatalf("❌ Store 应完全一致: %v %v", r, err)
+	}
+
+	// 只有元数据不同（例如改了注释后重新编译）
+	modified := append([]byte{}, st.Bytecode...)
+	modified[len(modified)-10] ^= 0xff
+	partial := *st
+	partial.Bytecode = modified
+	if r, err := v.Verify(ctx, dep.Address, &partial, Options{TxHash: &hash}); err != nil || r.Status != Partial || r.MetadataOK {
+		t.Fatalf("❌ 只有元数据不同应为部分一致: %v %v", r, err)
+	}
+
+	// 完全不同的合约
+	erc, _ := NewArtifact("ReferenceERC20", token.ReferenceERC20ABI, common.FromHex(token.ReferenceERC20Bin), nil)
+	r, err = v.Verify(ctx, dep.Address, erc, Options{Args: []interface{}{"IWS Token", "IWS", big.NewInt(1)}})
+	if err != nil || r.Status != Mismatch || !strings.Contains(r.Reason, "代码") {
+		t.Fatalf("❌ 应报告不一致: %v %v", r, err)
+	}
+	if r, _ := v.Verify(ctx, dep.Address, erc, Options{TxHash: &hash}); r.Status != Mismatch {
+		t.Fatalf("❌ 创建交易中的字节码不同应报告不一致: %v", r)
+	}
+
+	if _, err := v.Verify(ctx, chain.Accounts[1].Address, st, Options{}); !errors.Is(err, ErrNoCode) {
+		t.Fatalf("❌ 应返回 ErrNoCode: %v", err)
+	}
+	t.Logf("✅ %v", r)
+}
+
+// nonZeroPush32 返回代码中第一个操作数非零的 PUSH32 的操作数偏移，没有时返回 -1
+func nonZeroPush32(code []byte) int {
+	body, _ := SplitMetadata(code)
+	for pc := 0; pc < len(body); {
+		op := vm.OpCode(body[pc])
+		if op == vm.PUSH32 && pc+33 <= len(body) && common.BytesToHash(body[pc+1:pc+33]) != (common.Hash{}) {
+			return pc + 1
+		}
+		if op.IsPush() {
+			pc += int(op - vm.PUSH0)
+		}
+		pc++
+	}
+	return -1
+}
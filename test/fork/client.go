@@ -6,6 +6,8 @@ import (
 	"math/big"
 	"time"
 
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/proxy"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
@@ -25,14 +27,14 @@ func NewClient(rpcURL string, timeout time.Duration) (*BlockchainClient, error)
 	client, err := ethclient.DialContext(ctx, rpcURL)
 	if err != nil {
 		cancel()
-		return nil, fmt.Errorf("连接失败: %v", err)
+		return nil, fmt.Errorf("连接失败: %w", chainerr.Wrap(err))
 	}
 
 	// 测试连接
 	_, err = client.NetworkID(ctx)
 	if err != nil {
 		cancel()
-		return nil, fmt.Errorf("网络连接测试失败: %v", err)
+		return nil, fmt.Errorf("网络连接测试失败: %w", chainerr.Wrap(err))
 	}
 
 	return &BlockchainClient{
@@ -66,17 +68,27 @@ func (c *BlockchainClient) GetNetworkID() (*big.Int, error) {
 
 	chainID, err := c.NetworkID(c.ctx)
 	if err != nil {
-		return nil, err
+		return nil, chainerr.Wrap(err)
 	}
 
 	c.ChainID = chainID
 	return chainID, nil
 }
 
+// 确认连接的是预期的链，不一致时返回 *chainerr.ChainMismatchError
+func (c *BlockchainClient) ExpectChainID(want *big.Int) error {
+	chainID, err := c.GetNetworkID()
+	if err != nil {
+		return err
+	}
+	return chainerr.CheckChainID(want, chainID)
+}
+
 // 获取账户余额
 func (c *BlockchainClient) GetBalance(address string) (*big.Int, error) {
 	addr := common.HexToAddress(address)
-	return c.BalanceAt(c.ctx, addr, nil)
+	balance, err := c.BalanceAt(c.ctx, addr, nil)
+	return balance, chainerr.Wrap(err)
 }
 
 // 检查合约代码
@@ -84,11 +96,16 @@ func (c *BlockchainClient) HasContractCode(address string) (bool, int, error) {
 	addr := common.HexToAddress(address)
 	code, err := c.CodeAt(c.ctx, addr, nil)
 	if err != nil {
-		return false, 0, err
+		return false, 0, chainerr.Wrap(err)
 	}
 	return len(code) > 0, len(code), nil
 }
 
+// 检查代理合约，返回代理类型和实现合约地址（不是代理时 Kind 为空）
+func (c *BlockchainClient) InspectProxy(address string) (*proxy.Info, error) {
+	return proxy.New(c.Client).Inspect(c.ctx, common.HexToAddress(address))
+}
+
 // Wei 转 Ether
 func WeiToEther(wei *big.Int) *big.Float {
 	return new(big.Float).Quo(new(big.Float).SetInt(wei), big.NewFloat(1e18))
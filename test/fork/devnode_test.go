@@ -0,0 +1,68 @@
+package fork
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/devnode"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+const balanceOfABI = `[{"name":"balanceOf","type":"function","stateMutability":"view","inputs":[{"name":"","type":"address"}],"outputs":[{"name":"","type":"uint256"}]}]`
+
+// 测试在分叉节点上给 Hardhat 账户0 直接注入 WBNB，并通过快照回滚恢复
+func TestFundWBNB(t *testing.T) {
+	ctx := context.Background()
+	node, err := devnode.Dial(ctx, DefaultConfig.RPCURL)
+	if err != nil {
+		t.Fatalf("❌ 连接开发节点失败: %v", err)
+	}
+	defer node.Close()
+	t.Logf("🖥️  节点: %s (%s)", node.Version(), node.Flavor())
+
+	wbnb := common.HexToAddress(DefaultConfig.TestAddresses[2])
+	account0 := common.HexToAddress(DefaultConfig.TestAddresses[0])
+	client := ethclient.NewClient(node.Client())
+	parsed, _ := abi.JSON(strings.NewReader(balanceOfABI))
+	balanceOf := func() *big.Int {
+		data, _ := parsed.Pack("balanceOf", account0)
+		out, err := client.CallContract(ctx, callMsg(wbnb, data), nil)
+		if err != nil {
+			t.Fatalf("❌ 查询 WBNB 余额失败: %v", err)
+		}
+		return new(big.Int).SetBytes(out)
+	}
+
+	snapshot, err := node.Snapshot(ctx)
+	if err != nil {
+		t.Fatalf("❌ 保存快照失败: %v", err)
+	}
+	before := balanceOf()
+
+	// WBNB 与 WETH9 布局相同，balanceOf 位于槽 3
+	amount := new(big.Int).Mul(big.NewInt(100), big.NewInt(1e18))
+	if err := node.SetTokenBalance(ctx, wbnb, account0, 3, amount); err != nil {
+		t.Fatalf("❌ 注入 WBNB 失败: %v", err)
+	}
+	if got := balanceOf(); got.Cmp(amount) != 0 {
+		t.Fatalf("❌ 注入后余额不符: %s", got)
+	}
+	t.Logf("✅ 账户0 WBNB 余额: %s", amount)
+
+	if err := node.Revert(ctx, snapshot); err != nil {
+		t.Fatalf("❌ 回滚快照失败: %v", err)
+	}
+	if got := balanceOf(); got.Cmp(before) != 0 {
+		t.Fatalf("❌ 回滚后余额应恢复为 %s，实际 %s", before, got)
+	}
+	t.Log("✅ 快照回滚成功")
+}
+
+func callMsg(to common.Address, data []byte) ethereum.CallMsg {
+	return ethereum.CallMsg{To: &to, Data: data}
+}
@@ -2,9 +2,13 @@ package fork
 
 import (
 	"context"
+	"errors"
 	"math/big"
 	"testing"
 	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/chainerr"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/forkcheck"
 )
 
 // 测试主入口
@@ -14,6 +18,27 @@ func TestForkStatus(t *testing.T) {
 	t.Run("区块信息测试", testBlockInfo)
 	t.Run("账户余额测试", testAccountBalances)
 	t.Run("BSC合约测试", testBSCContracts)
+	t.Run("分叉断言检查", testForkCheck)
+}
+
+// 测试6: 使用 forkcheck 内置的 BSC 测试网配置做完整检查（与 iws fork check -preset bsc-testnet 相同）
+func testForkCheck(t *testing.T) {
+	cli, err := NewClient(DefaultConfig.RPCURL,
+		time.Duration(DefaultConfig.TestTimeout)*time.Second)
+	if err != nil {
+		t.Fatalf("❌ 连接失败: %v", err)
+	}
+	defer cli.Close()
+
+	report, err := forkcheck.Check(context.Background(), cli.Client, forkcheck.Presets["bsc-testnet"])
+	if err != nil {
+		t.Fatalf("❌ 分叉检查失败: %v", err)
+	}
+	for _, res := range report.Results {
+		t.Logf("[%s] %s %s: %s %s", res.Status, res.Kind, res.Name, res.Actual, res.Message)
+	}
+	// 与其他子测试一致，这里只记录结果；CI 门禁使用 iws fork check 的退出码
+	t.Logf("📋 检查结果: %s", report.Status)
 }
 
 // 测试1: 连接是否成功
@@ -73,9 +98,8 @@ func testNetworkInfo(t *testing.T) {
 
 	// 如果是分叉测试，期望是 BSC 测试网
 	expectedChainID := DefaultConfig.ChainIDs["bsc_test"]
-	if chainID.Cmp(expectedChainID) != 0 {
-		t.Logf("⚠️  注意: 期望链ID %d (BSC测试网)，实际得到 %d",
-			expectedChainID, chainID)
+	if err := cli.ExpectChainID(expectedChainID); errors.Is(err, chainerr.ErrChainMismatch) {
+		t.Logf("⚠️  注意: %v (期望 BSC 测试网)", err)
 	}
 }
 
@@ -176,6 +200,11 @@ func testBSCContracts(t *testing.T) {
 			t.Logf("✅ 检测到 %s 合约 (代码大小: %d 字节)",
 				contract.name, codeSize)
 			contractsFound++
+			if info, err := cli.InspectProxy(contract.address); err != nil {
+				t.Logf("⚠️  检查 %s 代理信息失败: %v", contract.name, err)
+			} else if info.IsProxy() {
+				t.Logf("   🔀 %s 是 %s 代理，实现合约: %s", contract.name, info.Kind, info.Implementation.Hex())
+			}
 		} else {
 			t.Logf("❌ 未检测到 %s 合约", contract.name)
 		}
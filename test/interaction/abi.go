@@ -1,8 +1,8 @@
 package interaction
 
-import _ "embed"
+import "github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/token"
 
-// ==================== 编译时嵌入 ABI 文件 ====================
+// ==================== ERC20 ABI ====================
 
-//go:embed contracts/token/ERC20.abi
-var erc20ABIJSON string
+// erc20ABIJSON 是 IERC20MetadataUpgradeable 的 ABI，与 pkg/contracts/token/ERC20.abi 相同
+var erc20ABIJSON = token.TokenMetaData.ABI
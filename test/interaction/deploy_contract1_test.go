@@ -2,47 +2,38 @@ package interaction
 
 import (
 	"context"
-	_ "embed" // embed 包用于编译时嵌入文件
 	"fmt"
-	"log"
 	"math/big"
 	"strings"
 	"testing"
-	"time"
 
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/txutil"
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
-// ==================== 编译时嵌入文件 ====================
-// 使用 //go:embed 指令将文件内容嵌入到变量中
-// 注意：文件必须在同目录下，文件名要完全匹配
+// ==================== 合约编译产物 ====================
+// Store 的 ABI 和字节码由 pkg/contracts 中的 solc 产物生成（见 iws contracts），
+// abigen 绑定的 StoreMetaData 中保存了同样的内容
 
-//go:embed contracts/store/Store_sol_Store.abi
-var contractABIJSON string // ABI 文件内容（JSON 格式）
-
-//go:embed contracts/store/Store_sol_Store.bin
-var contractBytecodeHex string // 字节码文件内容（十六进制字符串）
+var (
+	contractABIJSON     = store.StoreMetaData.ABI // ABI（JSON 格式）
+	contractBytecodeHex = store.StoreMetaData.Bin // 字节码（十六进制字符串）
+)
 
 // ==================== 测试函数：部署合约 ====================
 func TestDeployContract1(t *testing.T) {
 	// ============ 第一步：连接以太坊节点 ============
-	// 使用 Alchemy 提供的 Sepolia 测试网节点
-	client, err := ethclient.Dial("https://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
-	if err != nil {
-		log.Fatalf("❌ 连接节点失败: %v", err)
-	}
-	fmt.Println("✅ 成功连接到 Sepolia 测试网")
+	// 默认使用离线模拟链，IWS_LIVE=1 时使用 Alchemy 提供的 Sepolia 测试网节点
+	env := newTestEnv(t, "https://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
+	client := env.Client
 
 	// ============ 第二步：加载私钥 ============
-	// 从十六进制字符串加载私钥（注意：生产环境应从环境变量或密钥管理服务读取）
-	privateKey, err := crypto.HexToECDSA("ab99f80b034909680a1f840bd37a5f45bda536a2cc484c09dbea504914bcbbd9")
-	if err != nil {
-		log.Fatalf("❌ 加载私钥失败: %v", err)
-	}
+	// 测试私钥（注意：生产环境应从环境变量或密钥管理服务读取）
+	privateKey := env.Key
 
 	// 从私钥推导出公钥和地址
 	publicKey := privateKey.PublicKey
@@ -53,7 +44,7 @@ func TestDeployContract1(t *testing.T) {
 	// Nonce 是账户发送交易的序号，用于防止重放攻击
 	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
 	if err != nil {
-		log.Fatalf("❌ 获取 Nonce 失败: %v", err)
+		t.Fatalf("❌ 获取 Nonce 失败: %v", err)
 	}
 	fmt.Printf("🔢 当前 Nonce: %d\n", nonce)
 
@@ -61,7 +52,7 @@ func TestDeployContract1(t *testing.T) {
 	// Gas Price 是执行交易时愿意支付的每单位 Gas 的价格
 	gasPrice, err := client.SuggestGasPrice(context.Background())
 	if err != nil {
-		log.Fatalf("❌ 获取 Gas Price 失败: %v", err)
+		t.Fatalf("❌ 获取 Gas Price 失败: %v", err)
 	}
 	fmt.Printf("⛽ 建议 Gas Price: %s wei\n", gasPrice.String())
 
@@ -69,7 +60,7 @@ func TestDeployContract1(t *testing.T) {
 	// ABI（Application Binary Interface）描述了合约的函数签名
 	contractABI, err := abi.JSON(strings.NewReader(contractABIJSON))
 	if err != nil {
-		log.Fatalf("❌ 解析 ABI 失败: %v", err)
+		t.Fatalf("❌ 解析 ABI 失败: %v", err)
 	}
 	fmt.Println("✅ ABI 解析成功")
 
@@ -85,7 +76,7 @@ func TestDeployContract1(t *testing.T) {
 	versionParam := "v1.0.0" // 合约版本号
 	encodedArgs, err := contractABI.Pack("", versionParam)
 	if err != nil {
-		log.Fatalf("❌ 编码构造函数参数失败: %v", err)
+		t.Fatalf("❌ 编码构造函数参数失败: %v", err)
 	}
 	fmt.Printf("🔧 构造函数参数: version = %s\n", versionParam)
 
@@ -106,21 +97,21 @@ func TestDeployContract1(t *testing.T) {
 	// 获取链 ID（Sepolia 测试网的 Chain ID 是 11155111）
 	chainID, err := client.ChainID(context.Background())
 	if err != nil {
-		log.Fatalf("❌ 获取 Chain ID 失败: %v", err)
+		t.Fatalf("❌ 获取 Chain ID 失败: %v", err)
 	}
 	fmt.Printf("🔗 Chain ID: %s\n", chainID.String())
 
 	// 使用 EIP-155 签名算法对交易进行签名
 	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
 	if err != nil {
-		log.Fatalf("❌ 签名交易失败: %v", err)
+		t.Fatalf("❌ 签名交易失败: %v", err)
 	}
 	fmt.Println("✅ 交易签名成功")
 
 	// ============ 第十一步：发送交易到网络 ============
 	err = client.SendTransaction(context.Background(), signedTx)
 	if err != nil {
-		log.Fatalf("❌ 发送交易失败: %v", err)
+		t.Fatalf("❌ 发送交易失败: %v", err)
 	}
 
 	// 打印交易哈希
@@ -131,9 +122,11 @@ func TestDeployContract1(t *testing.T) {
 
 	// ============ 第十二步：等待交易确认 ============
 	fmt.Println("⏳ 等待交易被矿工确认（约 15-30 秒）...")
-	receipt, err := waitForReceipt(client, signedTx.Hash())
+	ctx, cancel := receiptContext()
+	defer cancel()
+	receipt, err := txutil.WaitForReceipt(ctx, client, signedTx.Hash(), txutil.DefaultPollInterval)
 	if err != nil {
-		log.Fatalf("❌ 等待交易确认失败: %v", err)
+		t.Fatalf("❌ 等待交易确认失败: %v", err)
 	}
 
 	// ============ 第十三步：检查部署结果 ============
@@ -145,34 +138,6 @@ func TestDeployContract1(t *testing.T) {
 		fmt.Printf("📦 区块高度: %d\n", receipt.BlockNumber.Uint64())
 		fmt.Printf("🔍 在 Etherscan 查看合约: https://sepolia.etherscan.io/address/%s\n", receipt.ContractAddress.Hex())
 	} else {
-		log.Fatalf("❌ 合约部署失败! Transaction Status: %d", receipt.Status)
+		t.Fatalf("❌ 合约部署失败! Transaction Status: %d", receipt.Status)
 	}
 }
-
-// ==================== 辅助函数：等待交易确认 ====================
-// 功能：轮询查询交易收据，直到交易被打包进区块
-// 参数：client - 以太坊客户端，txHash - 交易哈希
-// 返回：交易收据或错误
-func waitForReceipt(client *ethclient.Client, txHash common.Hash) (*types.Receipt, error) {
-	// 最多等待 60 次（每次 1 秒，总共约 1 分钟）
-	for i := 0; i < 60; i++ {
-		// 尝试获取交易收据
-		receipt, err := client.TransactionReceipt(context.Background(), txHash)
-		if err == nil {
-			// 收据存在，说明交易已被确认
-			return receipt, nil
-		}
-
-		// 如果错误不是 "not found"，则返回错误
-		if err.Error() != "not found" {
-			return nil, fmt.Errorf("查询交易收据失败: %v", err)
-		}
-
-		// 打印等待进度
-		fmt.Printf(".")
-		time.Sleep(1 * time.Second)
-	}
-
-	// 超时未确认
-	return nil, fmt.Errorf("交易确认超时（已等待 60 秒）")
-}
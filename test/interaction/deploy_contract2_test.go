@@ -3,43 +3,33 @@ package interaction
 import (
 	"context"
 	"fmt"
-	"log"
 	"math/big"
 	"testing"
-	"time"
 
-	"github.com/IJing-WishSnow/IWS-dapp/test/interaction/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/txutil"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 func TestDeployContract2(t *testing.T) {
-	client, err := ethclient.Dial("https://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
-	if err != nil {
-		log.Fatalf("❌ 连接节点失败: %v", err)
-	}
-	fmt.Println("✅ 成功连接到 Sepolia 测试网")
-
-	privateKey, err := crypto.HexToECDSA("ab99f80b034909680a1f840bd37a5f45bda536a2cc484c09dbea504914bcbbd9")
-	if err != nil {
-		log.Fatalf("❌ 加载私钥失败: %v", err)
-	}
+	env := newTestEnv(t, "https://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
+	client, privateKey := env.Client, env.Key
 
 	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 	fmt.Printf("📍 部署地址: %s\n", fromAddress.Hex())
 
 	chainID, err := client.ChainID(context.Background())
 	if err != nil {
-		log.Fatalf("❌ 获取 Chain ID 失败: %v", err)
+		t.Fatalf("❌ 获取 Chain ID 失败: %v", err)
 	}
 	fmt.Printf("🔗 Chain ID: %s\n", chainID.String())
 
 	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
 	if err != nil {
-		log.Fatalf("❌ 创建交易签名器失败: %v", err)
+		t.Fatalf("❌ 创建交易签名器失败: %v", err)
 	}
 
 	auth.Value = big.NewInt(0)
@@ -53,7 +43,7 @@ func TestDeployContract2(t *testing.T) {
 
 	address, tx, instance, err := store.DeployStore(auth, client, versionParam)
 	if err != nil {
-		log.Fatalf("❌ 部署合约失败: %v", err)
+		t.Fatalf("❌ 部署合约失败: %v", err)
 	}
 
 	txHash := tx.Hash().Hex()
@@ -63,9 +53,11 @@ func TestDeployContract2(t *testing.T) {
 	fmt.Printf("🔍 在 Etherscan 查看: https://sepolia.etherscan.io/tx/%s\n\n", txHash)
 
 	fmt.Println("⏳ 等待交易被矿工确认（约 15-30 秒）...")
-	receipt, err := waitForReceipt2(client, tx.Hash())
+	ctx, cancel := receiptContext()
+	defer cancel()
+	receipt, err := txutil.WaitForReceipt(ctx, client, tx.Hash(), txutil.DefaultPollInterval)
 	if err != nil {
-		log.Fatalf("❌ 等待交易确认失败: %v", err)
+		t.Fatalf("❌ 等待交易确认失败: %v", err)
 	}
 
 	if receipt.Status == types.ReceiptStatusSuccessful {
@@ -79,28 +71,10 @@ func TestDeployContract2(t *testing.T) {
 		fmt.Println("\n🧪 测试合约调用...")
 		testContractInteraction(instance, auth, client)
 	} else {
-		log.Fatalf("❌ 合约部署失败! Transaction Status: %d", receipt.Status)
+		t.Fatalf("❌ 合约部署失败! Transaction Status: %d", receipt.Status)
 	}
 }
 
-func waitForReceipt2(client *ethclient.Client, txHash common.Hash) (*types.Receipt, error) {
-	for i := 0; i < 60; i++ {
-		receipt, err := client.TransactionReceipt(context.Background(), txHash)
-		if err == nil {
-			return receipt, nil
-		}
-
-		if err.Error() != "not found" {
-			return nil, fmt.Errorf("查询交易收据失败: %v", err)
-		}
-
-		fmt.Printf(".")
-		time.Sleep(1 * time.Second)
-	}
-
-	return nil, fmt.Errorf("交易确认超时（已等待 60 秒）")
-}
-
 func weiToEth(wei *big.Int) string {
 	fwei := new(big.Float).SetInt(wei)
 	fether := new(big.Float).Quo(fwei, big.NewFloat(1e18))
@@ -133,7 +107,9 @@ func testContractInteraction(instance *store.Store, auth *bind.TransactOpts, cli
 	fmt.Printf("✅ SetItem 交易已发送: %s\n", tx.Hash().Hex())
 
 	fmt.Print("⏳ 等待交易确认")
-	receipt, err := waitForReceipt2(client, tx.Hash())
+	ctx, cancel := receiptContext()
+	defer cancel()
+	receipt, err := txutil.WaitForReceipt(ctx, client, tx.Hash(), txutil.DefaultPollInterval)
 	if err != nil {
 		fmt.Printf("\n⚠️  等待交易确认失败: %v\n", err)
 		return
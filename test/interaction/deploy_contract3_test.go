@@ -0,0 +1,77 @@
+package interaction
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/create2"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// ==================== 测试函数：通过 CREATE2 确定性部署 Store ====================
+// 地址只由工厂地址、salt 和初始化代码决定，与部署账户的 nonce 无关，可以离线预测；
+// 已部署时不会重复发送交易，并核对链上运行时代码
+func TestDeployContract3(t *testing.T) {
+	env := newTestEnv(t, "https://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
+	client, privateKey := env.Client, env.Key
+	ctx := context.Background()
+
+	// ============ 第一步：离线预测地址 ============
+	initCode := storeInitCode(t)
+	predicted := create2.Predict(create2.FactoryAddress, storeSalt, initCode)
+	fmt.Printf("🏭 工厂地址: %s\n", create2.FactoryAddress.Hex())
+	fmt.Printf("🧂 Salt: 0x%x\n", storeSalt)
+	fmt.Printf("📍 预测地址: %s\n", predicted.Hex())
+
+	// ============ 第二步：部署（已存在则跳过）============
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatalf("❌ 获取 Chain ID 失败: %v", err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(privateKey, chainID)
+	if err != nil {
+		t.Fatalf("❌ 创建交易签名器失败: %v", err)
+	}
+	fmt.Printf("📍 部署地址: %s\n", crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+
+	deployer := create2.New(client)
+	if !env.Live {
+		deployer.PollInterval = 10 * time.Millisecond
+	}
+	dep, err := deployer.Deploy(ctx, auth, storeSalt, initCode)
+	if err != nil {
+		t.Fatalf("❌ 部署失败: %v", err)
+	}
+	if dep.Address != predicted {
+		t.Fatalf("❌ 部署地址 %s 与预测地址 %s 不一致", dep.Address.Hex(), predicted.Hex())
+	}
+	if dep.Existing {
+		fmt.Println("♻️  合约已存在，未发送交易")
+	} else {
+		fmt.Printf("🚀 部署交易: %s（区块 %d）\n", dep.Tx.Hash().Hex(), dep.Receipt.BlockNumber.Uint64())
+	}
+	fmt.Printf("🔐 运行时代码哈希: %s\n", dep.CodeHash.Hex())
+
+	// 模拟链在创建测试环境时已部署过一次，这里应直接复用
+	if !env.Live && !dep.Existing {
+		t.Fatal("❌ 重复部署应复用已有合约")
+	}
+
+	// ============ 第三步：确认合约可用 ============
+	instance, err := store.NewStore(dep.Address, client)
+	if err != nil {
+		t.Fatalf("❌ 绑定合约失败: %v", err)
+	}
+	version, err := instance.Version(&bind.CallOpts{})
+	if err != nil {
+		t.Fatalf("❌ 读取 version 失败: %v", err)
+	}
+	fmt.Printf("📌 合约版本: %s\n", version)
+	if version != storeVersion {
+		t.Fatalf("❌ 合约版本 %s，期望 %s", version, storeVersion)
+	}
+}
@@ -0,0 +1,164 @@
+package interaction
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	storebinding "github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/store"
+	tokenbinding "github.com/IJing-WishSnow/IWS-dapp/pkg/contracts/token"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/create2"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/deployments"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/multicall"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/rpcx"
+	"github.com/IJing-WishSnow/IWS-dapp/test/simchain"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// ==================== 测试环境 ====================
+// 默认在 go-ethereum 模拟链上离线运行：预置资金账户，部署 Store（已写入 mykey=myvalue）和参考 ERC20。
+// 设置环境变量 IWS_LIVE=1 时连接 Sepolia 真实节点，使用部署清单（pkg/deployments）中记录的合约。
+// 模拟链上的 Store 通过 CREATE2 工厂部署，地址由 salt 和初始化代码决定。
+
+const (
+	testPrivateKey = "ab99f80b034909680a1f840bd37a5f45bda536a2cc484c09dbea504914bcbbd9"
+	storeVersion   = "v1.0.0"
+)
+
+// storeSalt 是 Store 的 CREATE2 salt，同一版本只部署一次
+var storeSalt = create2.Salt("IWS Store " + storeVersion)
+
+// storeInitCode 返回 Store 的初始化代码（字节码 + 构造函数参数 version）
+func storeInitCode(t *testing.T) []byte {
+	t.Helper()
+	parsed, err := abi.JSON(strings.NewReader(storebinding.StoreMetaData.ABI))
+	if err != nil {
+		t.Fatalf("❌ 解析 Store ABI 失败: %v", err)
+	}
+	initCode, err := create2.InitCode(&parsed, common.FromHex(storebinding.StoreMetaData.Bin), storeVersion)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	return initCode
+}
+
+// liveAddress 从内置部署清单中查找 Sepolia 上的合约地址
+func liveAddress(t *testing.T, name string) common.Address {
+	t.Helper()
+	addr, err := deployments.Default().Address("sepolia", name)
+	if err != nil {
+		t.Fatalf("❌ %v", err)
+	}
+	return addr
+}
+
+type testEnv struct {
+	Client *ethclient.Client
+	Key    *ecdsa.PrivateKey // 测试私钥，对应地址 0x8c8a...8373
+	Store  common.Address
+	Token  common.Address
+	Live   bool
+
+	chain *simchain.Chain // 离线模式下的模拟链
+}
+
+// receiptTimeout 是测试等待交易确认的最长时间
+const receiptTimeout = 60 * time.Second
+
+// receiptContext 返回等待交易确认用的上下文，配合 txutil.WaitForReceipt 使用
+func receiptContext() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), receiptTimeout)
+}
+
+// isLive 判断是否连接真实网络
+func isLive() bool {
+	return os.Getenv("IWS_LIVE") == "1"
+}
+
+// newTestEnv 创建测试环境，liveURL 为 IWS_LIVE=1 时连接的节点地址
+func newTestEnv(t *testing.T, liveURL string) *testEnv {
+	t.Helper()
+	key, err := crypto.HexToECDSA(testPrivateKey)
+	if err != nil {
+		t.Fatalf("❌ 加载私钥失败: %v", err)
+	}
+
+	if isLive() {
+		// 免费节点额度有限：每秒最多 10 个请求，限流/超时按指数退避重试
+		transport := rpcx.NewRetry(rpcx.NewRateLimiter(nil, 10, 0), rpcx.DefaultRetryPolicy)
+		transport.OnRetry = func(attempt int, delay time.Duration, err error) {
+			fmt.Printf("⏳ 第 %d 次重试（等待 %v）: %v\n", attempt, delay.Round(time.Millisecond), err)
+		}
+		client, err := rpcx.Dial(context.Background(), liveURL, transport)
+		if err != nil {
+			t.Fatalf("❌ 连接节点失败: %v", err)
+		}
+		t.Cleanup(client.Close)
+		fmt.Printf("✅ 成功连接到节点: %s\n", liveURL)
+		return &testEnv{
+			Client: client,
+			Key:    key,
+			Store:  liveAddress(t, "Store"),
+			Token:  liveAddress(t, "IWSToken"),
+			Live:   true,
+		}
+	}
+
+	// 与主网/测试网一致，在 Multicall3 和 CREATE2 工厂的标准地址预置合约代码
+	chain := simchain.New(t,
+		simchain.WithAlloc(multicall.Address, types.Account{Code: multicall.RuntimeCode, Balance: big.NewInt(0)}),
+		simchain.WithAlloc(create2.FactoryAddress, types.Account{Code: create2.FactoryCode, Balance: big.NewInt(0)}),
+	)
+	env := &testEnv{Client: chain.Client, Key: key, chain: chain}
+
+	// 交易由后台自动出块确认，测试代码按真实网络的方式轮询收据
+	chain.AutoMine(10 * time.Millisecond)
+
+	// 通过 CREATE2 部署 Store 并写入 TestInteractContract 要读取的数据
+	deployer := create2.New(chain.Client)
+	deployer.PollInterval = 10 * time.Millisecond
+	dep, err := deployer.Deploy(context.Background(), chain.Auth(0), storeSalt, storeInitCode(t))
+	if err != nil {
+		t.Fatalf("❌ 部署 Store 失败: %v", err)
+	}
+	storeAddr := dep.Address
+	store, err := storebinding.NewStore(storeAddr, chain.Client)
+	if err != nil {
+		t.Fatalf("❌ 绑定 Store 失败: %v", err)
+	}
+	var key32, value32 [32]byte
+	copy(key32[:], "mykey")
+	copy(value32[:], "myvalue")
+	tx, err := store.SetItem(chain.Auth(0), key32, value32)
+	if err != nil {
+		t.Fatalf("❌ 写入初始数据失败: %v", err)
+	}
+	chain.WaitMined(tx)
+	env.Store = storeAddr
+
+	supply := new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(1e18))
+	env.Token, _ = chain.DeployToken("IWS Token", "IWS", supply)
+	fmt.Printf("✅ 模拟链已就绪 (Chain ID %s)，Store: %s，Token: %s\n", chain.ChainID, env.Store.Hex(), env.Token.Hex())
+	return env
+}
+
+// sendTokenTransfer 用测试账户向 to 转出 amount 个最小单位的代币（仅离线模式），由后台自动出块确认
+func (e *testEnv) sendTokenTransfer(t *testing.T, to common.Address, amount *big.Int) {
+	token, err := tokenbinding.NewToken(e.Token, e.Client)
+	if err != nil {
+		t.Errorf("❌ 绑定代币合约失败: %v", err)
+		return
+	}
+	if _, err := token.Transfer(e.chain.Auth(0), to, amount); err != nil {
+		t.Errorf("❌ 发送代币转账失败: %v", err)
+	}
+}
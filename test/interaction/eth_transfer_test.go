@@ -4,34 +4,28 @@ import (
 	"context"
 	"crypto/ecdsa"
 	"fmt"
-	"log"
 	"math/big"
 	"testing"
 
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/txutil"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 func TestETHTransfer(t *testing.T) {
-	// 连接到以太坊测试网络节点
-	client, err := ethclient.Dial("https://sepolia.infura.io/v3/0c994b2c7e7d4226bde6c128e2d2f2c1")
-	if err != nil {
-		log.Fatalf("无法连接以太坊节点: %v", err) // 连接失败直接终止程序
-	}
+	// 连接到以太坊节点：默认离线模拟链，IWS_LIVE=1 时连接 Sepolia 测试网络
+	env := newTestEnv(t, "https://sepolia.infura.io/v3/0c994b2c7e7d4226bde6c128e2d2f2c1")
+	client := env.Client
 
-	// 从16进制字符串加载私钥（测试环境使用，生产环境需安全存储）
-	privateKey, err := crypto.HexToECDSA("ab99f80b034909680a1f840bd37a5f45bda536a2cc484c09dbea504914bcbbd9")
-	if err != nil {
-		log.Fatal(err)
-	}
+	// 测试私钥（测试环境使用，生产环境需安全存储）
+	privateKey := env.Key
 
 	// 从私钥推导出公钥
 	publicKey := privateKey.Public()
 	publicKeyECDSA, ok := publicKey.(*ecdsa.PublicKey)
 	if !ok {
-		log.Fatal("无法断言类型: 公钥不是*ecdsa.PublicKey类型")
+		t.Fatal("无法断言类型: 公钥不是*ecdsa.PublicKey类型")
 	}
 
 	// 从公钥生成发送者地址
@@ -40,7 +34,7 @@ func TestETHTransfer(t *testing.T) {
 	// 获取账户的待处理交易序号，防止重放攻击
 	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
 
 	// 设置转账金额：1 ETH = 10^18 wei
@@ -52,7 +46,7 @@ func TestETHTransfer(t *testing.T) {
 	// 获取网络推荐的Gas价格
 	gasPrice, err := client.SuggestGasPrice(context.Background())
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
 
 	// 设置接收者地址
@@ -67,21 +61,42 @@ func TestETHTransfer(t *testing.T) {
 	// 获取当前网络的链ID
 	chainID, err := client.NetworkID(context.Background())
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
 
 	// 使用私钥对交易进行签名
 	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
 
 	// 将签名后的交易广播到网络
 	err = client.SendTransaction(context.Background(), signedTx)
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
 
 	// 输出交易哈希，可用于在区块浏览器中查询交易状态
-	fmt.Printf("交易已发送: %s", signedTx.Hash().Hex())
+	fmt.Printf("交易已发送: %s\n", signedTx.Hash().Hex())
+
+	// 离线模式下确认到账
+	if !env.Live {
+		ctx, cancel := receiptContext()
+		defer cancel()
+		receipt, err := txutil.WaitForReceipt(ctx, client, signedTx.Hash(), txutil.DefaultPollInterval)
+		if err != nil || receipt.Status != types.ReceiptStatusSuccessful {
+			t.Fatalf("❌ 转账未成功确认: %v", err)
+		}
+		before, err := client.BalanceAt(context.Background(), toAddress, new(big.Int).Sub(receipt.BlockNumber, big.NewInt(1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		after, err := client.BalanceAt(context.Background(), toAddress, receipt.BlockNumber)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := new(big.Int).Sub(after, before); got.Cmp(value) != 0 {
+			t.Fatalf("❌ 收款地址余额增加 %s wei，期望 %s wei", got, value)
+		}
+	}
 }
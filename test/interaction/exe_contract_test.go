@@ -3,35 +3,24 @@ package interaction
 import (
 	"context"
 	"fmt"
-	"log"
 	"math/big"
 	"testing"
-	"time"
 
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/txutil"
 	"github.com/ethereum/go-ethereum"
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
-)
-
-const (
-	contractAddr = "0x48Bd8C28155a382d872e4758c11b967303fEDD90"
 )
 
 func TestExeContract(t *testing.T) {
 	// ============ 第一步：连接以太坊节点 ============
-	client, err := ethclient.Dial("https://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
-	if err != nil {
-		log.Fatalf("❌ 连接节点失败: %v", err)
-	}
-	fmt.Println("✅ 成功连接到 Sepolia 测试网")
+	// 默认使用离线模拟链；IWS_LIVE=1 时连接 Sepolia，使用部署清单中记录的 Store
+	env := newTestEnv(t, "https://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
+	client := env.Client
+	contractAddr := env.Store
 
 	// ============ 第二步：加载私钥 ============
-	privateKey, err := crypto.HexToECDSA("ab99f80b034909680a1f840bd37a5f45bda536a2cc484c09dbea504914bcbbd9")
-	if err != nil {
-		log.Fatalf("❌ 加载私钥失败: %v", err)
-	}
+	privateKey := env.Key
 
 	fromAddress := crypto.PubkeyToAddress(privateKey.PublicKey)
 	fmt.Printf("📍 操作地址: %s\n", fromAddress.Hex())
@@ -61,23 +50,23 @@ func TestExeContract(t *testing.T) {
 	// ============ 第四步：构造并发送交易 ============
 	nonce, err := client.PendingNonceAt(context.Background(), fromAddress)
 	if err != nil {
-		log.Fatalf("❌ 获取 nonce 失败: %v", err)
+		t.Fatalf("❌ 获取 nonce 失败: %v", err)
 	}
 
 	gasPrice, err := client.SuggestGasPrice(context.Background())
 	if err != nil {
-		log.Fatalf("❌ 获取 gas price 失败: %v", err)
+		t.Fatalf("❌ 获取 gas price 失败: %v", err)
 	}
 
 	chainID, err := client.ChainID(context.Background())
 	if err != nil {
-		log.Fatalf("❌ 获取 chain ID 失败: %v", err)
+		t.Fatalf("❌ 获取 chain ID 失败: %v", err)
 	}
 
 	// 创建交易
 	tx := types.NewTransaction(
 		nonce,
-		common.HexToAddress(contractAddr),
+		contractAddr,
 		big.NewInt(0),
 		uint64(200000),
 		gasPrice,
@@ -87,13 +76,13 @@ func TestExeContract(t *testing.T) {
 	// 签名交易
 	signedTx, err := types.SignTx(tx, types.NewEIP155Signer(chainID), privateKey)
 	if err != nil {
-		log.Fatalf("❌ 签名交易失败: %v", err)
+		t.Fatalf("❌ 签名交易失败: %v", err)
 	}
 
 	// 发送交易
 	err = client.SendTransaction(context.Background(), signedTx)
 	if err != nil {
-		log.Fatalf("❌ 发送交易失败: %v", err)
+		t.Fatalf("❌ 发送交易失败: %v", err)
 	}
 
 	fmt.Printf("\n✅ 交易已发送: %s\n", signedTx.Hash().Hex())
@@ -101,9 +90,11 @@ func TestExeContract(t *testing.T) {
 
 	// ============ 第五步：等待交易确认 ============
 	fmt.Print("⏳ 等待交易确认")
-	receipt, err := waitForReceipt4(client, signedTx.Hash())
+	ctx, cancel := receiptContext()
+	defer cancel()
+	receipt, err := txutil.WaitForReceipt(ctx, client, signedTx.Hash(), txutil.DefaultPollInterval)
 	if err != nil {
-		log.Fatalf("❌ 等待交易确认失败: %v", err)
+		t.Fatalf("❌ 等待交易确认失败: %v", err)
 	}
 
 	if receipt.Status == types.ReceiptStatusSuccessful {
@@ -111,7 +102,7 @@ func TestExeContract(t *testing.T) {
 		fmt.Printf("⛽ Gas 使用量: %d\n", receipt.GasUsed)
 		fmt.Printf("📦 区块高度: %d\n", receipt.BlockNumber.Uint64())
 	} else {
-		log.Fatalf("❌ 交易执行失败! Status: %d", receipt.Status)
+		t.Fatalf("❌ 交易执行失败! Status: %d", receipt.Status)
 	}
 
 	// ============ 第六步：手动构造查询数据 ============
@@ -127,7 +118,7 @@ func TestExeContract(t *testing.T) {
 	callInput = append(callInput, key[:]...)
 
 	// 构造调用消息
-	to := common.HexToAddress(contractAddr)
+	to := contractAddr
 	callMsg := ethereum.CallMsg{
 		To:   &to,
 		Data: callInput,
@@ -136,7 +127,7 @@ func TestExeContract(t *testing.T) {
 	// ============ 第七步：调用合约查询 ============
 	result, err := client.CallContract(context.Background(), callMsg, nil)
 	if err != nil {
-		log.Fatalf("❌ 调用合约失败: %v", err)
+		t.Fatalf("❌ 调用合约失败: %v", err)
 	}
 
 	// ============ 第八步：手动解析返回值 ============
@@ -148,29 +139,8 @@ func TestExeContract(t *testing.T) {
 		fmt.Printf("✅ 数据验证成功! 存储的值与原始值相同\n")
 		fmt.Printf("📌 存储的值: %s\n", string(unpacked[:32]))
 	} else {
-		fmt.Printf("⚠️  数据不匹配!\n")
-		fmt.Printf("   期望值: %s\n", string(value[:32]))
-		fmt.Printf("   实际值: %s\n", string(unpacked[:32]))
+		t.Fatalf("❌ 数据不匹配! 期望值: %s，实际值: %s", string(value[:32]), string(unpacked[:32]))
 	}
 
 	fmt.Println("\n🎉 操作完成!")
 }
-
-// ==================== 辅助函数：等待交易确认 ====================
-func waitForReceipt4(client *ethclient.Client, txHash common.Hash) (*types.Receipt, error) {
-	for i := 0; i < 60; i++ {
-		receipt, err := client.TransactionReceipt(context.Background(), txHash)
-		if err == nil {
-			return receipt, nil
-		}
-
-		if err.Error() != "not found" {
-			return nil, fmt.Errorf("查询交易收据失败: %v", err)
-		}
-
-		fmt.Printf(".")
-		time.Sleep(1 * time.Second)
-	}
-
-	return nil, fmt.Errorf("交易确认超时（已等待 60 秒）")
-}
@@ -1,68 +1,47 @@
 package interaction
 
 import (
+	"context"
 	"fmt"
-	"log"
 	"math"
 	"math/big"
 	"testing"
 
-	"github.com/IJing-WishSnow/IWS-dapp/test/interaction/contracts/token" // 导入通过abigen生成的ERC20合约Go绑定代码
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/multicall" // Multicall3 聚合只读调用
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind" // 提供合约调用选项，如交易发送者、Gas限制等
-	"github.com/ethereum/go-ethereum/common"            // 提供以太坊地址和哈希类型处理
-	"github.com/ethereum/go-ethereum/ethclient"         // 以太坊客户端，用于连接以太坊节点
+	"github.com/ethereum/go-ethereum/common" // 提供以太坊地址和哈希类型处理
 )
 
 // TestQueryBalance 测试查询ERC20代币余额及相关信息的功能
 // 本测试用例演示了如何与部署在以太坊网络上的ERC20代币合约进行交互
 func TestQueryBalance(t *testing.T) {
-	// 连接到以太坊Sepolia测试网络
+	// 连接到以太坊Sepolia测试网络（默认在离线模拟链上运行，IWS_LIVE=1 时连接 Sepolia）
 	// 使用Alchemy提供的节点服务，替换为你自己的API密钥
-	client, err := ethclient.Dial("https://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
-	if err != nil {
-		log.Fatal("连接以太坊节点失败:", err)
-	}
+	env := newTestEnv(t, "https://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
 
 	// IWS代币合约地址（部署在Sepolia测试网上的自定义代币）
-	tokenAddress := common.HexToAddress("0xE5aFC41736bBE96cCB912Cb2d2e6BB503979b657")
-
-	// 创建代币合约实例，使用abigen生成的NewToken函数
-	// 此实例提供了与ERC20合约交互的所有方法
-	instance, err := token.NewToken(tokenAddress, client)
-	if err != nil {
-		log.Fatal("创建合约实例失败:", err)
-	}
+	tokenAddress := env.Token
 
 	// 要查询余额的钱包地址（需要替换为你实际要查询的地址）
 	// address := common.HexToAddress("0x2281cB9267ABAF264c0A4c0dD2e414b4d68cE634")
 	address := common.HexToAddress("0x8c8aB9B6178877246B224F8D745A1410C4928373")
 
-	// 查询代币余额 - 返回的是最小单位的余额（基于代币的decimals）
-	// 例如：如果decimals=18，返回的是以wei为单位的余额
-	bal, err := instance.BalanceOf(&bind.CallOpts{}, address)
+	// 通过 Multicall3 把 name/symbol/decimals/totalSupply 合并为一次 eth_call，
+	// 链上没有 Multicall3 时自动退化为逐个调用
+	caller := multicall.New(env.Client)
+	info, err := caller.TokenInfo(context.Background(), tokenAddress, nil)
 	if err != nil {
-		log.Fatal("查询余额失败:", err)
+		t.Fatal("查询代币信息失败:", err)
 	}
+	name, symbol, decimals := info.Name, info.Symbol, info.Decimals
 
-	// 查询代币名称 - ERC20标准可选函数
-	name, err := instance.Name(&bind.CallOpts{})
-	if err != nil {
-		log.Fatal("查询代币名称失败:", err)
-	}
-
-	// 查询代币符号 - ERC20标准可选函数
-	symbol, err := instance.Symbol(&bind.CallOpts{})
-	if err != nil {
-		log.Fatal("查询代币符号失败:", err)
-	}
-
-	// 查询代币精度（小数位数）- ERC20标准可选函数
-	// 决定了代币可分割的最小单位，常见值为18（如ETH）
-	decimals, err := instance.Decimals(&bind.CallOpts{})
+	// 查询代币余额 - 返回的是最小单位的余额（基于代币的decimals）
+	// 例如：如果decimals=18，返回的是以wei为单位的余额；批量查询多个地址时同样只需一次 eth_call
+	balances, err := caller.TokenBalances(context.Background(), tokenAddress, []common.Address{address}, nil)
 	if err != nil {
-		log.Fatal("查询代币精度失败:", err)
+		t.Fatal("查询余额失败:", err)
 	}
+	bal := balances[0]
 
 	// 输出代币基本信息和原始余额
 	fmt.Printf("代币名称: %s\n", name)      // 实际输出: "IWS Token"
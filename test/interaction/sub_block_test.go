@@ -3,22 +3,19 @@ package interaction
 import (
 	"context"
 	"fmt"
-	"log"
 	"testing"
+	"time"
 
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 // TestSubBlock 测试通过WebSocket订阅新区块头功能
 // 演示了如何实时监听以太坊网络的新区块生成
 func TestSubBlock(t *testing.T) {
-	// 通过WebSocket连接到以太坊Sepolia测试网络
+	// 默认使用离线模拟链；IWS_LIVE=1 时通过WebSocket连接到以太坊Sepolia测试网络
 	// WebSocket连接支持实时订阅功能，适合监听区块和交易事件
-	client, err := ethclient.Dial("wss://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
-	if err != nil {
-		log.Fatal("连接以太坊节点失败:", err)
-	}
+	env := newTestEnv(t, "wss://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
+	client := env.Client
 
 	// 创建用于接收新区块头的通道
 	headers := make(chan *types.Header)
@@ -27,15 +24,32 @@ func TestSubBlock(t *testing.T) {
 	// 当网络中有新区块产生时，区块头信息会通过这个通道发送
 	sub, err := client.SubscribeNewHead(context.Background(), headers)
 	if err != nil {
-		log.Fatal("订阅新区块头失败:", err)
+		t.Fatal("订阅新区块头失败:", err)
+	}
+
+	defer sub.Unsubscribe()
+
+	// 离线模式：自己出几个区块，全部收到即结束，超时则失败
+	remaining := -1
+	timeout := make(<-chan time.Time)
+	if !env.Live {
+		remaining = 3
+		timeout = time.After(10 * time.Second)
+		go func(n int) {
+			for i := 0; i < n; i++ {
+				env.chain.Commit()
+			}
+		}(remaining)
 	}
 
-	// 无限循环监听新区块事件
-	for {
+	// 监听新区块事件（真实网络上无限循环）
+	for remaining != 0 {
 		select {
 		case err := <-sub.Err():
 			// 处理订阅错误
-			log.Fatal("订阅错误:", err)
+			t.Fatal("订阅错误:", err)
+		case <-timeout:
+			t.Fatalf("❌ 10 秒内还有 %d 个区块未收到", remaining)
 		case header := <-headers:
 			// 接收到新的区块头信息
 
@@ -45,7 +59,7 @@ func TestSubBlock(t *testing.T) {
 			// 通过区块哈希获取完整的区块信息
 			block, err := client.BlockByHash(context.Background(), header.Hash())
 			if err != nil {
-				log.Fatal("获取区块详情失败:", err)
+				t.Fatal("获取区块详情失败:", err)
 			}
 
 			// 打印区块详细信息
@@ -61,6 +75,7 @@ func TestSubBlock(t *testing.T) {
 			}
 
 			fmt.Println("--- 新区块信息结束 ---")
+			remaining--
 		}
 	}
 }
@@ -18,27 +18,23 @@ import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 // ==================== 测试函数：实时监听 ERC20 合约事件 ====================
 func TestSubscribeERC20Events(t *testing.T) {
 	// ============ 第一步：连接以太坊 WebSocket 节点 ============
 	fmt.Println("🔌 正在连接 WebSocket...")
-	client, err := ethclient.Dial("wss://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
-	if err != nil {
-		log.Fatalf("❌ 连接 WebSocket 节点失败: %v", err)
-	}
-	fmt.Println("✅ 成功连接到 Sepolia 测试网 WebSocket")
+	env := newTestEnv(t, "wss://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
+	client := env.Client
 
 	// ============ 第二步：设置 ERC20 合约地址 ============
-	contractAddress := common.HexToAddress("0xE5aFC41736bBE96cCB912Cb2d2e6BB503979b657")
+	contractAddress := env.Token
 	fmt.Printf("📍 合约地址: %s\n", contractAddress.Hex())
 
 	// ============ 第三步：解析 ERC20 合约 ABI ============
 	contractABI, err := abi.JSON(strings.NewReader(erc20ABIJSON))
 	if err != nil {
-		log.Fatalf("❌ 解析 ABI 失败: %v", err)
+		t.Fatalf("❌ 解析 ABI 失败: %v", err)
 	}
 	fmt.Println("✅ ABI 解析成功")
 
@@ -63,7 +59,7 @@ func TestSubscribeERC20Events(t *testing.T) {
 
 	sub, err := client.SubscribeFilterLogs(context.Background(), query, logs)
 	if err != nil {
-		log.Fatalf("❌ 订阅事件失败: %v", err)
+		t.Fatalf("❌ 订阅事件失败: %v", err)
 	}
 	defer sub.Unsubscribe()
 
@@ -75,11 +71,27 @@ func TestSubscribeERC20Events(t *testing.T) {
 	// 创建手动停止通道
 	stopChan := make(chan os.Signal, 1)
 	signal.Notify(stopChan, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(stopChan)
+
+	// 离线模式：自己发一笔转账触发事件，收到第一个事件即结束，超时则失败
+	if !env.Live {
+		go env.sendTokenTransfer(t, common.HexToAddress("0x2281cB9267ABAF264c0A4c0dD2e414b4d68cE634"), big.NewInt(1e18))
+		select {
+		case err := <-sub.Err():
+			t.Fatalf("❌ 订阅错误: %v", err)
+		case vLog := <-logs:
+			fmt.Printf("\n🎉 收到新事件! 时间: %s\n", time.Now().Format("15:04:05"))
+			processRealtimeEvent(vLog, contractABI, transferEventHash, approvalEventHash)
+		case <-time.After(10 * time.Second):
+			t.Fatal("❌ 10 秒内未收到事件")
+		}
+		return
+	}
 
 	for {
 		select {
 		case err := <-sub.Err():
-			log.Fatalf("❌ 订阅错误: %v", err)
+			t.Fatalf("❌ 订阅错误: %v", err)
 		case vLog := <-logs:
 			fmt.Printf("\n🎉 收到新事件! 时间: %s\n", time.Now().Format("15:04:05"))
 			processRealtimeEvent(vLog, contractABI, transferEventHash, approvalEventHash)
@@ -93,7 +105,7 @@ func TestSubscribeERC20Events(t *testing.T) {
 	// for {
 	// 	select {
 	// 	case err := <-sub.Err():
-	// 		log.Fatalf("❌ 订阅错误: %v", err)
+	// 		t.Fatalf("❌ 订阅错误: %v", err)
 	// 	case vLog := <-logs:
 	// 		fmt.Printf("\n🎉 收到新事件! 时间: %s\n", time.Now().Format("15:04:05"))
 	// 		processRealtimeEvent(vLog, contractABI, transferEventHash, approvalEventHash)
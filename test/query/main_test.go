@@ -0,0 +1,75 @@
+package query
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/rpcx"
+)
+
+// 录制文件：保存固定区块 23866957 相关的 JSON-RPC 请求/响应
+const fixturePath = "testdata/mainnet.json"
+
+var (
+	record     = flag.Bool("record", false, "连接主网节点，把 RPC 请求/响应录制到 "+fixturePath)
+	fixtureErr error // 回放模式下录制文件不可用的原因
+)
+
+// go test ./test/query          离线回放录制文件（缺失时失败）
+// go test ./test/query -record  连接主网重新录制
+// IWS_SKIP_REPLAY=1             录制文件缺失时跳过而不是失败
+func TestMain(m *testing.M) {
+	flag.Parse()
+	ctx := context.Background()
+
+	var recorder *rpcx.Recorder
+	var err error
+	switch {
+	case *record:
+		recorder = rpcx.NewRecorder(nil)
+		client, err = rpcx.Dial(ctx, mainnetURL, recorder)
+	default:
+		replayer, openErr := rpcx.OpenReplayer(fixturePath)
+		if openErr != nil {
+			fixtureErr = openErr
+			replayer = rpcx.NewReplayer(fixturePath, &rpcx.Fixture{})
+		}
+		client, err = rpcx.Dial(ctx, mainnetURL, replayer)
+	}
+	if err != nil {
+		log.Fatalf("无法连接以太坊节点: %v", err)
+	}
+
+	code := m.Run()
+	if recorder != nil {
+		if err := recorder.Save(fixturePath); err != nil {
+			log.Fatalf("保存录制文件失败: %v", err)
+		}
+		fmt.Printf("💾 已录制 %d 个请求到 %s\n", len(recorder.Fixture().Exchanges), fixturePath)
+	}
+	os.Exit(code)
+}
+
+// requireLive 跳过依赖最新链上状态、无法录制回放的测试（设置 IWS_LIVE=1 时连接真实节点运行）
+func requireLive(t *testing.T) {
+	t.Helper()
+	if os.Getenv("IWS_LIVE") != "1" {
+		t.Skip("⚠️  查询的是最新链上状态，无法回放，设置 IWS_LIVE=1 连接真实节点运行")
+	}
+}
+
+// requireFixture 要求录制文件可用：缺失时测试失败，设置 IWS_SKIP_REPLAY=1 才显式跳过
+func requireFixture(t *testing.T) {
+	t.Helper()
+	if fixtureErr == nil {
+		return
+	}
+	if os.Getenv("IWS_SKIP_REPLAY") == "1" {
+		t.Skipf("⚠️  已设置 IWS_SKIP_REPLAY=1，跳过回放: %v", fixtureErr)
+	}
+	t.Fatalf("❌ 录制文件不可用（运行 go test ./test/query -record 录制，或设置 IWS_SKIP_REPLAY=1 跳过）: %v", fixtureErr)
+}
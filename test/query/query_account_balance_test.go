@@ -3,7 +3,6 @@ package query
 import (
 	"context"
 	"fmt"
-	"log"
 	"math"
 	"math/big"
 	"testing"
@@ -13,39 +12,39 @@ import (
 )
 
 func TestQueryAccountBalance(t *testing.T) {
-	// 连接到以太坊节点
-	// client, err := ethclient.Dial("https://eth-mainnet.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
-	client, err := ethclient.Dial("https://eth-sepolia.g.alchemy.com/v2/4Mb8kv8N7tWzzTDYHAkE3")
-	if err != nil {
-		log.Fatal(err)
-	}
+	requireFixture(t)
 
 	// 将十六进制地址转换为Address类型
-	// account := common.HexToAddress("0x2281cB9267ABAF264c0A4c0dD2e414b4d68cE634")
-	account := common.HexToAddress("0x8c8ab9b6178877246b224f8d745a1410c4928373")
+	account := common.HexToAddress("0x2281cB9267ABAF264c0A4c0dD2e414b4d68cE634")
 
-	// 查询当前最新的余额
-	balance, err := client.BalanceAt(context.Background(), account, nil)
-	if err != nil {
-		log.Fatal(err)
-	}
-	fmt.Println(balance)
-
-	// 查询特定区块高度的余额
-	blockNumber := big.NewInt(5532993)
+	// 查询固定区块高度的余额（可录制回放）
 	balanceAt, err := client.BalanceAt(context.Background(), account, blockNumber)
 	if err != nil {
-		log.Fatal(err)
+		t.Fatal(err)
 	}
 	fmt.Println(balanceAt)
 
-	// 将最新余额从wei转换为ETH
+	// 将余额从wei转换为ETH
 	fbalance := new(big.Float)
-	fbalance.SetString(balance.String())
+	fbalance.SetString(balanceAt.String())
 	ethValue := new(big.Float).Quo(fbalance, big.NewFloat(math.Pow10(18)))
 	fmt.Println(ethValue)
 
-	// 查询待处理余额（包含待处理交易）
-	pendingBalance, err := client.PendingBalanceAt(context.Background(), account)
+	// 最新余额和待处理余额（包含待处理交易）随链上状态变化，无法回放，直接连接主网
+	requireLive(t)
+	live, err := ethclient.Dial(mainnetURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer live.Close()
+	balance, err := live.BalanceAt(context.Background(), account, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fmt.Println(balance)
+	pendingBalance, err := live.PendingBalanceAt(context.Background(), account)
+	if err != nil {
+		t.Fatal(err)
+	}
 	fmt.Println(pendingBalance)
 }
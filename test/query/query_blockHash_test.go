@@ -3,18 +3,22 @@ package query
 import (
 	"context"
 	"fmt"
-	"log"
 	"math/big"
 	"testing"
 )
 
 func TestGetBlock(t *testing.T) {
+	requireFixture(t)
+
 	// 通过区块高度获取哈希
 	blockNumber := big.NewInt(23866957)
 	block, err := client.BlockByNumber(context.Background(), blockNumber)
 	if err != nil {
-		log.Fatal(err)
+		t.Fatalf("❌ 获取区块失败: %v", err)
 	}
 
 	fmt.Printf("区块哈希: %s\n", block.Hash().Hex())
+	if block.Hash() != blockHash {
+		t.Fatalf("❌ 区块哈希 %s，期望 %s", block.Hash().Hex(), blockHash.Hex())
+	}
 }
@@ -3,7 +3,6 @@ package query
 import (
 	"context"
 	"fmt"
-	"log"
 	"testing"
 	"time"
 
@@ -11,6 +10,8 @@ import (
 )
 
 func TestQueryBlockReceipts(t *testing.T) {
+	requireFixture(t)
+
 	// 添加超时控制
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
@@ -18,19 +19,26 @@ func TestQueryBlockReceipts(t *testing.T) {
 	// 通过区块哈希获取整个区块的所有交易收据
 	receiptByHash, err := client.BlockReceipts(ctx, rpc.BlockNumberOrHashWithHash(blockHash, false))
 	if err != nil {
-		log.Fatal("通过哈希获取区块收据失败:", err)
+		t.Fatalf("❌ 通过哈希获取区块收据失败: %v", err)
 	}
 
 	// 通过区块号获取整个区块的所有交易收据（修复类型转换）
 	receiptsByNum, err := client.BlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(blockNumber.Int64())))
 	if err != nil {
-		log.Fatal("通过区块号获取区块收据失败:", err)
+		t.Fatalf("❌ 通过区块号获取区块收据失败: %v", err)
 	}
 
-	// 验证两种方式获取的收据是否相同
-	if len(receiptByHash) > 0 && len(receiptsByNum) > 0 {
-		fmt.Println("两种方式获取的收据是否相同:", receiptByHash[0].TxHash == receiptsByNum[0].TxHash)
+	// 验证两种方式获取的收据相同，且每笔交易都有收据
+	if len(receiptByHash) != blockTxCount || len(receiptsByNum) != blockTxCount {
+		t.Fatalf("❌ 收据数量 %d / %d，期望 %d", len(receiptByHash), len(receiptsByNum), blockTxCount)
+	}
+	for i := range receiptByHash {
+		r := receiptByHash[i]
+		if r.TxHash != receiptsByNum[i].TxHash || r.BlockHash != blockHash || r.TransactionIndex != uint(i) {
+			t.Fatalf("❌ 第 %d 个收据不符: %s（区块 %s，索引 %d）", i, r.TxHash.Hex(), r.BlockHash.Hex(), r.TransactionIndex)
+		}
 	}
+	fmt.Println("两种方式获取的收据是否相同:", true)
 
 	// 遍历区块中的交易收据（限制数量避免超时）
 	for i, receipt := range receiptByHash {
@@ -48,7 +56,10 @@ func TestQueryBlockReceipts(t *testing.T) {
 	// 通过交易哈希单独查询交易收据
 	receipt, err := client.TransactionReceipt(ctx, txHash)
 	if err != nil {
-		log.Fatal("通过交易哈希获取收据失败:", err)
+		t.Fatalf("❌ 通过交易哈希获取收据失败: %v", err)
+	}
+	if receipt.TxHash != txHash {
+		t.Fatalf("❌ 交易 %s 的收据哈希为 %s", txHash.Hex(), receipt.TxHash.Hex())
 	}
 
 	// 显示单个交易的收据详情
@@ -3,40 +3,52 @@ package query
 import (
 	"context"
 	"fmt"
-	"log"
 	"testing"
 )
 
 func TestQueryBlock(t *testing.T) {
+	requireFixture(t)
 
 	header, err := client.HeaderByNumber(context.Background(), blockNumber) // 根据区块号获取区块头信息
-	fmt.Println(header.Number.Uint64())                                     // 打印区块号：23866957
-	fmt.Println(header.Time)                                                // 打印区块时间戳
-	fmt.Println(header.Difficulty.Uint64())                                 // 打印挖矿难度值
-	fmt.Println(header.Hash().Hex())                                        // 打印区块哈希值
-
 	if err != nil {
-		log.Fatal(err)
+		t.Fatalf("❌ 获取区块头失败: %v", err)
 	}
+	fmt.Println(header.Number.Uint64())     // 打印区块号：23866957
+	fmt.Println(header.Time)                // 打印区块时间戳
+	fmt.Println(header.Difficulty.Uint64()) // 打印挖矿难度值
+	fmt.Println(header.Hash().Hex())        // 打印区块哈希值
 
 	block, err := client.BlockByNumber(context.Background(), blockNumber) // 根据区块号获取完整区块信息
 	if err != nil {
-		log.Fatal(err)
+		t.Fatalf("❌ 获取区块失败: %v", err)
 	}
 
 	fmt.Println(block.Number().Uint64())     // 打印区块号：23866957
 	fmt.Println(block.Time())                // 打印区块时间戳：1763965919
-	fmt.Println(block.Difficulty().Uint64()) // 打印挖矿难度值：0
-	fmt.Println(block.Hash().Hex())          // 打印区块哈希值：0xbd829c3842e414aa7cab0040b5ff50a3c7a6ffacbbb653d1288fbbb9d8ba92d7
+	fmt.Println(block.Difficulty().Uint64()) // 打印挖矿难度值：0（合并后为 0）
+	fmt.Println(block.Hash().Hex())          // 打印区块哈希值
 	fmt.Println(len(block.Transactions()))   // 打印交易数量：123
 
-	count, err := client.TransactionCount(context.Background(), block.Hash()) // 通过区块哈希查询该区块中的交易总数
+	// 核对已知数据；区块哈希由区块头重新计算，能发现录制或解码错误
+	if block.Number().Cmp(blockNumber) != 0 || header.Hash() != blockHash || block.Hash() != blockHash {
+		t.Fatalf("❌ 区块 %v 的哈希 %s，期望 %s", block.Number(), block.Hash().Hex(), blockHash.Hex())
+	}
+	if block.Time() != blockTime || block.Difficulty().Sign() != 0 {
+		t.Fatalf("❌ 区块时间戳 %d、难度 %v，期望 %d、0", block.Time(), block.Difficulty(), blockTime)
+	}
+	if len(block.Transactions()) != blockTxCount {
+		t.Fatalf("❌ 区块包含 %d 笔交易，期望 %d", len(block.Transactions()), blockTxCount)
+	}
 
+	count, err := client.TransactionCount(context.Background(), block.Hash()) // 通过区块哈希查询该区块中的交易总数
 	if err != nil {
-		log.Fatal(err)
+		t.Fatalf("❌ 查询交易数量失败: %v", err)
 	}
 
 	fmt.Println(count) // 打印交易总数：123
+	if count != blockTxCount {
+		t.Fatalf("❌ 交易总数 %d，期望 %d", count, blockTxCount)
+	}
 }
 
 // ```go
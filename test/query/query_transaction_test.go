@@ -3,14 +3,19 @@ package query
 import (
 	"context"
 	"fmt"
-	"log"
+	"os"
 	"testing"
 	"time"
 
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/decoder"
+	"github.com/IJing-WishSnow/IWS-dapp/pkg/rpcx"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
 func TestQueryTransaction(t *testing.T) {
+	requireFixture(t)
+
 	// 创建带超时的上下文，避免请求卡死
 	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
 	defer cancel()
@@ -18,17 +23,36 @@ func TestQueryTransaction(t *testing.T) {
 	// 获取区块链网络ID - 用于交易签名验证
 	chainID, err := client.ChainID(ctx)
 	if err != nil {
-		log.Fatal("获取链ID失败:", err)
+		t.Fatalf("❌ 获取链ID失败: %v", err)
 	}
 	fmt.Printf("当前网络链ID: %s\n", chainID.String())
 
 	// 使用一个已知的区块号进行测试
 	block, err := client.BlockByNumber(ctx, blockNumber)
 	if err != nil {
-		log.Fatal("获取区块信息失败:", err)
+		t.Fatalf("❌ 获取区块信息失败: %v", err)
+	}
+	if block.Hash() != blockHash || len(block.Transactions()) != blockTxCount {
+		t.Fatalf("❌ 区块 %s 包含 %d 笔交易，期望 %s、%d 笔", block.Hash().Hex(), len(block.Transactions()), blockHash.Hex(), blockTxCount)
 	}
 	fmt.Printf("成功获取区块 #%d，包含 %d 笔交易\n", block.Number().Uint64(), len(block.Transactions()))
 
+	// 用内置 ABI 解码交易 calldata（ERC20、Store、Multicall 等）
+	dec := decoder.New(nil)
+
+	// 一次批量请求取回前 3 笔交易的收据，代替逐笔查询
+	var hashes []common.Hash
+	for _, tx := range block.Transactions() {
+		if len(hashes) == 3 {
+			break
+		}
+		hashes = append(hashes, tx.Hash())
+	}
+	receipts, batchErr := rpcx.NewBatcher(client.Client(), 0).Receipts(ctx, hashes)
+	if receipts == nil {
+		t.Fatalf("❌ 批量获取交易收据失败: %v", batchErr)
+	}
+
 	// 遍历区块中的交易并分析交易数据
 	txCount := 0
 	for _, tx := range block.Transactions() {
@@ -44,7 +68,10 @@ func TestQueryTransaction(t *testing.T) {
 		fmt.Println(tx.Gas())               // Gas限制 - 交易允许消耗的最大Gas量，防止无限循环和过度消耗资源
 		fmt.Println(tx.GasPrice().Uint64()) // Gas价格（wei）- 每单位Gas的价格，决定交易处理优先级
 		fmt.Println(tx.Nonce())             // 发送者交易计数器 - 防止重放攻击，确保交易顺序执行
-		fmt.Println(tx.Data())              // 交易附加数据 - 智能合约调用参数或备注信息，普通转账为空
+
+		// 交易附加数据 - 按已知 ABI 解码为调用树（含 multicall 内部调用），普通转账为空
+		dec.Decode(tx.To(), tx.Data()).Render(os.Stdout)
+
 		if tx.To() != nil {
 			fmt.Println(tx.To().Hex()) // 接收方地址 - 资金或合约调用的目标地址，nil表示合约创建交易
 		} else {
@@ -59,12 +86,15 @@ func TestQueryTransaction(t *testing.T) {
 			continue // 跳过此交易继续处理下一个
 		}
 
-		// 获取交易收据 - 包含交易在区块链上的执行结果和产生的事件日志
-		receipt, err := client.TransactionReceipt(ctx, tx.Hash())
-		if err != nil {
-			fmt.Printf("获取交易收据失败: %v\n", err)
+		// 交易收据 - 包含交易在区块链上的执行结果和产生的事件日志
+		receipt := receipts[txCount-1]
+		if receipt == nil {
+			fmt.Printf("获取交易收据失败: %v\n", batchErr)
 			continue
 		}
+		if receipt.TxHash != tx.Hash() || receipt.BlockHash != blockHash {
+			t.Fatalf("❌ 交易 %s 的收据不符: %s", tx.Hash().Hex(), receipt.TxHash.Hex())
+		}
 
 		// 交易状态: 1=成功, 0=失败
 		fmt.Printf("交易状态: %d ", receipt.Status)
@@ -85,17 +115,22 @@ func TestQueryTransaction(t *testing.T) {
 	fmt.Println("\n=== 区块交易查询 ===")
 
 	// 验证区块是否存在
-	block, err = client.BlockByHash(ctx, blockHash)
+	byHash, err := client.BlockByHash(ctx, blockHash)
 	if err != nil {
-		fmt.Printf("区块不存在或查询失败: %v\n", err)
-	} else {
-		fmt.Printf("区块 #%d 验证成功\n", block.Number().Uint64())
+		t.Fatalf("❌ 区块不存在或查询失败: %v", err)
 	}
+	if byHash.Number().Cmp(blockNumber) != 0 {
+		t.Fatalf("❌ 区块 %s 的高度为 %v，期望 %v", blockHash.Hex(), byHash.Number(), blockNumber)
+	}
+	fmt.Printf("区块 #%d 验证成功\n", byHash.Number().Uint64())
 
 	// 获取指定区块中的交易总数
 	count, err := client.TransactionCount(ctx, blockHash)
 	if err != nil {
-		log.Fatal("获取交易数量失败:", err)
+		t.Fatalf("❌ 获取交易数量失败: %v", err)
+	}
+	if count != blockTxCount {
+		t.Fatalf("❌ 区块中包含 %d 笔交易，期望 %d", count, blockTxCount)
 	}
 	fmt.Printf("区块中包含 %d 笔交易\n", count)
 
@@ -103,7 +138,10 @@ func TestQueryTransaction(t *testing.T) {
 	for idx := uint(0); idx < count && idx < 3; idx++ {
 		tx, err := client.TransactionInBlock(ctx, blockHash, idx)
 		if err != nil {
-			log.Fatal("获取区块中交易失败:", err)
+			t.Fatalf("❌ 获取区块中交易失败: %v", err)
+		}
+		if tx.Hash() != block.Transactions()[idx].Hash() {
+			t.Fatalf("❌ 第 %d 笔交易 %s 与区块中的 %s 不一致", idx, tx.Hash().Hex(), block.Transactions()[idx].Hash().Hex())
 		}
 		fmt.Printf("交易%d: %s\n", idx, tx.Hash().Hex())
 	}
@@ -113,7 +151,10 @@ func TestQueryTransaction(t *testing.T) {
 	// 通过交易哈希直接查询交易详情
 	tx, isPending, err := client.TransactionByHash(ctx, txHash)
 	if err != nil {
-		log.Fatal("通过哈希查询交易失败:", err)
+		t.Fatalf("❌ 通过哈希查询交易失败: %v", err)
+	}
+	if tx.Hash() != txHash || isPending {
+		t.Fatalf("❌ 交易 %s 应已确认，实际为 %s（pending=%v）", txHash.Hex(), tx.Hash().Hex(), isPending)
 	}
 
 	// 交易状态说明
@@ -0,0 +1,101 @@
+package simchain
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// HTTPURL 启动一个 HTTP JSON-RPC 前端并返回其地址，请求被转发到模拟链，
+// 供只能通过 URL 连接节点的代码（如 iws 命令、ethclient.Dial）使用
+func (c *Chain) HTTPURL() string {
+	if c.http == nil {
+		c.http = httptest.NewServer(http.HandlerFunc(c.serveRPC))
+	}
+	return c.http.URL
+}
+
+type jsonrpcRequest struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+}
+
+type jsonrpcError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+type jsonrpcResponse struct {
+	Version string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *jsonrpcError   `json:"error,omitempty"`
+}
+
+func (c *Chain) serveRPC(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+
+	// 批量请求
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		var reqs []jsonrpcRequest
+		if err := json.Unmarshal(trimmed, &reqs); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resps := make([]jsonrpcResponse, len(reqs))
+		for i, req := range reqs {
+			resps[i] = c.forward(r, req)
+		}
+		json.NewEncoder(w).Encode(resps)
+		return
+	}
+
+	var req jsonrpcRequest
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	json.NewEncoder(w).Encode(c.forward(r, req))
+}
+
+// forward 把单个 JSON-RPC 请求转发到模拟链的进程内 RPC 连接
+func (c *Chain) forward(r *http.Request, req jsonrpcRequest) jsonrpcResponse {
+	resp := jsonrpcResponse{Version: "2.0", ID: req.ID}
+	args := make([]interface{}, len(req.Params))
+	for i, p := range req.Params {
+		args[i] = p
+	}
+
+	var result json.RawMessage
+	err := c.rpc.CallContext(r.Context(), &result, req.Method, args...)
+	if err == nil {
+		if result == nil {
+			result = json.RawMessage("null")
+		}
+		resp.Result = result
+		return resp
+	}
+
+	resp.Error = &jsonrpcError{Code: -32000, Message: err.Error()}
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		resp.Error.Code = rpcErr.ErrorCode()
+	}
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		resp.Error.Data = dataErr.ErrorData()
+	}
+	return resp
+}
@@ -0,0 +1,72 @@
+package simchain
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// 测试预置账户、合约部署、自动出块和 HTTP 前端
+func TestChain(t *testing.T) {
+	ctx := context.Background()
+	c := New(t)
+
+	owner := c.Accounts[0].Address
+	if owner != common.HexToAddress("0x8c8aB9B6178877246B224F8D745A1410C4928373") {
+		t.Fatalf("❌ 第 0 个预置账户地址不符: %s", owner.Hex())
+	}
+	if bal, _ := c.Client.BalanceAt(ctx, owner, nil); bal.Cmp(DefaultBalance) != 0 {
+		t.Fatalf("❌ 预置余额不符: %s", bal)
+	}
+
+	storeAddr, store := c.DeployStore("v1.0.0")
+	if v, err := store.Version(&bind.CallOpts{}); err != nil || v != "v1.0.0" {
+		t.Fatalf("❌ Store 版本不符: %q %v", v, err)
+	}
+
+	supply := new(big.Int).Mul(big.NewInt(1_000_000), big.NewInt(1e18))
+	tokenAddr, token := c.DeployToken("IWS Token", "IWS", supply)
+	if bal, err := token.BalanceOf(&bind.CallOpts{}, owner); err != nil || bal.Cmp(supply) != 0 {
+		t.Fatalf("❌ 部署者代币余额不符: %s %v", bal, err)
+	}
+	t.Logf("✅ Store: %s, Token: %s", storeAddr.Hex(), tokenAddr.Hex())
+
+	// 自动出块：发送交易后不手动 Commit，轮询收据应能拿到结果
+	c.AutoMine(10 * time.Millisecond)
+	tx, err := token.Transfer(c.Auth(0), c.Accounts[1].Address, big.NewInt(5))
+	if err != nil {
+		t.Fatalf("❌ 转账失败: %v", err)
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if receipt, err := bind.WaitMined(waitCtx, c.Client, tx); err != nil || receipt.Status != 1 {
+		t.Fatalf("❌ 自动出块未确认交易: %v", err)
+	}
+
+	// HTTP 前端：通过 URL 连接，单个请求和批量请求都应转发到模拟链
+	client, err := ethclient.Dial(c.HTTPURL())
+	if err != nil {
+		t.Fatalf("❌ 连接 HTTP 前端失败: %v", err)
+	}
+	defer client.Close()
+	if id, err := client.ChainID(ctx); err != nil || id.Cmp(c.ChainID) != 0 {
+		t.Fatalf("❌ HTTP 前端 Chain ID 不符: %v %v", id, err)
+	}
+	var head, code string
+	batch := []rpc.BatchElem{
+		{Method: "eth_blockNumber", Result: &head},
+		{Method: "eth_getCode", Args: []interface{}{tokenAddr, "latest"}, Result: &code},
+	}
+	if err := client.Client().BatchCallContext(ctx, batch); err != nil || batch[0].Error != nil || len(code) < 10 {
+		t.Fatalf("❌ 批量请求失败: %v %v code=%d", err, batch[0].Error, len(code))
+	}
+	if _, err := client.TransactionReceipt(ctx, common.Hash{}); err == nil {
+		t.Fatal("❌ 不存在的交易应返回错误")
+	}
+}